@@ -5,13 +5,20 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"fittrackplus/internal/auth"
 	"fittrackplus/internal/common/config"
 	"fittrackplus/internal/common/database"
 	"fittrackplus/internal/dashboard"
+	"fittrackplus/internal/exercise"
+	"fittrackplus/internal/goal"
 	"fittrackplus/internal/plan"
 	"fittrackplus/internal/profile"
+	"fittrackplus/internal/metrics"
+	"fittrackplus/internal/nutrition"
+	"fittrackplus/internal/progress"
+	"fittrackplus/internal/workout"
 	_ "fittrackplus/docs" // This is required for swagger
 
 	"github.com/gin-gonic/gin"
@@ -45,14 +52,44 @@ func main() {
 	// Load configuration from environment variables
 	cfg := config.LoadConfig()
 
+	// Refuse to start with unsafe settings (e.g. the default JWT secret in release mode)
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	// Connect to the database
 	err := database.Connect(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Set Gin to release mode for production
-	gin.SetMode(gin.ReleaseMode)
+	// Set Gin mode (release by default for production)
+	gin.SetMode(cfg.GinMode)
+
+	// Make sure a signing key exists and rotate it on schedule
+	if cfg.JWTAlgorithm != "HS256" {
+		keyManager := auth.GetKeyManager(cfg)
+		if err := keyManager.RotateIfDue(); err != nil {
+			log.Fatalf("Failed to initialize JWT signing keys: %v", err)
+		}
+		keyManager.StartRotationScheduler(time.Hour)
+	}
+
+	// Seed the exercise library on first start
+	if err := exercise.NewExerciseService(cfg).SeedDefaultExercises(); err != nil {
+		log.Printf("Failed to seed exercise library: %v", err)
+	}
+
+	// Seed the food database on first start
+	if err := nutrition.NewNutritionService(cfg).SeedDefaultFoods(); err != nil {
+		log.Printf("Failed to seed food database: %v", err)
+	}
+
+	// Mark goals achieved or missed even when their members are away
+	goal.NewGoalService(cfg).StartEvaluationScheduler(time.Hour)
+
+	// Complete plans whose duration has passed
+	plan.NewPlanService(cfg).StartCompletionScheduler(time.Hour)
 
 	// Create a new Gin router
 	// Gin is a popular HTTP web framework for Go
@@ -62,7 +99,7 @@ func main() {
 	// This allows our frontend to communicate with the backend
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		
 		if c.Request.Method == "OPTIONS" {
@@ -102,6 +139,12 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 	profileHandler := profile.NewProfileHandler(cfg)
 	dashboardHandler := dashboard.NewDashboardHandler(cfg)
 	planHandler := plan.NewPlanHandler(cfg)
+	exerciseHandler := exercise.NewExerciseHandler(cfg)
+	workoutHandler := workout.NewWorkoutHandler(cfg)
+	progressHandler := progress.NewProgressHandler(cfg)
+	metricsHandler := metrics.NewMetricsHandler(cfg)
+	goalHandler := goal.NewGoalHandler(cfg)
+	nutritionHandler := nutrition.NewNutritionHandler(cfg)
 
 	// Debug: Check if handlers are created successfully
 	fmt.Println("🔧 Handlers initialized:")
@@ -109,6 +152,12 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 	fmt.Println("   - ProfileHandler:", profileHandler != nil)
 	fmt.Println("   - DashboardHandler:", dashboardHandler != nil)
 	fmt.Println("   - PlanHandler:", planHandler != nil)
+	fmt.Println("   - ExerciseHandler:", exerciseHandler != nil)
+	fmt.Println("   - WorkoutHandler:", workoutHandler != nil)
+	fmt.Println("   - ProgressHandler:", progressHandler != nil)
+	fmt.Println("   - MetricsHandler:", metricsHandler != nil)
+	fmt.Println("   - GoalHandler:", goalHandler != nil)
+	fmt.Println("   - NutritionHandler:", nutritionHandler != nil)
 
 	// API version 1 group
 	api := router.Group("/api/v1")
@@ -121,6 +170,15 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 		{
 			authGroup.POST("/register", authHandler.Register)
 			authGroup.POST("/login", authHandler.Login)
+
+			// Social login (OpenID Connect)
+			authGroup.GET("/oidc/:provider/login", authHandler.OIDCLogin)
+			authGroup.GET("/oidc/:provider/callback", authHandler.OIDCCallback)
+
+			authGroup.POST("/logout", auth.AuthMiddleware(cfg), authHandler.Logout)
+
+			// Signing key management (Admin only)
+			authGroup.POST("/keys/rotate", auth.AuthMiddleware(cfg), auth.RoleMiddleware("admin"), authHandler.RotateSigningKey)
 		}
 		
 		// User routes (protected - authentication required)
@@ -129,6 +187,17 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 		{
 			// Basic user profile (from auth)
 			users.PUT("/profile", authHandler.UpdateProfile)
+
+			// Linked social login identities
+			users.GET("/identities", authHandler.GetIdentities)
+			users.POST("/identities/:provider/link", authHandler.LinkIdentity)
+			users.DELETE("/identities/:id", authHandler.UnlinkIdentity)
+
+			// Active sessions and devices
+			users.GET("/sessions", authHandler.GetSessions)
+			users.DELETE("/sessions", authHandler.RevokeOtherSessions)
+			users.DELETE("/sessions/:id", authHandler.RevokeSession)
+			users.PUT("/sessions/notifications", authHandler.UpdateSessionNotifications)
 			
 			// Enhanced profile management
 			profileGroup := users.Group("/profile")
@@ -156,6 +225,8 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 			dashboardGroup.GET("/stats", dashboardHandler.GetDashboardStats)
 			dashboardGroup.GET("/quick-actions", dashboardHandler.GetQuickActions)
 			dashboardGroup.GET("/notifications", dashboardHandler.GetNotifications)
+			dashboardGroup.PUT("/notifications/read-all", dashboardHandler.MarkAllNotificationsRead)
+			dashboardGroup.PUT("/notifications/:id/read", dashboardHandler.MarkNotificationRead)
 		}
 
 		// Plan routes (protected - authentication required)
@@ -166,17 +237,150 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 			planGroup.POST("", planHandler.CreatePlan)
 			planGroup.GET("", planHandler.GetPlans)
 			planGroup.GET("/:id", planHandler.GetPlan)
+			planGroup.PUT("/:id", planHandler.UpdatePlan)
+			planGroup.PATCH("/:id", planHandler.PatchPlan)
+			planGroup.DELETE("/:id", planHandler.DeletePlan)
+			planGroup.POST("/:id/archive", planHandler.ArchivePlan)
+			planGroup.POST("/:id/unarchive", planHandler.UnarchivePlan)
+			planGroup.POST("/:id/clone", planHandler.ClonePlan)
+
+			// Plan versions
+			planGroup.GET("/:id/versions", planHandler.GetPlanVersions)
+			planGroup.POST("/:id/versions", planHandler.PublishPlanVersion)
+			planGroup.GET("/:id/versions/:version", planHandler.GetPlanVersion)
+			planGroup.GET("/:id/diff", planHandler.DiffPlanVersions)
+			planGroup.GET("/:id/allergy-check", planHandler.CheckPlanAllergies)
 			
 			// Plan assignment (Admin/Trainer only)
 			planGroup.POST("/assign", planHandler.AssignPlan)
 			
 			// User plan access
 			planGroup.GET("/my-plans", planHandler.GetUserPlans)
+			planGroup.GET("/my-plans/:id", planHandler.GetUserPlan)
+			planGroup.POST("/my-plans/:id/migrate", planHandler.MigrateUserPlan)
+			planGroup.POST("/my-plans/:id/completions", planHandler.LogWorkoutCompletion)
+			planGroup.POST("/my-plans/:id/reschedule", planHandler.RescheduleWorkout)
+			planGroup.GET("/my-plans/:id/grocery-list", planHandler.GetGroceryList)
+			planGroup.GET("/calendar", planHandler.GetCalendar)
 			planGroup.GET("/assigned", planHandler.GetAssignedPlans)
 			
 			// Member plan selection
 			planGroup.GET("/available", planHandler.GetAvailablePlans)
 			planGroup.POST("/request", planHandler.RequestPlanAssignment)
+
+			// Plan request review (routed trainer or Admin)
+			planGroup.GET("/requests", planHandler.GetPlanRequests)
+			planGroup.POST("/requests/:id/approve", planHandler.ApprovePlanRequest)
+			planGroup.POST("/requests/:id/reject", planHandler.RejectPlanRequest)
+			planGroup.POST("/requests/:id/withdraw", planHandler.WithdrawPlanRequest)
+		}
+
+		// Exercise library routes (protected - authentication required)
+		exerciseGroup := api.Group("/exercises")
+		exerciseGroup.Use(auth.AuthMiddleware(cfg)) // Apply authentication middleware
+		{
+			// Catalog search (all roles)
+			exerciseGroup.GET("", exerciseHandler.SearchExercises)
+			exerciseGroup.GET("/filters", exerciseHandler.GetExerciseFilters)
+			exerciseGroup.GET("/:id", exerciseHandler.GetExercise)
+
+			// Custom exercises (Admin/Trainer) and review (Admin only)
+			exerciseGroup.POST("", exerciseHandler.CreateExercise)
+			exerciseGroup.PUT("/:id", exerciseHandler.UpdateExercise)
+			exerciseGroup.GET("/pending", exerciseHandler.GetPendingExercises)
+			exerciseGroup.POST("/:id/approve", exerciseHandler.ApproveExercise)
+			exerciseGroup.POST("/:id/reject", exerciseHandler.RejectExercise)
+			exerciseGroup.DELETE("/:id", exerciseHandler.DeleteExercise)
+		}
+
+		// Workout logging routes (protected - authentication required)
+		workoutGroup := api.Group("/workouts")
+		workoutGroup.Use(auth.AuthMiddleware(cfg)) // Apply authentication middleware
+		{
+			workoutGroup.GET("/sessions", workoutHandler.GetSessions)
+			workoutGroup.POST("/sessions", workoutHandler.StartSession)
+			workoutGroup.POST("/sessions/sync", workoutHandler.SubmitSessions)
+			workoutGroup.GET("/sessions/:id", workoutHandler.GetSession)
+			workoutGroup.POST("/sessions/:id/exercises", workoutHandler.LogExercise)
+			workoutGroup.POST("/sessions/:id/finish", workoutHandler.FinishSession)
+			workoutGroup.DELETE("/sessions/:id", workoutHandler.DeleteSession)
+
+			// Personal records
+			workoutGroup.GET("/records", workoutHandler.GetPersonalRecords)
+			workoutGroup.GET("/records/history", workoutHandler.GetRecordHistory)
+			workoutGroup.GET("/e1rm", workoutHandler.EstimateOneRepMax)
+
+			// Training analytics (member, their trainer or admin)
+			workoutGroup.GET("/analytics", workoutHandler.GetTrainingAnalytics)
+		}
+
+		// Progress tracking routes (protected - authentication required)
+		progressGroup := api.Group("/progress")
+		progressGroup.Use(auth.AuthMiddleware(cfg)) // Apply authentication middleware
+		{
+			progressGroup.GET("", progressHandler.GetProgressHistory)
+			progressGroup.POST("", progressHandler.CreateProgressLog)
+			progressGroup.GET("/summary", progressHandler.GetProgressSummary)
+			progressGroup.GET("/trend", progressHandler.GetWeightTrend)
+			progressGroup.GET("/measurements", progressHandler.GetMeasurementSeries)
+			progressGroup.GET("/measurements/sites", progressHandler.GetMeasurementSites)
+			progressGroup.GET("/measurements/changes", progressHandler.GetMeasurementReport)
+			progressGroup.GET("/photos", progressHandler.GetPhotoTimeline)
+			progressGroup.POST("/photos", progressHandler.UploadPhoto)
+			progressGroup.GET("/photos/compare", progressHandler.ComparePhotos)
+			progressGroup.GET("/photos/:id/image", progressHandler.GetPhotoImage)
+			progressGroup.PUT("/photos/:id/sharing", progressHandler.SetPhotoSharing)
+			progressGroup.DELETE("/photos/:id", progressHandler.DeletePhoto)
+			progressGroup.GET("/:id", progressHandler.GetProgressLog)
+			progressGroup.PUT("/:id", progressHandler.UpdateProgressLog)
+			progressGroup.DELETE("/:id", progressHandler.DeleteProgressLog)
+		}
+
+		// Health metric routes (protected - authentication required)
+		metricsGroup := api.Group("/metrics")
+		metricsGroup.Use(auth.AuthMiddleware(cfg)) // Apply authentication middleware
+		{
+			metricsGroup.GET("", metricsHandler.GetMetrics)
+			metricsGroup.GET("/history", metricsHandler.GetMetricsHistory)
+		}
+
+		// Goal routes (protected - authentication required)
+		goalGroup := api.Group("/goals")
+		goalGroup.Use(auth.AuthMiddleware(cfg)) // Apply authentication middleware
+		{
+			goalGroup.GET("", goalHandler.GetGoals)
+			goalGroup.POST("", goalHandler.CreateGoal)
+			goalGroup.GET("/:id", goalHandler.GetGoal)
+			goalGroup.PUT("/:id", goalHandler.UpdateGoal)
+			goalGroup.DELETE("/:id", goalHandler.DeleteGoal)
+		}
+
+		// Nutrition routes (protected - authentication required)
+		nutritionGroup := api.Group("/nutrition")
+		nutritionGroup.Use(auth.AuthMiddleware(cfg)) // Apply authentication middleware
+		{
+			// Food database
+			nutritionGroup.GET("/foods", nutritionHandler.SearchFoods)
+			nutritionGroup.GET("/foods/categories", nutritionHandler.GetFoodCategories)
+			nutritionGroup.GET("/foods/:id", nutritionHandler.GetFood)
+			nutritionGroup.POST("/foods", nutritionHandler.CreateFood)
+			nutritionGroup.PUT("/foods/:id", nutritionHandler.UpdateFood)
+			nutritionGroup.DELETE("/foods/:id", nutritionHandler.DeleteFood)
+
+			// Recipes
+			nutritionGroup.GET("/recipes", nutritionHandler.SearchRecipes)
+			nutritionGroup.GET("/recipes/:id", nutritionHandler.GetRecipe)
+			nutritionGroup.POST("/recipes", nutritionHandler.CreateRecipe)
+			nutritionGroup.PUT("/recipes/:id", nutritionHandler.UpdateRecipe)
+			nutritionGroup.DELETE("/recipes/:id", nutritionHandler.DeleteRecipe)
+
+			// Meal logging, daily totals, targets and adherence
+			nutritionGroup.POST("/meals", nutritionHandler.LogMeal)
+			nutritionGroup.PUT("/meals/:id", nutritionHandler.UpdateMealEntry)
+			nutritionGroup.DELETE("/meals/:id", nutritionHandler.DeleteMealEntry)
+			nutritionGroup.GET("/days/:date", nutritionHandler.GetDay)
+			nutritionGroup.GET("/targets", nutritionHandler.GetTargets)
+			nutritionGroup.GET("/adherence", nutritionHandler.GetAdherence)
 		}
 	}
 
@@ -185,6 +389,15 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 	fmt.Println("   - User routes: /api/v1/users/*")
 	fmt.Println("   - Dashboard routes: /api/v1/dashboard/*")
 	fmt.Println("   - Plan routes: /api/v1/plans/*")
+	fmt.Println("   - Exercise routes: /api/v1/exercises/*")
+	fmt.Println("   - Workout routes: /api/v1/workouts/*")
+	fmt.Println("   - Progress routes: /api/v1/progress/*")
+	fmt.Println("   - Metrics routes: /api/v1/metrics/*")
+	fmt.Println("   - Goal routes: /api/v1/goals/*")
+	fmt.Println("   - Nutrition routes: /api/v1/nutrition/*")
+
+	// Publish token verification keys for other services
+	router.GET("/.well-known/jwks.json", authHandler.GetJWKS)
 
 	// Serve Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -200,10 +413,22 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 				"auth": gin.H{
 					"register": "POST /api/v1/auth/register",
 					"login": "POST /api/v1/auth/login",
+					"oidc_login": "GET /api/v1/auth/oidc/{provider}/login",
+					"oidc_callback": "GET /api/v1/auth/oidc/{provider}/callback",
+					"logout": "POST /api/v1/auth/logout",
+					"rotate_keys": "POST /api/v1/auth/keys/rotate",
+					"jwks": "GET /.well-known/jwks.json",
 				},
 				"users": gin.H{
 					"profile": "GET /api/v1/users/profile",
 					"update": "PUT /api/v1/users/profile",
+					"identities": "GET /api/v1/users/identities",
+					"link_identity": "POST /api/v1/users/identities/{provider}/link",
+					"unlink_identity": "DELETE /api/v1/users/identities/{id}",
+					"sessions": "GET /api/v1/users/sessions",
+					"revoke_session": "DELETE /api/v1/users/sessions/{id}",
+					"revoke_other_sessions": "DELETE /api/v1/users/sessions",
+					"session_notifications": "PUT /api/v1/users/sessions/notifications",
 					"profile_setup": "POST /api/v1/users/profile/setup",
 					"profile_image": "POST /api/v1/users/profile/upload-image",
 					"profile_completion": "GET /api/v1/users/profile/completion",
@@ -219,16 +444,111 @@ func setupRoutes(router *gin.Engine, cfg *config.Config) {
 					"stats": "GET /api/v1/dashboard/stats",
 					"quick_actions": "GET /api/v1/dashboard/quick-actions",
 					"notifications": "GET /api/v1/dashboard/notifications",
+					"notification_read": "PUT /api/v1/dashboard/notifications/{id}/read",
+					"notifications_read_all": "PUT /api/v1/dashboard/notifications/read-all",
 				},
 				"plans": gin.H{
 					"create": "POST /api/v1/plans",
 					"list": "GET /api/v1/plans",
 					"get": "GET /api/v1/plans/{id}",
+					"update": "PUT /api/v1/plans/{id}",
+					"patch": "PATCH /api/v1/plans/{id}",
+					"delete": "DELETE /api/v1/plans/{id}",
+					"archive": "POST /api/v1/plans/{id}/archive",
+					"unarchive": "POST /api/v1/plans/{id}/unarchive",
+					"clone": "POST /api/v1/plans/{id}/clone",
+					"versions": "GET /api/v1/plans/{id}/versions",
+					"publish_version": "POST /api/v1/plans/{id}/versions",
+					"version": "GET /api/v1/plans/{id}/versions/{version}",
+					"diff": "GET /api/v1/plans/{id}/diff?from=&to=",
+					"allergy_check": "GET /api/v1/plans/{id}/allergy-check?user_id=",
 					"assign": "POST /api/v1/plans/assign",
 					"my_plans": "GET /api/v1/plans/my-plans",
+					"my_plan": "GET /api/v1/plans/my-plans/{id}",
+					"migrate": "POST /api/v1/plans/my-plans/{id}/migrate",
+					"log_completion": "POST /api/v1/plans/my-plans/{id}/completions",
+					"reschedule": "POST /api/v1/plans/my-plans/{id}/reschedule",
+					"grocery_list": "GET /api/v1/plans/my-plans/{id}/grocery-list?from=&to=",
+					"calendar": "GET /api/v1/plans/calendar",
 					"assigned": "GET /api/v1/plans/assigned",
 					"available": "GET /api/v1/plans/available",
 					"request": "POST /api/v1/plans/request",
+					"requests": "GET /api/v1/plans/requests",
+					"approve_request": "POST /api/v1/plans/requests/{id}/approve",
+					"reject_request": "POST /api/v1/plans/requests/{id}/reject",
+					"withdraw_request": "POST /api/v1/plans/requests/{id}/withdraw",
+				},
+				"exercises": gin.H{
+					"search": "GET /api/v1/exercises?q=&muscle=&equipment=&difficulty=&category=",
+					"filters": "GET /api/v1/exercises/filters",
+					"get": "GET /api/v1/exercises/{id}",
+					"create": "POST /api/v1/exercises",
+					"update": "PUT /api/v1/exercises/{id}",
+					"pending": "GET /api/v1/exercises/pending",
+					"approve": "POST /api/v1/exercises/{id}/approve",
+					"reject": "POST /api/v1/exercises/{id}/reject",
+					"delete": "DELETE /api/v1/exercises/{id}",
+				},
+				"workouts": gin.H{
+					"sessions": "GET /api/v1/workouts/sessions?from=&to=&status=",
+					"start": "POST /api/v1/workouts/sessions",
+					"sync": "POST /api/v1/workouts/sessions/sync",
+					"get": "GET /api/v1/workouts/sessions/{id}",
+					"log_exercise": "POST /api/v1/workouts/sessions/{id}/exercises",
+					"finish": "POST /api/v1/workouts/sessions/{id}/finish",
+					"delete": "DELETE /api/v1/workouts/sessions/{id}",
+					"records": "GET /api/v1/workouts/records?exercise_id=&exercise=",
+					"record_history": "GET /api/v1/workouts/records/history?exercise_id=&exercise=&type=",
+					"e1rm": "GET /api/v1/workouts/e1rm?weight=&reps=",
+					"analytics": "GET /api/v1/workouts/analytics?user_id=&weeks=",
+				},
+				"progress": gin.H{
+					"history": "GET /api/v1/progress?user_id=&from=&to=&page=&page_size=",
+					"create": "POST /api/v1/progress",
+					"summary": "GET /api/v1/progress/summary?user_id=",
+					"trend": "GET /api/v1/progress/trend?user_id=&days=",
+					"measurement_series": "GET /api/v1/progress/measurements?site=&side=&user_id=&unit=&from=&to=",
+					"measurement_sites": "GET /api/v1/progress/measurements/sites",
+					"measurement_changes": "GET /api/v1/progress/measurements/changes?user_id=&unit=&from=&to=",
+					"photos": "GET /api/v1/progress/photos?user_id=&pose=&from=&to=",
+					"upload_photo": "POST /api/v1/progress/photos",
+					"compare_photos": "GET /api/v1/progress/photos/compare?from=&to=&user_id=",
+					"photo_image": "GET /api/v1/progress/photos/{id}/image",
+					"photo_sharing": "PUT /api/v1/progress/photos/{id}/sharing",
+					"delete_photo": "DELETE /api/v1/progress/photos/{id}",
+					"get": "GET /api/v1/progress/{id}",
+					"update": "PUT /api/v1/progress/{id}",
+					"delete": "DELETE /api/v1/progress/{id}",
+				},
+				"metrics": gin.H{
+					"current": "GET /api/v1/metrics?user_id=&activity_level=",
+					"history": "GET /api/v1/metrics/history?user_id=&from=&to=",
+				},
+				"goals": gin.H{
+					"list": "GET /api/v1/goals?user_id=&status=",
+					"create": "POST /api/v1/goals",
+					"get": "GET /api/v1/goals/{id}",
+					"update": "PUT /api/v1/goals/{id}",
+					"delete": "DELETE /api/v1/goals/{id}",
+				},
+				"nutrition": gin.H{
+					"search_foods": "GET /api/v1/nutrition/foods?q=&category=",
+					"categories": "GET /api/v1/nutrition/foods/categories",
+					"get_food": "GET /api/v1/nutrition/foods/{id}",
+					"create_food": "POST /api/v1/nutrition/foods",
+					"update_food": "PUT /api/v1/nutrition/foods/{id}",
+					"delete_food": "DELETE /api/v1/nutrition/foods/{id}",
+					"search_recipes": "GET /api/v1/nutrition/recipes?q=&tag=&mine=",
+					"get_recipe": "GET /api/v1/nutrition/recipes/{id}?servings=",
+					"create_recipe": "POST /api/v1/nutrition/recipes",
+					"update_recipe": "PUT /api/v1/nutrition/recipes/{id}",
+					"delete_recipe": "DELETE /api/v1/nutrition/recipes/{id}",
+					"log_meal": "POST /api/v1/nutrition/meals",
+					"update_meal": "PUT /api/v1/nutrition/meals/{id}",
+					"delete_meal": "DELETE /api/v1/nutrition/meals/{id}",
+					"day": "GET /api/v1/nutrition/days/{date}?user_id=",
+					"targets": "GET /api/v1/nutrition/targets?user_id=",
+					"adherence": "GET /api/v1/nutrition/adherence?user_id=&from=&to=",
 				},
 			},
 		})
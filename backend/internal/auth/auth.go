@@ -2,11 +2,13 @@ package auth
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"fittrackplus/internal/common/config"
 	"fittrackplus/internal/common/database"
 	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/notification"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -25,14 +27,33 @@ type JWTClaims struct {
 type AuthService struct {
 	db       *gorm.DB
 	jwtSecret string
+	jwtAlgorithm string
+	keyManager *KeyManager
+	oidcProviders map[string]*OIDCProvider
+	notificationService *notification.NotificationService
 }
 
 // NewAuthService creates a new authentication service
 func NewAuthService(cfg *config.Config) *AuthService {
-	return &AuthService{
-		db:        database.GetDB(),
-		jwtSecret: cfg.JWTSecret,
+	oidcProviders := map[string]*OIDCProvider{}
+	for name, providerCfg := range cfg.OIDCProviders {
+		oidcProviders[name] = NewOIDCProvider(providerCfg)
 	}
+
+	service := &AuthService{
+		db:            database.GetDB(),
+		jwtSecret:     cfg.JWTSecret,
+		jwtAlgorithm:  cfg.JWTAlgorithm,
+		oidcProviders: oidcProviders,
+		notificationService: notification.NewNotificationService(cfg),
+	}
+
+	// Asymmetric algorithms sign with rotating keys; HS256 keeps using the shared secret
+	if service.usesKeyManager() {
+		service.keyManager = GetKeyManager(cfg)
+	}
+
+	return service
 }
 
 // RegisterRequest represents the data needed for user registration
@@ -65,8 +86,24 @@ type AuthResponse struct {
 	ExpiresAt time.Time   `json:"expires_at"`
 }
 
+// OIDCLoginResponse contains the URL the client should redirect the user to
+type OIDCLoginResponse struct {
+	Provider         string    `json:"provider"`
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// oauthStateTTL is how long a user has to complete the provider login
+const oauthStateTTL = 10 * time.Minute
+
 // Register creates a new user account
 func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
+	return s.RegisterFromDevice(req, DeviceInfo{})
+}
+
+// RegisterFromDevice creates a new user account and records the device of the first session
+func (s *AuthService) RegisterFromDevice(req *RegisterRequest, device DeviceInfo) (*AuthResponse, error) {
 	// Check if user already exists
 	var existingUser models.User
 	if err := s.db.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
@@ -96,7 +133,7 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 	}
 
 	// Generate JWT token
-	token, expiresAt, err := s.generateToken(&user)
+	token, expiresAt, err := s.generateToken(&user, device, "register")
 	if err != nil {
 		return nil, err
 	}
@@ -113,6 +150,11 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 
 // Login authenticates a user and returns a JWT token
 func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
+	return s.LoginFromDevice(req, DeviceInfo{})
+}
+
+// LoginFromDevice authenticates a user and records the login as a session for that device
+func (s *AuthService) LoginFromDevice(req *LoginRequest, device DeviceInfo) (*AuthResponse, error) {
 	// Find user by email
 	var user models.User
 	if err := s.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
@@ -133,7 +175,7 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 	}
 
 	// Generate JWT token
-	token, expiresAt, err := s.generateToken(&user)
+	token, expiresAt, err := s.generateToken(&user, device, "password")
 	if err != nil {
 		return nil, err
 	}
@@ -152,11 +194,26 @@ func (s *AuthService) Login(req *LoginRequest) (*AuthResponse, error) {
 func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	// Parse the token
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Only accept the signing method we are configured for, so an attacker
+		// can't switch a token to HS256 and sign it with a public key
+		if !s.usesKeyManager() {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("unexpected signing method")
+			}
+			return []byte(s.jwtSecret), nil
+		}
+
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		default:
 			return nil, errors.New("unexpected signing method")
 		}
-		return []byte(s.jwtSecret), nil
+
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token is missing a key ID")
+		}
+		return s.keyManager.VerificationKey(kid)
 	})
 
 	if err != nil {
@@ -205,11 +262,32 @@ func (s *AuthService) UpdateProfile(userID uint, req *UpdateProfileRequest) (*mo
 	return &user, nil
 }
 
-// generateToken creates a new JWT token for a user
-func (s *AuthService) generateToken(user *models.User) (string, time.Time, error) {
+// generateToken creates a new JWT token for a user and records it as a session
+func (s *AuthService) generateToken(user *models.User, device DeviceInfo, loginMethod string) (string, time.Time, error) {
 	// Set expiration time (24 hours from now)
 	expiresAt := time.Now().Add(24 * time.Hour)
 
+	// Every token gets its own ID so the session can be listed and revoked
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	tokenString, err := s.signToken(user, tokenID, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	// Only record the session once the token exists, so a signing failure leaves no orphan session
+	if _, err := s.createSession(user, tokenID, expiresAt, device, loginMethod); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// signToken signs the claims for a token with the configured algorithm
+func (s *AuthService) signToken(user *models.User, tokenID string, expiresAt time.Time) (string, error) {
 	// Create claims
 	claims := &JWTClaims{
 		UserID: user.ID,
@@ -221,17 +299,275 @@ func (s *AuthService) generateToken(user *models.User) (string, time.Time, error
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "fittrackplus",
 			Subject:   user.Email,
+			ID:        tokenID,
 		},
 	}
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// HS256 signs with the shared secret
+	if !s.usesKeyManager() {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(s.jwtSecret))
+	}
+
+	// Asymmetric algorithms sign with the current key and advertise it through "kid"
+	kid, algorithm, signingKey, err := s.keyManager.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil {
+		return "", errors.New("unsupported signing algorithm: " + algorithm)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
 
 	// Sign the token
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	return token.SignedString(signingKey)
+}
+
+// GetJWKS returns the public keys other services can use to verify our tokens
+func (s *AuthService) GetJWKS() (*JWKSet, error) {
+	if !s.usesKeyManager() {
+		// Symmetric secrets are never published
+		return &JWKSet{Keys: []JWK{}}, nil
+	}
+	return s.keyManager.JWKS()
+}
+
+// RotateSigningKey immediately replaces the current signing key
+func (s *AuthService) RotateSigningKey() error {
+	if !s.usesKeyManager() {
+		return errors.New("key rotation requires an asymmetric JWT algorithm")
+	}
+	return s.keyManager.Rotate()
+}
+
+// usesKeyManager reports whether tokens are signed with rotating asymmetric keys
+func (s *AuthService) usesKeyManager() bool {
+	return s.jwtAlgorithm != "" && s.jwtAlgorithm != "HS256"
+}
+
+// BeginOIDCLogin starts an authorization code + PKCE flow with the given provider
+// When linkUserID is set, the resulting identity is linked to that user instead of logging in
+func (s *AuthService) BeginOIDCLogin(providerName string, linkUserID *uint) (*OIDCLoginResponse, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, errors.New("unknown identity provider")
+	}
+
+	state, err := randomToken(24)
 	if err != nil {
-		return "", time.Time{}, err
+		return nil, err
+	}
+	nonce, err := randomToken(24)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := randomToken(48)
+	if err != nil {
+		return nil, err
 	}
 
-	return tokenString, expiresAt, nil
-} 
\ No newline at end of file
+	authURL, err := provider.AuthorizationURL(state, nonce, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean up abandoned flows while we're here
+	s.db.Where("expires_at < ?", time.Now()).Delete(&models.OAuthState{})
+
+	oauthState := models.OAuthState{
+		State:        state,
+		Provider:     providerName,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}
+	if err := s.db.Create(&oauthState).Error; err != nil {
+		return nil, err
+	}
+
+	return &OIDCLoginResponse{
+		Provider:         providerName,
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresAt:        oauthState.ExpiresAt,
+	}, nil
+}
+
+// CompleteOIDCLogin handles the provider callback: it validates the state, exchanges the code,
+// verifies the ID token and resolves (or creates) the matching user
+func (s *AuthService) CompleteOIDCLogin(providerName, state, code string, device DeviceInfo) (*AuthResponse, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, errors.New("unknown identity provider")
+	}
+
+	// States are single use - delete it before doing anything else
+	var oauthState models.OAuthState
+	if err := s.db.Where("state = ? AND provider = ?", state, providerName).First(&oauthState).Error; err != nil {
+		return nil, errors.New("invalid or expired login state")
+	}
+	s.db.Delete(&oauthState)
+	if time.Now().After(oauthState.ExpiresAt) {
+		return nil, errors.New("invalid or expired login state")
+	}
+
+	claims, err := provider.Exchange(code, oauthState.CodeVerifier, oauthState.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveOIDCUser(providerName, claims, oauthState.LinkUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is deactivated")
+	}
+
+	token, expiresAt, err := s.generateToken(user, device, "oidc:"+providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
+
+	return &AuthResponse{
+		Token:     token,
+		User:      *user,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// GetIdentities lists the external identities linked to a user
+func (s *AuthService) GetIdentities(userID uint) ([]models.UserIdentity, error) {
+	identities := []models.UserIdentity{}
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// UnlinkIdentity removes a linked identity, as long as the user keeps a way to sign in
+func (s *AuthService) UnlinkIdentity(userID, identityID uint) error {
+	var identity models.UserIdentity
+	if err := s.db.Where("id = ? AND user_id = ?", identityID, userID).First(&identity).Error; err != nil {
+		return errors.New("identity not found")
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	var identityCount int64
+	s.db.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&identityCount)
+	if user.Password == "" && identityCount <= 1 {
+		return errors.New("cannot unlink the only sign-in method; set a password first")
+	}
+
+	// Hard delete: (provider, subject) is unique, so a soft-deleted row would block relinking
+	return s.db.Unscoped().Delete(&identity).Error
+}
+
+// resolveOIDCUser finds the user for a verified identity, linking or creating as needed
+func (s *AuthService) resolveOIDCUser(providerName string, claims *OIDCClaims, linkUserID *uint) (*models.User, error) {
+	now := time.Now()
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+
+	// 1. Known identity - just log in (or refuse to link it to a different account)
+	var identity models.UserIdentity
+	err := s.db.Where("provider = ? AND subject = ?", providerName, claims.Subject).First(&identity).Error
+	if err == nil {
+		if linkUserID != nil && *linkUserID != identity.UserID {
+			return nil, errors.New("this identity is already linked to another account")
+		}
+		identity.Email = email
+		identity.EmailVerified = claims.EmailVerified
+		identity.LastLoginAt = &now
+		s.db.Save(&identity)
+
+		var user models.User
+		if err := s.db.First(&user, identity.UserID).Error; err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var user models.User
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		switch {
+		case linkUserID != nil:
+			// 2. Explicit link requested by a signed-in user
+			if err := tx.First(&user, *linkUserID).Error; err != nil {
+				return err
+			}
+
+		default:
+			if email == "" {
+				return errors.New("identity provider did not return an email address")
+			}
+
+			err := tx.Where("LOWER(email) = ?", email).First(&user).Error
+			switch {
+			case err == nil:
+				// 3. Existing account - only link automatically on a verified email
+				if !claims.EmailVerified {
+					return errors.New("email is not verified by the identity provider; sign in and link it from your account instead")
+				}
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				// 4. New member
+				user = models.User{
+					Email:     email,
+					FirstName: firstNonEmpty(claims.GivenName, claims.Name, strings.Split(email, "@")[0]),
+					LastName:  claims.FamilyName,
+					Role:      "member",
+					IsActive:  true,
+				}
+				if err := tx.Create(&user).Error; err != nil {
+					return err
+				}
+			default:
+				return err
+			}
+		}
+
+		// Clear identities soft-deleted before unlinking became a hard delete
+		if err := tx.Unscoped().Where("provider = ? AND subject = ? AND deleted_at IS NOT NULL", providerName, claims.Subject).
+			Delete(&models.UserIdentity{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:        user.ID,
+			Provider:      providerName,
+			Subject:       claims.Subject,
+			Email:         email,
+			EmailVerified: claims.EmailVerified,
+			LastLoginAt:   &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -5,7 +5,6 @@ import (
 
 	"fittrackplus/internal/common/config"
 	"fittrackplus/internal/common/database"
-	"fittrackplus/internal/common/models"
 )
 
 func setupTestDB(t *testing.T) *config.Config {
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"strconv"
 
 	"fittrackplus/internal/common/config"
 
@@ -44,7 +45,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Register the user
-	response, err := h.authService.Register(&req)
+	response, err := h.authService.RegisterFromDevice(&req, deviceFromRequest(c))
 	if err != nil {
 		// Check if it's a duplicate email error
 		if err.Error() == "user with this email already exists" {
@@ -88,7 +89,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Login the user
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.LoginFromDevice(&req, deviceFromRequest(c))
 	if err != nil {
 		// Check if it's an authentication error
 		if err.Error() == "invalid email or password" || err.Error() == "account is deactivated" {
@@ -175,4 +176,466 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, user)
-} 
\ No newline at end of file
+}
+
+// OIDCLogin starts a social login with an OpenID Connect provider
+// @Summary Start social login
+// @Description Start an OpenID Connect authorization code + PKCE flow and get the provider login URL
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name (e.g. google)"
+// @Success 200 {object} OIDCLoginResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /auth/oidc/{provider}/login [get]
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	response, err := h.authService.BeginOIDCLogin(c.Param("provider"), nil)
+	if err != nil {
+		if err.Error() == "unknown identity provider" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "Failed to start social login",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// OIDCCallback completes a social login
+// @Summary Complete social login
+// @Description Exchange the provider authorization code, verify the ID token and sign the user in
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name (e.g. google)"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State returned by the login endpoint"
+// @Success 200 {object} AuthResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	// Providers report user cancellation and similar failures through the error parameter
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Social login was not completed",
+			"details": providerError,
+		})
+		return
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "code and state are required",
+		})
+		return
+	}
+
+	response, err := h.authService.CompleteOIDCLogin(c.Param("provider"), state, code, deviceFromRequest(c))
+	if err != nil {
+		switch err.Error() {
+		case "unknown identity provider":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case "invalid or expired login state":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case "this identity is already linked to another account":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Social login failed",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetIdentities lists the current user's linked identities
+// @Summary List linked identities
+// @Description List the social login identities linked to the current user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.UserIdentity
+// @Failure 401 {object} map[string]interface{}
+// @Router /users/identities [get]
+func (h *AuthHandler) GetIdentities(c *gin.Context) {
+	userID, exists := GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	identities, err := h.authService.GetIdentities(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get identities",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// LinkIdentity starts linking a new provider identity to the current user
+// @Summary Link a social identity
+// @Description Start an OpenID Connect flow whose callback links the identity to the current user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider name (e.g. google)"
+// @Success 200 {object} OIDCLoginResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/identities/{provider}/link [post]
+func (h *AuthHandler) LinkIdentity(c *gin.Context) {
+	userID, exists := GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	response, err := h.authService.BeginOIDCLogin(c.Param("provider"), &userID)
+	if err != nil {
+		if err.Error() == "unknown identity provider" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "Failed to start identity linking",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UnlinkIdentity removes a linked identity from the current user
+// @Summary Unlink a social identity
+// @Description Remove a linked identity (refused if it is the user's only way to sign in)
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Identity ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/identities/{id} [delete]
+func (h *AuthHandler) UnlinkIdentity(c *gin.Context) {
+	userID, exists := GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	identityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid identity ID",
+		})
+		return
+	}
+
+	if err := h.authService.UnlinkIdentity(userID, uint(identityID)); err != nil {
+		if err.Error() == "identity not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to unlink identity",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Identity unlinked successfully",
+	})
+}
+
+// GetJWKS publishes the public keys used to sign access tokens
+// @Summary JSON Web Key Set
+// @Description Public keys (RS256/EdDSA) other services can use to verify FitTrack+ access tokens. Served at /.well-known/jwks.json
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} JWKSet
+// @Failure 500 {object} map[string]interface{}
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) GetJWKS(c *gin.Context) {
+	jwks, err := h.authService.GetJWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load signing keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Let verifiers cache the key set, but not for longer than a rotation check
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
+
+// RotateSigningKey forces an immediate signing key rotation
+// @Summary Rotate JWT signing key
+// @Description Generate a new signing key; the previous key keeps verifying tokens during the overlap window (Admin only)
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /auth/keys/rotate [post]
+func (h *AuthHandler) RotateSigningKey(c *gin.Context) {
+	if err := h.authService.RotateSigningKey(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to rotate signing key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Signing key rotated successfully",
+	})
+}
+
+// Logout signs out the current session
+// @Summary Logout
+// @Description Revoke the session of the token used for this request
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID, exists := GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	if sessionID, ok := GetCurrentSessionID(c); ok {
+		if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to logout",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
+	})
+}
+
+// GetSessions lists the current user's active sessions
+// @Summary List active sessions
+// @Description List the devices the current user is signed in on
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} SessionResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /users/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, exists := GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	currentSessionID, _ := GetCurrentSessionID(c)
+	sessions, err := h.authService.GetSessions(userID, currentSessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get sessions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession signs out one of the current user's sessions
+// @Summary Revoke a session
+// @Description Sign out a specific device
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, exists := GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session ID",
+		})
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, uint(sessionID)); err != nil {
+		if err.Error() == "session not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions signs out every other device
+// @Summary Revoke all other sessions
+// @Description Sign out every session except the one making this request
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /users/sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, exists := GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	currentSessionID, _ := GetCurrentSessionID(c)
+	revoked, err := h.authService.RevokeOtherSessions(userID, currentSessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke sessions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Other sessions revoked successfully",
+		"revoked": revoked,
+	})
+}
+
+// UpdateSessionNotifications turns new device login notifications on or off
+// @Summary Configure new device login notifications
+// @Description Enable or disable the security notification sent when the account signs in from a new device
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body map[string]interface{} true "Notification setting (enabled)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /users/sessions/notifications [put]
+func (h *AuthHandler) UpdateSessionNotifications(c *gin.Context) {
+	var req struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found in context",
+		})
+		return
+	}
+
+	if err := h.authService.SetNewDeviceNotifications(userID, *req.Enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update notification setting",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification setting updated",
+		"notify_on_new_device": *req.Enabled,
+	})
+}
+
+// deviceFromRequest extracts the client's user agent and IP address
+func deviceFromRequest(c *gin.Context) DeviceInfo {
+	return DeviceInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
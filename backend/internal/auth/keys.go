@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+
+	"gorm.io/gorm"
+)
+
+// KeyManager manages the asymmetric keys used to sign access tokens
+// Keys live in the database so every server instance signs and verifies with the same set
+type KeyManager struct {
+	db               *gorm.DB
+	algorithm        string
+	rotationInterval time.Duration
+	overlap          time.Duration
+
+	mu       sync.RWMutex
+	keys     []*loadedKey
+	loadedAt time.Time
+}
+
+// loadedKey is a signing key with its parsed key material
+type loadedKey struct {
+	record  models.SigningKey
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// keyCacheTTL controls how often keys are reloaded so rotations done by other instances are picked up
+const keyCacheTTL = time.Minute
+
+var (
+	sharedKeyManager     *KeyManager
+	sharedKeyManagerOnce sync.Once
+)
+
+// GetKeyManager returns the process-wide key manager
+// It is shared so the auth handler and every middleware instance use one key cache
+func GetKeyManager(cfg *config.Config) *KeyManager {
+	sharedKeyManagerOnce.Do(func() {
+		sharedKeyManager = &KeyManager{
+			db:               database.GetDB(),
+			algorithm:        cfg.JWTAlgorithm,
+			rotationInterval: cfg.JWTKeyRotationInterval,
+			overlap:          cfg.JWTKeyOverlap,
+		}
+	})
+	return sharedKeyManager
+}
+
+// SigningKey returns the key new tokens should be signed with, creating one if needed
+func (m *KeyManager) SigningKey() (kid, algorithm string, key crypto.Signer, err error) {
+	if err := m.ensureLoaded(); err != nil {
+		return "", "", nil, err
+	}
+
+	current := m.currentKey()
+	if current == nil {
+		// No usable key yet (first start, or the last one retired) - create one
+		if err := m.Rotate(); err != nil {
+			return "", "", nil, err
+		}
+		if current = m.currentKey(); current == nil {
+			return "", "", nil, errors.New("no active signing key")
+		}
+	}
+
+	return current.record.Kid, current.record.Algorithm, current.private, nil
+}
+
+// VerificationKey returns the public key for a kid, including retired keys still in their overlap window
+func (m *KeyManager) VerificationKey(kid string) (crypto.PublicKey, error) {
+	if err := m.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	if key := m.findKey(kid); key != nil {
+		return key.public, nil
+	}
+
+	// Another instance may have just rotated - reload once before giving up
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	if key := m.findKey(kid); key != nil {
+		return key.public, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// JWKS returns the public keys that may still verify tokens
+func (m *KeyManager) JWKS() (*JWKSet, error) {
+	if err := m.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := &JWKSet{Keys: []JWK{}}
+	now := time.Now()
+	for _, key := range m.keys {
+		if key.record.ExpiresAt != nil && now.After(*key.record.ExpiresAt) {
+			continue
+		}
+		jwk, err := NewJWK(key.record.Kid, key.record.Algorithm, key.public)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+// Rotate creates a new signing key and retires the current ones
+// Retired keys keep verifying tokens for the configured overlap window
+func (m *KeyManager) Rotate() error {
+	record, err := generateSigningKey(m.algorithm)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(m.overlap)
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SigningKey{}).
+			Where("retires_at IS NULL OR retires_at > ?", now).
+			Updates(map[string]interface{}{"retires_at": now, "expires_at": expiresAt}).Error; err != nil {
+			return err
+		}
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🔑 Rotated JWT signing key, new kid: %s", record.Kid)
+	return m.reload()
+}
+
+// RotateIfDue rotates the signing key when the current one is older than the rotation interval
+func (m *KeyManager) RotateIfDue() error {
+	if err := m.reload(); err != nil {
+		return err
+	}
+
+	current := m.currentKey()
+	if current != nil && time.Since(current.record.CreatedAt) < m.rotationInterval {
+		return nil
+	}
+	return m.Rotate()
+}
+
+// StartRotationScheduler checks for due rotations in the background
+func (m *KeyManager) StartRotationScheduler(checkInterval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := m.RotateIfDue(); err != nil {
+				log.Printf("❌ JWT key rotation failed: %v", err)
+			}
+		}
+	}()
+}
+
+// currentKey returns the newest key that is not retired
+func (m *KeyManager) currentKey() *loadedKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	var current *loadedKey
+	for _, key := range m.keys {
+		if key.record.RetiresAt != nil && !now.Before(*key.record.RetiresAt) {
+			continue
+		}
+		if current == nil || key.record.CreatedAt.After(current.record.CreatedAt) {
+			current = key
+		}
+	}
+	return current
+}
+
+func (m *KeyManager) findKey(kid string) *loadedKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range m.keys {
+		if key.record.Kid != kid {
+			continue
+		}
+		if key.record.ExpiresAt != nil && now.After(*key.record.ExpiresAt) {
+			return nil
+		}
+		return key
+	}
+	return nil
+}
+
+func (m *KeyManager) ensureLoaded() error {
+	m.mu.RLock()
+	fresh := m.keys != nil && time.Since(m.loadedAt) < keyCacheTTL
+	m.mu.RUnlock()
+
+	if fresh {
+		return nil
+	}
+	return m.reload()
+}
+
+// reload reads all unexpired keys from the database
+func (m *KeyManager) reload() error {
+	var records []models.SigningKey
+	if err := m.db.Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at").Find(&records).Error; err != nil {
+		return err
+	}
+
+	keys := []*loadedKey{}
+	for _, record := range records {
+		key, err := parseSigningKey(record)
+		if err != nil {
+			log.Printf("❌ Skipping unreadable signing key %s: %v", record.Kid, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.loadedAt = time.Now()
+	m.mu.Unlock()
+	return nil
+}
+
+// generateSigningKey creates a new key pair for the algorithm
+func generateSigningKey(algorithm string) (*models.SigningKey, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case "RS256":
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case "EdDSA":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := randomToken(12)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		Kid:        kid,
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	}, nil
+}
+
+// parseSigningKey decodes the stored PEM key material
+func parseSigningKey(record models.SigningKey) (*loadedKey, error) {
+	block, _ := pem.Decode([]byte(record.PrivateKey))
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+
+	return &loadedKey{
+		record:  record,
+		private: private,
+		public:  private.Public(),
+	}, nil
+}
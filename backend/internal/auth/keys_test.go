@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSigningKey_JWKRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		wantKty   string
+	}{
+		{name: "RSA key", algorithm: "RS256", wantKty: "RSA"},
+		{name: "Ed25519 key", algorithm: "EdDSA", wantKty: "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := generateSigningKey(tt.algorithm)
+			if err != nil {
+				t.Fatalf("Unexpected error generating key: %v", err)
+			}
+
+			key, err := parseSigningKey(*record)
+			if err != nil {
+				t.Fatalf("Unexpected error parsing stored key: %v", err)
+			}
+
+			// Sign a token the same way generateToken does
+			claims := &JWTClaims{
+				UserID: 42,
+				RegisteredClaims: jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				},
+			}
+			token := jwt.NewWithClaims(jwt.GetSigningMethod(tt.algorithm), claims)
+			token.Header["kid"] = record.Kid
+			signed, err := token.SignedString(key.private)
+			if err != nil {
+				t.Fatalf("Unexpected error signing token: %v", err)
+			}
+
+			// Verify it using only the published JWK, as another service would
+			jwk, err := NewJWK(record.Kid, record.Algorithm, key.public)
+			if err != nil {
+				t.Fatalf("Unexpected error building JWK: %v", err)
+			}
+			if jwk.Kty != tt.wantKty || jwk.Kid != record.Kid || jwk.Use != "sig" {
+				t.Errorf("Unexpected JWK: %+v", jwk)
+			}
+
+			publicKey, err := jwk.PublicKey()
+			if err != nil {
+				t.Fatalf("Unexpected error reading JWK: %v", err)
+			}
+
+			parsed := &JWTClaims{}
+			_, err = jwt.ParseWithClaims(signed, parsed, func(token *jwt.Token) (interface{}, error) {
+				return publicKey, nil
+			}, jwt.WithValidMethods([]string{tt.algorithm}))
+			if err != nil {
+				t.Fatalf("Token did not verify with published key: %v", err)
+			}
+			if parsed.UserID != 42 {
+				t.Errorf("Expected user ID 42, got %d", parsed.UserID)
+			}
+		})
+	}
+}
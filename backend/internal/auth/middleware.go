@@ -79,10 +79,24 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Check that the session behind the token hasn't been revoked
+		session, err := authService.ValidateSession(claims)
+		if err != nil {
+			fmt.Printf("❌ Session check failed: %v\n", err)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Session has expired or been revoked",
+			})
+			c.Abort()
+			return
+		}
+
 		// Store user information in context for later use
 		c.Set("user", user)
 		c.Set("user_id", claims.UserID)
 		c.Set("user_role", claims.Role)
+		if session != nil {
+			c.Set("session_id", session.ID)
+		}
 
 		fmt.Printf("✅ Authentication successful for user: %s (%s)\n", user.Email, user.Role)
 		c.Next()
@@ -141,6 +155,15 @@ func GetCurrentUserID(c *gin.Context) (uint, bool) {
 	return userID.(uint), true
 }
 
+// GetCurrentSessionID extracts the current session ID from the context
+func GetCurrentSessionID(c *gin.Context) (uint, bool) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		return 0, false
+	}
+	return sessionID.(uint), true
+}
+
 // GetCurrentUserRole extracts the current user role from the context
 func GetCurrentUserRole(c *gin.Context) (string, bool) {
 	userRole, exists := c.Get("user_role")
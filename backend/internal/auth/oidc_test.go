@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIssuer is a minimal local OpenID Connect provider for tests
+type mockIssuer struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	kid           string
+	clientID      string
+	code          string
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	m := &mockIssuer{key: key, kid: "test-key-1", clientID: "fittrack-test", code: "auth-code"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(JWKSet{Keys: []JWK{{
+			Kty: "RSA",
+			Kid: m.kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != m.code || pkceChallenge(r.Form.Get("code_verifier")) != m.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     m.signIDToken(t, m.claims),
+		})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func (m *mockIssuer) provider() *OIDCProvider {
+	return NewOIDCProvider(config.OIDCProviderConfig{
+		Name:        "mock",
+		IssuerURL:   m.server.URL,
+		ClientID:    m.clientID,
+		RedirectURL: "http://localhost:8080/api/v1/auth/oidc/mock/callback",
+		Scopes:      []string{"openid", "email"},
+	})
+}
+
+func (m *mockIssuer) defaultClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            m.clientID,
+		"sub":            "user-123",
+		"email":          "member@example.com",
+		"email_verified": true,
+		"nonce":          m.nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func (m *mockIssuer) signIDToken(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		t.Fatalf("Failed to sign id_token: %v", err)
+	}
+	return signed
+}
+
+func TestOIDCProvider_AuthorizationCodeFlow(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := issuer.provider()
+
+	state, nonce, verifier := "state-1", "nonce-1", "verifier-with-enough-entropy"
+	authURL, err := provider.AuthorizationURL(state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("Unexpected error building authorization URL: %v", err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Invalid authorization URL: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") != pkceChallenge(verifier) {
+		t.Errorf("Expected S256 PKCE challenge in authorization URL, got %s", authURL)
+	}
+	if query.Get("state") != state || query.Get("nonce") != nonce {
+		t.Errorf("Expected state and nonce in authorization URL, got %s", authURL)
+	}
+
+	// The mock issuer only accepts the verifier matching this challenge
+	issuer.codeChallenge = query.Get("code_challenge")
+	issuer.nonce = nonce
+	issuer.claims = issuer.defaultClaims()
+
+	claims, err := provider.Exchange(issuer.code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("Unexpected error exchanging code: %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "member@example.com" || !claims.EmailVerified {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	if _, err := provider.Exchange(issuer.code, "wrong-verifier", nonce); err == nil {
+		t.Errorf("Expected error for wrong PKCE verifier but got none")
+	}
+}
+
+func TestOIDCProvider_VerifyIDToken(t *testing.T) {
+	issuer := newMockIssuer(t)
+	issuer.nonce = "nonce-1"
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr bool
+	}{
+		{
+			name:    "Valid token",
+			token:   func() string { return issuer.signIDToken(t, issuer.defaultClaims()) },
+			wantErr: false,
+		},
+		{
+			name: "Wrong audience",
+			token: func() string {
+				claims := issuer.defaultClaims()
+				claims["aud"] = "someone-else"
+				return issuer.signIDToken(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Wrong issuer",
+			token: func() string {
+				claims := issuer.defaultClaims()
+				claims["iss"] = "https://evil.example.com"
+				return issuer.signIDToken(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Expired",
+			token: func() string {
+				claims := issuer.defaultClaims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return issuer.signIDToken(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Nonce mismatch",
+			token: func() string {
+				claims := issuer.defaultClaims()
+				claims["nonce"] = "replayed"
+				return issuer.signIDToken(t, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Signed by unknown key",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.defaultClaims())
+				token.Header["kid"] = issuer.kid
+				signed, _ := token.SignedString(otherKey)
+				return signed
+			},
+			wantErr: true,
+		},
+	}
+
+	provider := issuer.provider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.VerifyIDToken(tt.token(), issuer.nonce)
+
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/notification"
+)
+
+// DeviceInfo describes the client a login came from
+type DeviceInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionResponse represents one of the user's sessions
+type SessionResponse struct {
+	ID          uint       `json:"id"`
+	DeviceName  string     `json:"device_name"`
+	UserAgent   string     `json:"user_agent"`
+	IPAddress   string     `json:"ip_address"`
+	LoginMethod string     `json:"login_method"`
+	IsCurrent   bool       `json:"is_current"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastSeenAt  time.Time  `json:"last_seen_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// lastSeenUpdateInterval limits how often a session's last-seen time is written
+const lastSeenUpdateInterval = 5 * time.Minute
+
+// createSession records a login and notifies the user if it came from a device we haven't seen before
+func (s *AuthService) createSession(user *models.User, tokenID string, expiresAt time.Time, device DeviceInfo, loginMethod string) (*models.UserSession, error) {
+	deviceID := deviceFingerprint(device.UserAgent)
+
+	// Look for earlier logins from this device before recording the new one
+	var previousLogins, knownDeviceLogins int64
+	s.db.Model(&models.UserSession{}).Unscoped().Where("user_id = ?", user.ID).Count(&previousLogins)
+	s.db.Model(&models.UserSession{}).Unscoped().Where("user_id = ? AND device_id = ?", user.ID, deviceID).Count(&knownDeviceLogins)
+
+	now := time.Now()
+	session := models.UserSession{
+		UserID:      user.ID,
+		TokenID:     tokenID,
+		DeviceID:    deviceID,
+		DeviceName:  describeDevice(device.UserAgent),
+		UserAgent:   device.UserAgent,
+		IPAddress:   device.IPAddress,
+		LoginMethod: loginMethod,
+		LastSeenAt:  now,
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	if isNewDeviceLogin(previousLogins, knownDeviceLogins, user.NotifyOnNewDevice) {
+		s.notificationService.Notify(user.ID, notification.Message{
+			Type:     "warning",
+			Category: "security",
+			Title:    "New device login",
+			Message: fmt.Sprintf("Your account was signed in from %s (IP %s) at %s. If this wasn't you, revoke the session and change your password.",
+				session.DeviceName, session.IPAddress, now.Format("2006-01-02 15:04 MST")),
+			Link: "/profile/sessions",
+		})
+	}
+
+	return &session, nil
+}
+
+// ValidateSession checks that the session behind a token has not been revoked
+// Tokens issued before sessions were tracked have no token ID and are accepted until they expire
+func (s *AuthService) ValidateSession(claims *JWTClaims) (*models.UserSession, error) {
+	if claims.ID == "" {
+		return nil, nil
+	}
+
+	var session models.UserSession
+	if err := s.db.Where("token_id = ? AND user_id = ?", claims.ID, claims.UserID).First(&session).Error; err != nil {
+		return nil, errors.New("session not found")
+	}
+	if !session.IsActive() {
+		return nil, errors.New("session has been revoked")
+	}
+
+	// Keep last-seen reasonably fresh without writing on every request
+	if time.Since(session.LastSeenAt) > lastSeenUpdateInterval {
+		session.LastSeenAt = time.Now()
+		s.db.Model(&session).Update("last_seen_at", session.LastSeenAt)
+	}
+
+	return &session, nil
+}
+
+// GetSessions lists the user's active sessions, flagging the one making the request
+func (s *AuthService) GetSessions(userID, currentSessionID uint) ([]SessionResponse, error) {
+	var sessions []models.UserSession
+	if err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []SessionResponse{}
+	for _, session := range sessions {
+		responses = append(responses, SessionResponse{
+			ID:          session.ID,
+			DeviceName:  session.DeviceName,
+			UserAgent:   session.UserAgent,
+			IPAddress:   session.IPAddress,
+			LoginMethod: session.LoginMethod,
+			IsCurrent:   session.ID == currentSessionID,
+			CreatedAt:   session.CreatedAt,
+			LastSeenAt:  session.LastSeenAt,
+			ExpiresAt:   session.ExpiresAt,
+			RevokedAt:   session.RevokedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+// RevokeSession signs a single session out
+func (s *AuthService) RevokeSession(userID, sessionID uint) error {
+	result := s.db.Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs out every session except the current one
+func (s *AuthService) RevokeOtherSessions(userID, currentSessionID uint) (int64, error) {
+	result := s.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, currentSessionID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// SetNewDeviceNotifications turns the new device login notification on or off
+func (s *AuthService) SetNewDeviceNotifications(userID uint, enabled bool) error {
+	return s.db.Model(&models.User{}).Where("id = ?", userID).Update("notify_on_new_device", enabled).Error
+}
+
+// isNewDeviceLogin reports whether a login should trigger a new device notification
+// The very first login (registration) is not a "new device"
+func isNewDeviceLogin(previousLogins, knownDeviceLogins int64, notify bool) bool {
+	return previousLogins > 0 && knownDeviceLogins == 0 && notify
+}
+
+// deviceFingerprint identifies a device by its user agent
+func deviceFingerprint(userAgent string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(userAgent)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// describeDevice turns a user agent into a short human readable name like "Chrome on Windows"
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/") || strings.Contains(userAgent, "Opera"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	case strings.Contains(userAgent, "okhttp") || strings.Contains(userAgent, "Dart/"):
+		browser = "FitTrack+ app"
+	}
+
+	os := "unknown OS"
+	switch {
+	case strings.Contains(userAgent, "iPhone"):
+		os = "iPhone"
+	case strings.Contains(userAgent, "iPad"):
+		os = "iPad"
+	case strings.Contains(userAgent, "Android"):
+		os = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		os = "Windows"
+	case strings.Contains(userAgent, "Mac OS X") || strings.Contains(userAgent, "Macintosh"):
+		os = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		os = "Linux"
+	}
+
+	return browser + " on " + os
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/testdb"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestDescribeDevice(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"", "Unknown device"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "Chrome on Windows"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0", "Edge on Windows"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 OPR/106.0.0.0", "Opera on Linux"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.2; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox on macOS"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1", "Safari on iPhone"},
+		{"Mozilla/5.0 (iPad; CPU OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1", "Safari on iPad"},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36", "Chrome on Android"},
+		{"Dart/3.2 (dart:io)", "FitTrack+ app on unknown OS"},
+		{"curl/8.4.0", "Unknown browser on unknown OS"},
+	}
+
+	for _, tt := range tests {
+		if got := describeDevice(tt.userAgent); got != tt.want {
+			t.Errorf("describeDevice(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestDeviceFingerprint(t *testing.T) {
+	userAgent := "Mozilla/5.0 (X11; Linux x86_64) Firefox/121.0"
+	if deviceFingerprint(userAgent) != deviceFingerprint("  "+userAgent+" ") {
+		t.Error("surrounding whitespace should not change the fingerprint")
+	}
+	if deviceFingerprint(userAgent) == deviceFingerprint("Mozilla/5.0 (X11; Linux x86_64) Firefox/122.0") {
+		t.Error("different user agents should have different fingerprints")
+	}
+}
+
+func TestIsNewDeviceLogin(t *testing.T) {
+	tests := []struct {
+		name              string
+		previousLogins    int64
+		knownDeviceLogins int64
+		notify            bool
+		want              bool
+	}{
+		{"first login ever", 0, 0, true, false},
+		{"new device", 3, 0, true, true},
+		{"known device", 3, 2, true, false},
+		{"notifications turned off", 3, 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNewDeviceLogin(tt.previousLogins, tt.knownDeviceLogins, tt.notify); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateSession(t *testing.T) {
+	cfg := testdb.Connect(t)
+	authService := NewAuthService(cfg)
+	user := testdb.CreateUser(t, "member")
+
+	tokenID := "session-test-" + time.Now().Format("150405.000000000")
+	session, err := authService.createSession(user, tokenID, time.Now().Add(time.Hour), DeviceInfo{UserAgent: "Dart/3.2 (dart:io)", IPAddress: "127.0.0.1"}, "password")
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	claims := func(id string) *JWTClaims {
+		return &JWTClaims{UserID: user.ID, RegisteredClaims: jwt.RegisteredClaims{ID: id}}
+	}
+
+	// Tokens issued before sessions were tracked carry no token ID
+	legacy, err := authService.ValidateSession(claims(""))
+	if err != nil || legacy != nil {
+		t.Fatalf("expected a token without an ID to be accepted without a session, got %+v, %v", legacy, err)
+	}
+
+	active, err := authService.ValidateSession(claims(tokenID))
+	if err != nil || active == nil || active.ID != session.ID {
+		t.Fatalf("expected the active session, got %+v, %v", active, err)
+	}
+
+	if _, err := authService.ValidateSession(claims("unknown-" + tokenID)); err == nil || err.Error() != "session not found" {
+		t.Errorf("expected an unknown token ID to be refused, got %v", err)
+	}
+
+	if err := authService.RevokeSession(user.ID, session.ID); err != nil {
+		t.Fatalf("failed to revoke session: %v", err)
+	}
+	if _, err := authService.ValidateSession(claims(tokenID)); err == nil || err.Error() != "session has been revoked" {
+		t.Errorf("expected the revoked session to be refused, got %v", err)
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -18,12 +22,43 @@ type Config struct {
 	DBName     string
 	
 	// Server configuration
-	Port string
+	Port    string
+	GinMode string // debug, release or test
 	
 	// JWT configuration
-	JWTSecret string
+	JWTSecret              string        // HS256 secret (only used when JWTAlgorithm is HS256)
+	JWTAlgorithm           string        // HS256, RS256 or EdDSA
+	JWTKeyRotationInterval time.Duration // how often a new signing key is generated
+	JWTKeyOverlap          time.Duration // how long a retired key keeps verifying tokens
+
+	// OpenID Connect providers for social login, keyed by provider name
+	OIDCProviders map[string]OIDCProviderConfig
+
+	// Plan assignment rules: how many plans of each type a member may follow at once
+	// The limits apply to the whole deployment; there is no per-gym setting yet
+	MaxActivePlansPerType map[string]int
+
+	// Formula used to estimate one-rep maxes for personal records: epley or brzycki
+	E1RMFormula string
+
+	// File uploads: directory private files such as progress photos are stored in, and their size limit in bytes
+	UploadPath    string
+	MaxUploadSize int64
 }
 
+// OIDCProviderConfig holds the settings for a single OpenID Connect provider
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// DefaultJWTSecret is the placeholder secret used when JWT_SECRET is not set
+const DefaultJWTSecret = "your-secret-key-change-in-production"
+
 // LoadConfig loads configuration from environment variables
 // This function returns a pointer to Config struct
 func LoadConfig() *Config {
@@ -42,13 +77,102 @@ func LoadConfig() *Config {
 		DBName:     getEnv("DB_NAME", "fittrackplus"),
 		
 		// Server settings
-		Port: getEnv("PORT", "8080"),
+		Port:    getEnv("PORT", "8080"),
+		GinMode: getEnv("GIN_MODE", "release"),
 		
 		// JWT settings
-		JWTSecret: getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		JWTSecret:              getEnv("JWT_SECRET", DefaultJWTSecret),
+		JWTAlgorithm:           getEnv("JWT_ALGORITHM", "RS256"),
+		JWTKeyRotationInterval: time.Duration(getEnvInt("JWT_KEY_ROTATION_DAYS", 30)) * 24 * time.Hour,
+		JWTKeyOverlap:          time.Duration(getEnvInt("JWT_KEY_OVERLAP_HOURS", 48)) * time.Hour,
+
+		// Social login settings
+		OIDCProviders: loadOIDCProviders(),
+
+		// Plan assignment settings
+		MaxActivePlansPerType: loadPlanLimits(),
+
+		// Workout tracking settings
+		E1RMFormula: getEnv("E1RM_FORMULA", "epley"),
+
+		// File upload settings
+		UploadPath:    getEnv("UPLOAD_PATH", "./uploads"),
+		MaxUploadSize: int64(getEnvInt("MAX_FILE_SIZE", 10*1024*1024)),
 	}
 }
 
+// MaxActivePlans returns how many active plans of a type a member may have (0 means unlimited)
+func (c *Config) MaxActivePlans(planType string) int {
+	if limit, ok := c.MaxActivePlansPerType[planType]; ok {
+		return limit
+	}
+	return 1
+}
+
+// Validate checks the configuration for settings that are unsafe to run with
+func (c *Config) Validate() error {
+	switch c.JWTAlgorithm {
+	case "HS256", "RS256", "EdDSA":
+	default:
+		return errors.New("JWT_ALGORITHM must be one of HS256, RS256, EdDSA")
+	}
+
+	if c.E1RMFormula != "epley" && c.E1RMFormula != "brzycki" {
+		return errors.New("E1RM_FORMULA must be epley or brzycki")
+	}
+
+	if c.UploadPath == "" || c.MaxUploadSize <= 0 {
+		return errors.New("UPLOAD_PATH must be set and MAX_FILE_SIZE must be positive")
+	}
+
+	// Never run a release build with the well-known placeholder secret (it only signs HS256 tokens)
+	if c.JWTAlgorithm == "HS256" && c.GinMode == "release" && (c.JWTSecret == "" || c.JWTSecret == DefaultJWTSecret) {
+		return errors.New("JWT_SECRET must be set to a non-default value in release mode")
+	}
+
+	if c.JWTAlgorithm != "HS256" {
+		if c.JWTKeyRotationInterval <= 0 {
+			return errors.New("JWT_KEY_ROTATION_DAYS must be at least 1")
+		}
+		// Retired keys must outlive the tokens they signed (tokens are valid for 24 hours)
+		if c.JWTKeyOverlap < 24*time.Hour {
+			return errors.New("JWT_KEY_OVERLAP_HOURS must be at least 24")
+		}
+	}
+
+	return nil
+}
+
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS (e.g. "google,keycloak")
+// Each provider is configured with OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID,
+// OIDC_<NAME>_CLIENT_SECRET, OIDC_<NAME>_REDIRECT_URL and optionally OIDC_<NAME>_SCOPES
+func loadOIDCProviders() map[string]OIDCProviderConfig {
+	providers := map[string]OIDCProviderConfig{}
+
+	for _, name := range splitList(os.Getenv("OIDC_PROVIDERS")) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       splitList(getEnv(prefix+"SCOPES", "openid,email,profile")),
+		}
+
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			log.Printf("Warning: OIDC provider %q is missing an issuer or client ID, skipping it", name)
+			continue
+		}
+
+		providers[name] = provider
+	}
+
+	return providers
+}
+
 // getEnv is a helper function to get environment variables with defaults
 // In Go, we can return multiple values from a function
 func getEnv(key, defaultValue string) string {
@@ -61,4 +185,47 @@ func getEnv(key, defaultValue string) string {
 	}
 	
 	return value
-} 
\ No newline at end of file
+}
+
+// loadPlanLimits reads PLAN_MAX_ACTIVE_PER_TYPE (e.g. "fitness=1,diet=1,physio=2")
+// Types that are not listed allow one active plan; 0 removes the limit.
+// The setting is process-wide: a deployment serving several gyms shares one set of limits
+func loadPlanLimits() map[string]int {
+	limits := map[string]int{}
+
+	for _, item := range splitList(os.Getenv("PLAN_MAX_ACTIVE_PER_TYPE")) {
+		planType, value, found := strings.Cut(item, "=")
+		if !found {
+			log.Printf("Warning: ignoring plan limit %q, expected type=count", item)
+			continue
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit < 0 {
+			log.Printf("Warning: ignoring plan limit %q, count must be a non-negative number", item)
+			continue
+		}
+		limits[strings.TrimSpace(planType)] = limit
+	}
+
+	return limits
+}
+
+// getEnvInt gets an integer environment variable, falling back to the default if unset or invalid
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// splitList splits a comma-separated environment value into trimmed, non-empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestLoadPlanLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  map[string]int
+	}{
+		{"unset", "", map[string]int{}},
+		{"several types", "fitness=1,diet=2", map[string]int{"fitness": 1, "diet": 2}},
+		{"spaces and unlimited", " fitness = 0 , physio=3 ", map[string]int{"fitness": 0, "physio": 3}},
+		{"malformed items skipped", "fitness,diet=two,physio=-1,weight=2", map[string]int{"weight": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PLAN_MAX_ACTIVE_PER_TYPE", tt.value)
+			limits := loadPlanLimits()
+			if len(limits) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, limits)
+			}
+			for planType, limit := range tt.want {
+				if got, ok := limits[planType]; !ok || got != limit {
+					t.Errorf("expected %v, got %v", tt.want, limits)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxActivePlans(t *testing.T) {
+	cfg := &Config{MaxActivePlansPerType: map[string]int{"fitness": 2, "physio": 0}}
+	if got := cfg.MaxActivePlans("fitness"); got != 2 {
+		t.Errorf("expected 2 fitness plans, got %d", got)
+	}
+	if got := cfg.MaxActivePlans("physio"); got != 0 {
+		t.Errorf("expected physio plans to be unlimited, got %d", got)
+	}
+	if got := cfg.MaxActivePlans("diet"); got != 1 {
+		t.Errorf("expected unlisted types to allow 1 plan, got %d", got)
+	}
+}
@@ -66,10 +66,37 @@ func AutoMigrate() error {
 		&models.AdminProfile{},
 		&models.PhysioProfile{},
 		&models.Plan{},
+		&models.PlanDay{},
+		&models.PlanWorkout{},
+		&models.PlanVersion{},
+		&models.Exercise{},
+		&models.ExercisePrescription{},
+		&models.PlanMeal{},
+		&models.PlanMealFood{},
+		&models.PlanFoodSubstitution{},
 		&models.UserPlan{},
+		&models.PlanAssignmentRequest{},
+		&models.WorkoutCompletion{},
+		&models.PlanCalendarEntry{},
+		&models.WorkoutSession{},
+		&models.WorkoutExerciseLog{},
+		&models.WorkoutSetLog{},
+		&models.PersonalRecord{},
 		&models.ProgressLog{},
+		&models.BodyMeasurement{},
+		&models.ProgressPhoto{},
+		&models.Goal{},
+		&models.Food{},
+		&models.MealEntry{},
+		&models.Recipe{},
+		&models.RecipeIngredient{},
 		&models.Booking{},
 		&models.Payment{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.SigningKey{},
+		&models.UserSession{},
+		&models.Notification{},
 	)
 }
 
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// BodyMeasurement is one typed circumference recorded with a progress entry
+// Values are stored in centimetres and converted to the reader's unit preference
+type BodyMeasurement struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        uint      `json:"user_id" gorm:"index:idx_body_measurement_site;not null"`
+	ProgressLogID uint      `json:"progress_log_id" gorm:"index;not null"`
+	Site          string    `json:"site" gorm:"index:idx_body_measurement_site;size:32;not null"` // neck, shoulders, chest, waist, abdomen, hips, arm, forearm, thigh, calf
+	Side          string    `json:"side" gorm:"size:8"`                                           // left or right for limbs, empty otherwise
+	ValueCm       float64   `json:"value_cm"`
+	MeasuredAt    time.Time `json:"measured_at" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Exercise is an entry in the shared exercise catalog
+// Trainers can contribute custom exercises, which stay pending until an admin approves them
+type Exercise struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Name              string         `json:"name" gorm:"uniqueIndex;not null"`
+	Category          string         `json:"category"`          // strength, cardio, mobility, physio
+	PrimaryMuscles    string         `json:"primary_muscles"`   // JSON string of muscle groups
+	SecondaryMuscles  string         `json:"secondary_muscles"` // JSON string of muscle groups
+	Equipment         string         `json:"equipment"`         // JSON string of equipment
+	Difficulty        string         `json:"difficulty"`        // beginner, intermediate, advanced
+	Instructions      string         `json:"instructions"`
+	MediaURLs         string         `json:"media_urls"`                             // JSON string of image/video URLs
+	Contraindications string         `json:"contraindications"`                      // JSON string of conditions the exercise should be avoided with
+	Aliases           string         `json:"aliases"`                                // JSON string of alternative names
+	Status            string         `json:"status" gorm:"default:'approved';index"` // pending, approved, rejected
+	IsCustom          bool           `json:"is_custom" gorm:"default:false"`
+	CreatedBy         *uint          `json:"created_by"`
+	ReviewedBy        *uint          `json:"reviewed_by"`
+	ReviewNote        string         `json:"review_note"`
+	ReviewedAt        *time.Time     `json:"reviewed_at"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
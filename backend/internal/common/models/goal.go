@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Goal is a member's target for a weight, body-fat, measurement, performance or habit metric
+// Its current value and progress are recomputed from the member's logs
+type Goal struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	UserID      uint   `json:"user_id" gorm:"index;not null"`
+	Type        string `json:"type" gorm:"size:16;not null"` // weight, body_fat, measurement, performance, habit
+	Title       string `json:"title" gorm:"not null"`
+	Description string `json:"description"`
+
+	// What the goal tracks, depending on its type
+	MeasurementSite string  `json:"measurement_site,omitempty"` // measurement goals
+	MeasurementSide string  `json:"measurement_side,omitempty"`
+	ExerciseID      *uint   `json:"exercise_id,omitempty"` // performance goals
+	ExerciseName    string  `json:"exercise_name,omitempty"`
+	RecordType      string  `json:"record_type,omitempty"`     // performance goals: heaviest_weight, most_reps, best_e1rm, fastest_time, longest_distance
+	DistanceMeters  float64 `json:"distance_meters,omitempty"` // fastest_time goals, e.g. 5000 for a 5k
+	Habit           string  `json:"habit,omitempty"`           // habit goals: workouts_per_week, weigh_ins_per_week
+
+	Unit          string     `json:"unit"`
+	BaselineValue float64    `json:"baseline_value"`
+	TargetValue   float64    `json:"target_value"`
+	CurrentValue  float64    `json:"current_value"`
+	Progress      float64    `json:"progress"` // 0-100
+	StartDate     time.Time  `json:"start_date"`
+	Deadline      time.Time  `json:"deadline"`
+	Status        string     `json:"status" gorm:"index;default:'active'"` // active, achieved, missed
+	CompletedAt   *time.Time `json:"completed_at"`
+	EvaluatedAt   *time.Time `json:"evaluated_at"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity links an external OpenID Connect identity to a user
+// A user can have several identities (e.g. Google and a gym SSO provider)
+type UserIdentity struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	UserID        uint           `json:"user_id" gorm:"index;not null"`
+	Provider      string         `json:"provider" gorm:"uniqueIndex:idx_identity_provider_subject;not null"`
+	Subject       string         `json:"subject" gorm:"uniqueIndex:idx_identity_provider_subject;not null"` // "sub" claim from the provider
+	Email         string         `json:"email"`
+	EmailVerified bool           `json:"email_verified"`
+	LastLoginAt   *time.Time     `json:"last_login_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationship
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// OAuthState stores a pending authorization code flow between the login redirect and the callback
+type OAuthState struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	State        string    `json:"-" gorm:"uniqueIndex;not null"`
+	Provider     string    `json:"provider" gorm:"not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"` // PKCE verifier, never leaves the server
+	Nonce        string    `json:"-" gorm:"not null"`
+	LinkUserID   *uint     `json:"link_user_id"` // set when an authenticated user is linking a new identity
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SigningKey is an asymmetric key used to sign access tokens
+// Keys are rotated on a schedule; retired keys stay published until they expire
+// so tokens signed just before a rotation keep validating
+type SigningKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Kid        string     `json:"kid" gorm:"uniqueIndex;not null"`
+	Algorithm  string     `json:"algorithm" gorm:"not null"`  // RS256, EdDSA
+	PrivateKey string     `json:"-" gorm:"not null"`          // PKCS#8 PEM
+	PublicKey  string     `json:"public_key" gorm:"not null"` // PKIX PEM
+	RetiresAt  *time.Time `json:"retires_at"`                 // no longer used for signing after this time
+	ExpiresAt  *time.Time `json:"expires_at"`                 // removed from the JWKS after this time
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
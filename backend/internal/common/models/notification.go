@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification is an in-app message for a user (security alerts, plan updates, achievements...)
+type Notification struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"index;not null"`
+	Type      string         `json:"type" gorm:"default:'info'"` // info, warning, success, error
+	Category  string         `json:"category" gorm:"index"`      // security, plan, progress, goal, ...
+	Title     string         `json:"title" gorm:"not null"`
+	Message   string         `json:"message"`
+	Link      string         `json:"link"` // optional frontend route the notification points to
+	IsRead    bool           `json:"is_read" gorm:"default:false"`
+	ReadAt    *time.Time     `json:"read_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationship
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
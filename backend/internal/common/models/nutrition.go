@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Food is an entry in the local food database, with nutrients per 100 g
+// Admin and trainer entries are shared; members' custom foods are private to them
+type Food struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	Name         string  `json:"name" gorm:"index;not null"`
+	Brand        string  `json:"brand"`
+	Category     string  `json:"category" gorm:"index"` // protein, dairy, grains, fruit, vegetables, legumes, fats, snacks, beverages, other
+	Calories     float64 `json:"calories"`              // kcal per 100 g
+	Protein      float64 `json:"protein"`               // g per 100 g
+	Carbs        float64 `json:"carbs"`                 // g per 100 g
+	Fat          float64 `json:"fat"`                   // g per 100 g
+	Fiber        float64 `json:"fiber"`                 // g per 100 g
+	Sugar        float64 `json:"sugar"`                 // g per 100 g
+	Sodium       float64 `json:"sodium"`                // mg per 100 g
+	Allergens    string  `json:"allergens"`             // JSON string of allergen tags, e.g. ["milk","gluten"]
+	ServingGrams float64 `json:"serving_grams"`         // default portion, e.g. 50 for one egg
+	ServingLabel string  `json:"serving_label"`         // e.g. "1 large egg"
+	IsPrivate    bool    `json:"is_private" gorm:"default:false"`
+	CreatedBy    *uint   `json:"created_by" gorm:"index"` // nil for the seeded database
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// MealEntry is a portion of food a member logged for a meal on a day
+// Nutrients are calculated when the entry is saved, so later food edits keep the log intact
+type MealEntry struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	UserID   uint      `json:"user_id" gorm:"index:idx_meal_entries_user_date;not null"`
+	Date     time.Time `json:"date" gorm:"type:date;index:idx_meal_entries_user_date;not null"`
+	Meal     string    `json:"meal" gorm:"size:16;not null"` // breakfast, lunch, dinner, snack
+	FoodID   *uint     `json:"food_id" gorm:"index"`
+	RecipeID *uint     `json:"recipe_id,omitempty" gorm:"index"` // set instead of FoodID for recipes
+	FoodName string    `json:"food_name" gorm:"not null"`
+	Grams    float64   `json:"grams"`
+	Calories float64   `json:"calories"`
+	Protein  float64   `json:"protein"`
+	Carbs    float64   `json:"carbs"`
+	Fat      float64   `json:"fat"`
+	Fiber    float64   `json:"fiber"`
+	Notes    string    `json:"notes"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Recipe is a dish made from foods in the food database
+// Nutrients are per serving and recalculated from the ingredients whenever the recipe is saved
+type Recipe struct {
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	Name         string  `json:"name" gorm:"index;not null"`
+	Description  string  `json:"description"`
+	Servings     int     `json:"servings" gorm:"not null;default:1"`
+	YieldGrams   float64 `json:"yield_grams"`   // weight of the finished dish
+	ServingGrams float64 `json:"serving_grams"` // yield divided by servings
+	PrepMinutes  int     `json:"prep_minutes"`
+	CookMinutes  int     `json:"cook_minutes"`
+	Steps        string  `json:"steps"`     // JSON string of instructions, in order
+	Tags         string  `json:"tags"`      // JSON string of tags, e.g. ["breakfast","high-protein"]
+	Allergens    string  `json:"allergens"` // JSON string of the ingredients' allergen tags
+	Calories     float64 `json:"calories"`  // kcal per serving
+	Protein      float64 `json:"protein"`   // g per serving
+	Carbs        float64 `json:"carbs"`     // g per serving
+	Fat          float64 `json:"fat"`       // g per serving
+	Fiber        float64 `json:"fiber"`     // g per serving
+	IsPrivate    bool    `json:"is_private" gorm:"default:false"`
+	CreatedBy    *uint   `json:"created_by" gorm:"index"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Ingredients []RecipeIngredient `json:"ingredients,omitempty" gorm:"foreignKey:RecipeID"`
+}
+
+// RecipeIngredient is a quantity of a food used in a recipe, with its nutrients
+type RecipeIngredient struct {
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	RecipeID uint    `json:"recipe_id" gorm:"index;not null"`
+	Position int     `json:"position"`
+	FoodID   *uint   `json:"food_id" gorm:"index"`
+	FoodName string  `json:"food_name" gorm:"not null"`
+	Category string  `json:"category"`
+	Grams    float64 `json:"grams"`
+	Calories float64 `json:"calories"`
+	Protein  float64 `json:"protein"`
+	Carbs    float64 `json:"carbs"`
+	Fat      float64 `json:"fat"`
+	Fiber    float64 `json:"fiber"`
+	Notes    string  `json:"notes"` // e.g. "finely chopped"
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
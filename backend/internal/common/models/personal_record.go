@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// PersonalRecord is a best performance on an exercise, detected from logged sets
+// Every improvement is kept, so the rows for an exercise and record type form its PR history
+type PersonalRecord struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	UserID         uint      `json:"user_id" gorm:"index:idx_personal_record_exercise;not null"`
+	ExerciseKey    string    `json:"exercise_key" gorm:"index:idx_personal_record_exercise;size:128;not null"` // catalog ID or lower-cased name
+	ExerciseID     *uint     `json:"exercise_id"`
+	ExerciseName   string    `json:"exercise_name" gorm:"not null"`
+	RecordType     string    `json:"record_type" gorm:"index:idx_personal_record_exercise;not null"` // heaviest_weight, most_reps, best_e1rm, fastest_time, longest_distance
+	Value          float64   `json:"value"`                                                          // kg, reps, seconds or meters depending on the record type
+	PreviousValue  *float64  `json:"previous_value"`                                                 // record it beat, nil for the first performance
+	WeightKg       float64   `json:"weight_kg"`                                                      // load of the set (the weight most_reps records are kept per)
+	Reps           int       `json:"reps"`
+	DistanceMeters float64   `json:"distance_meters"` // distance of the set (the distance fastest_time records are kept per)
+	SessionID      uint      `json:"session_id" gorm:"index"`
+	SetLogID       uint      `json:"set_log_id"`
+	AchievedAt     time.Time `json:"achieved_at" gorm:"index"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// PlanCalendarEntry places a training day of a member's plan on a calendar date
+// Entries are projected onto the member's preferred workout days when the plan is
+// assigned; rest days get no entry
+type PlanCalendarEntry struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserPlanID      uint       `json:"user_plan_id" gorm:"uniqueIndex:idx_calendar_plan_day;not null"`
+	UserID          uint       `json:"user_id" gorm:"index:idx_calendar_user_date;not null"`
+	PlanDay         int        `json:"plan_day" gorm:"uniqueIndex:idx_calendar_plan_day;not null"`
+	Date            time.Time  `json:"date" gorm:"type:date;index:idx_calendar_user_date;not null"`
+	RescheduledFrom *time.Time `json:"rescheduled_from" gorm:"type:date"` // original date when the member moved the workout
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
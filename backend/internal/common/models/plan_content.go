@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PlanDay is one day of a plan's program
+// Days are numbered from 1; when a plan defines fewer days than its duration
+// the sequence repeats (e.g. a 7-day week over a 12-week plan)
+type PlanDay struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	PlanID    uint           `json:"plan_id" gorm:"index;not null"`
+	DayNumber int            `json:"day_number" gorm:"not null"`
+	Name      string         `json:"name"` // e.g. "Upper body", "Rest"
+	IsRestDay bool           `json:"is_rest_day" gorm:"default:false"`
+	Notes     string         `json:"notes"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Workouts []PlanWorkout `json:"workouts,omitempty" gorm:"foreignKey:PlanDayID"`
+	Meals    []PlanMeal    `json:"meals,omitempty" gorm:"foreignKey:PlanDayID"` // diet plans
+}
+
+// PlanWorkout is a training session within a plan day
+type PlanWorkout struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	PlanDayID uint           `json:"plan_day_id" gorm:"index;not null"`
+	Name      string         `json:"name" gorm:"not null"`
+	Position  int            `json:"position"`
+	Notes     string         `json:"notes"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Exercises []ExercisePrescription `json:"exercises,omitempty" gorm:"foreignKey:PlanWorkoutID"`
+}
+
+// ExercisePrescription describes how an exercise should be performed in a workout
+type ExercisePrescription struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	PlanWorkoutID   uint           `json:"plan_workout_id" gorm:"index;not null"`
+	Position        int            `json:"position"`
+	ExerciseID      *uint          `json:"exercise_id" gorm:"index"` // catalog entry, if the exercise comes from the library
+	ExerciseName    string         `json:"exercise_name" gorm:"not null"`
+	Sets            int            `json:"sets"`
+	RepsMin         int            `json:"reps_min"`
+	RepsMax         int            `json:"reps_max"`
+	LoadType        string         `json:"load_type"` // kg, percent_1rm, bodyweight, rpe
+	LoadValue       float64        `json:"load_value"`
+	Tempo           string         `json:"tempo"` // eccentric-pause-concentric-pause, e.g. "3-1-1-0"
+	RestSeconds     int            `json:"rest_seconds"`
+	RPE             float64        `json:"rpe"`              // target rate of perceived exertion, 1-10
+	DurationSeconds int            `json:"duration_seconds"` // for timed or cardio work
+	DistanceMeters  float64        `json:"distance_meters"`
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationship
+	Exercise *Exercise `json:"exercise,omitempty" gorm:"foreignKey:ExerciseID"`
+}
+
+// PlanMeal is a meal within a diet plan day
+type PlanMeal struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	PlanDayID uint           `json:"plan_day_id" gorm:"index;not null"`
+	Meal      string         `json:"meal" gorm:"size:16;not null"` // breakfast, lunch, dinner, snack
+	Name      string         `json:"name"`                         // e.g. "Overnight oats"
+	Position  int            `json:"position"`
+	Notes     string         `json:"notes"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Foods []PlanMealFood `json:"foods,omitempty" gorm:"foreignKey:PlanMealID"`
+}
+
+// PlanMealFood is a quantity of a food or recipe prescribed in a meal
+// Name, category, allergens and nutrients are copied from the food database so
+// plans stay readable if an entry changes or is removed
+type PlanMealFood struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	PlanMealID  uint           `json:"plan_meal_id" gorm:"index;not null"`
+	Position    int            `json:"position"`
+	FoodID      *uint          `json:"food_id" gorm:"index"`
+	RecipeID    *uint          `json:"recipe_id" gorm:"index"` // set instead of FoodID for recipes
+	Servings    float64        `json:"servings"`               // recipe servings, when given that way
+	FoodName    string         `json:"food_name" gorm:"not null"`
+	Category    string         `json:"category"`
+	Grams       float64        `json:"grams"`
+	Calories    float64        `json:"calories"`
+	Protein     float64        `json:"protein"`
+	Carbs       float64        `json:"carbs"`
+	Fat         float64        `json:"fat"`
+	Fiber       float64        `json:"fiber"`
+	Allergens   string         `json:"allergens"`   // JSON string of allergen tags
+	Ingredients string         `json:"ingredients"` // JSON string of the recipe's ingredients scaled to the portion
+	Notes       string         `json:"notes"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Substitutions []PlanFoodSubstitution `json:"substitutions,omitempty" gorm:"foreignKey:PlanMealFoodID"`
+}
+
+// PlanFoodSubstitution is a food a member may eat instead of a prescribed one
+type PlanFoodSubstitution struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	PlanMealFoodID uint           `json:"plan_meal_food_id" gorm:"index;not null"`
+	Position       int            `json:"position"`
+	FoodID         *uint          `json:"food_id" gorm:"index"`
+	RecipeID       *uint          `json:"recipe_id" gorm:"index"`
+	FoodName       string         `json:"food_name" gorm:"not null"`
+	Category       string         `json:"category"`
+	Grams          float64        `json:"grams"`
+	Calories       float64        `json:"calories"`
+	Protein        float64        `json:"protein"`
+	Carbs          float64        `json:"carbs"`
+	Fat            float64        `json:"fat"`
+	Fiber          float64        `json:"fiber"`
+	Allergens      string         `json:"allergens"` // JSON string of allergen tags
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
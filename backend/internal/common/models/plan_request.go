@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PlanAssignmentRequest is a member's request to follow a plan
+// It is routed to the member's trainer, or to admins when the member has none,
+// and the plan is only assigned once the request is approved
+type PlanAssignmentRequest struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	UserID        uint           `json:"user_id" gorm:"index;not null"`
+	PlanID        uint           `json:"plan_id" gorm:"index;not null"`
+	Reason        string         `json:"reason"`
+	Status        string         `json:"status" gorm:"default:'pending';index"` // pending, approved, rejected, withdrawn
+	ReviewerID    *uint          `json:"reviewer_id" gorm:"index"`              // trainer the request is routed to; nil routes to admins
+	ReviewedBy    *uint          `json:"reviewed_by"`
+	ReviewComment string         `json:"review_comment"`
+	ReviewedAt    *time.Time     `json:"reviewed_at"`
+	UserPlanID    *uint          `json:"user_plan_id"` // assignment created on approval
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Plan Plan `json:"plan,omitempty" gorm:"foreignKey:PlanID"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// PlanVersion is an immutable, published snapshot of a plan
+// Members are pinned to the version they were assigned so later edits
+// to the plan do not change their program until they choose to migrate
+type PlanVersion struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	PlanID      uint      `json:"plan_id" gorm:"uniqueIndex:idx_plan_version;not null"`
+	Version     int       `json:"version" gorm:"uniqueIndex:idx_plan_version;not null"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	GoalType    string    `json:"goal_type"`
+	PlanType    string    `json:"plan_type"`
+	Duration    int       `json:"duration"`
+	Content     string    `json:"content"` // JSON snapshot of the plan days, workouts and exercises
+	Diet        string    `json:"diet"`    // JSON snapshot of the diet plan
+	Changelog   string    `json:"changelog"`
+	PublishedBy *uint     `json:"published_by"`
+	PublishedAt time.Time `json:"published_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
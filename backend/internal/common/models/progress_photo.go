@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// ProgressPhoto is a dated progress picture of a member in a standard pose
+// Photos are private to the member until they consent to share them with their trainer
+type ProgressPhoto struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	UserID            uint      `json:"user_id" gorm:"index:idx_progress_photo_timeline;not null"`
+	Pose              string    `json:"pose" gorm:"size:16;not null"` // front, side, back
+	TakenOn           time.Time `json:"taken_on" gorm:"index:idx_progress_photo_timeline;not null"`
+	FilePath          string    `json:"-" gorm:"not null"` // relative to the upload directory, never served directly
+	ContentType       string    `json:"content_type"`
+	SizeBytes         int64     `json:"size_bytes"`
+	Notes             string    `json:"notes"`
+	SharedWithTrainer bool      `json:"shared_with_trainer" gorm:"default:false"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
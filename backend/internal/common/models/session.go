@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSession records a single login (one issued access token) and the device it came from
+type UserSession struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      uint           `json:"user_id" gorm:"index;not null"`
+	TokenID     string         `json:"-" gorm:"uniqueIndex;not null"` // "jti" claim of the issued token
+	DeviceID    string         `json:"-" gorm:"index"`                // hash of the user agent, used to spot new devices
+	DeviceName  string         `json:"device_name"`                   // e.g. "Chrome on Windows"
+	UserAgent   string         `json:"user_agent"`
+	IPAddress   string         `json:"ip_address"`
+	LoginMethod string         `json:"login_method"` // password, register, oidc:<provider>
+	LastSeenAt  time.Time      `json:"last_seen_at"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	RevokedAt   *time.Time     `json:"revoked_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationship
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// IsActive reports whether the session can still be used
+func (s *UserSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
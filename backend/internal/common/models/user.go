@@ -17,6 +17,7 @@ type User struct {
 	Role      string         `json:"role" gorm:"default:'member'"` // member, trainer, physio, admin
 	Phone     string         `json:"phone"`
 	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	NotifyOnNewDevice bool   `json:"notify_on_new_device" gorm:"default:true"` // send a security notification for logins from new devices
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"` // Soft delete
@@ -27,6 +28,8 @@ type User struct {
 	Progress  []ProgressLog  `json:"progress,omitempty" gorm:"foreignKey:UserID"`
 	Bookings  []Booking      `json:"bookings,omitempty" gorm:"foreignKey:UserID"`
 	Payments  []Payment      `json:"payments,omitempty" gorm:"foreignKey:UserID"`
+	Identities []UserIdentity `json:"identities,omitempty" gorm:"foreignKey:UserID"`
+	Sessions  []UserSession  `json:"sessions,omitempty" gorm:"foreignKey:UserID"`
 }
 
 // UserProfile contains additional user information
@@ -39,6 +42,7 @@ type UserProfile struct {
 	Weight                float64        `json:"weight"` // in kg
 	Age                   int            `json:"age"`
 	Gender                string         `json:"gender"`
+	ActivityLevel         string         `json:"activity_level"` // sedentary, light, moderate, active, very_active; drives TDEE
 	
 	// Fitness Goals
 	Goals                 string         `json:"goals"` // JSON string of fitness goals
@@ -54,7 +58,7 @@ type UserProfile struct {
 	// Physical Measurements
 	BodyFatPercentage     float64        `json:"body_fat_percentage"`
 	MuscleMass            float64        `json:"muscle_mass"`
-	BodyMeasurements      string         `json:"body_measurements"` // JSON string of measurements
+	BodyMeasurements      string         `json:"body_measurements"` // JSON string of baseline measurements in cm, keyed like "waist" or "left_arm"
 	
 	// Profile Image
 	ProfileImageURL       string         `json:"profile_image_url"`
@@ -63,6 +67,7 @@ type UserProfile struct {
 	PreferredWorkoutTime  string         `json:"preferred_workout_time"`
 	WorkoutDays           string         `json:"workout_days"` // JSON string of days
 	CommunicationPreference string       `json:"communication_preference"`
+	UnitPreference        string         `json:"unit_preference"` // metric or imperial; measurements are stored in cm either way
 	
 	// Profile Completion
 	IsProfileComplete     bool           `json:"is_profile_complete" gorm:"default:false"`
@@ -82,16 +87,21 @@ type Plan struct {
 	Description string         `json:"description"`
 	GoalType    string         `json:"goal_type"` // lose_weight, gain_muscle, flexibility, rehab
 	PlanType    string         `json:"plan_type"` // fitness, diet, physio
-	Exercises   string         `json:"exercises"` // JSON string of exercises
-	Diet        string         `json:"diet"`      // JSON string of diet plan
-	PhysioExercises string     `json:"physio_exercises"` // JSON string of physio exercises
+	Exercises   string         `json:"exercises"` // Deprecated: legacy JSON string, use Days
+	Diet        string         `json:"diet"`      // Deprecated: legacy JSON string, use Days with meals
+	PhysioExercises string     `json:"physio_exercises"` // Deprecated: legacy JSON string, use Days
 	Duration    int            `json:"duration"` // in days
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	LatestVersion int          `json:"latest_version" gorm:"default:0"` // last published version number
+	CreatedBy   *uint          `json:"created_by" gorm:"index"`          // author; nil for legacy library plans
+	IsShared    bool           `json:"is_shared" gorm:"default:false"`   // shared library plan any trainer may edit
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
+	Days      []PlanDay  `json:"days,omitempty" gorm:"foreignKey:PlanID"` // working draft, members follow published versions
+	Versions  []PlanVersion `json:"versions,omitempty" gorm:"foreignKey:PlanID"`
 	UserPlans []UserPlan `json:"user_plans,omitempty" gorm:"foreignKey:PlanID"`
 }
 
@@ -100,6 +110,8 @@ type UserPlan struct {
 	ID         uint           `json:"id" gorm:"primaryKey"`
 	UserID     uint           `json:"user_id"`
 	PlanID     uint           `json:"plan_id"`
+	PlanVersionID *uint       `json:"plan_version_id"` // version the member is following
+	AssignedBy *uint          `json:"assigned_by" gorm:"index"` // trainer/admin who assigned the plan
 	Status     string         `json:"status" gorm:"default:'active'"` // active, completed, paused
 	AssignedAt time.Time      `json:"assigned_at"`
 	CompletedAt *time.Time    `json:"completed_at"`
@@ -110,6 +122,7 @@ type UserPlan struct {
 	// Relationships
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Plan Plan `json:"plan,omitempty" gorm:"foreignKey:PlanID"`
+	PlanVersion *PlanVersion `json:"plan_version,omitempty" gorm:"foreignKey:PlanVersionID"`
 }
 
 // ProgressLog tracks user progress over time
@@ -117,8 +130,8 @@ type ProgressLog struct {
 	ID              uint           `json:"id" gorm:"primaryKey"`
 	UserID          uint           `json:"user_id"`
 	Weight          float64        `json:"weight"`
-	Measurements    string         `json:"measurements"` // JSON string of body measurements
-	WorkoutCompletion string       `json:"workout_completion"` // JSON string of completed workouts
+	Measurements    string         `json:"measurements"` // JSON string of other measurements such as body_fat; circumferences are BodyMeasurement rows
+	WorkoutCompletion string       `json:"workout_completion"` // Deprecated: legacy JSON string, use WorkoutSession
 	PhysioProgress  string         `json:"physio_progress"` // JSON string of physio progress
 	Notes           string         `json:"notes"`
 	LoggedAt        time.Time      `json:"logged_at"`
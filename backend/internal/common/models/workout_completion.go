@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// WorkoutCompletion records that a member finished a scheduled workout of their plan
+// Plan days are counted from 1 over the whole plan duration, so a repeating weekly
+// program has distinct plan days for each week
+type WorkoutCompletion struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	UserPlanID      uint      `json:"user_plan_id" gorm:"uniqueIndex:idx_workout_completion;not null"`
+	UserID          uint      `json:"user_id" gorm:"index;not null"`
+	PlanDay         int       `json:"plan_day" gorm:"uniqueIndex:idx_workout_completion;not null"`
+	WorkoutPosition int       `json:"workout_position" gorm:"uniqueIndex:idx_workout_completion;not null"`
+	CompletedAt     time.Time `json:"completed_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
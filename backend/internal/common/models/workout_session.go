@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkoutSession is a workout a member actually performed
+// Sessions may follow a scheduled workout of one of the member's plans. ClientID is
+// generated by the app so sessions recorded offline can be submitted more than once
+type WorkoutSession struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	UserID          uint           `json:"user_id" gorm:"uniqueIndex:idx_workout_session_client;not null"`
+	ClientID        string         `json:"client_id" gorm:"uniqueIndex:idx_workout_session_client;size:64;not null"`
+	UserPlanID      *uint          `json:"user_plan_id" gorm:"index"`
+	PlanDay         int            `json:"plan_day"`         // scheduled plan day, 0 when not following a plan
+	WorkoutPosition int            `json:"workout_position"` // scheduled workout within the plan day
+	Name            string         `json:"name"`
+	Status          string         `json:"status" gorm:"default:'in_progress';index"` // in_progress, completed
+	StartedAt       time.Time      `json:"started_at" gorm:"index"`
+	FinishedAt      *time.Time     `json:"finished_at"`
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Exercises []WorkoutExerciseLog `json:"exercises,omitempty" gorm:"foreignKey:SessionID"`
+}
+
+// WorkoutExerciseLog is one exercise performed during a session
+type WorkoutExerciseLog struct {
+	ID                   uint           `json:"id" gorm:"primaryKey"`
+	SessionID            uint           `json:"session_id" gorm:"uniqueIndex:idx_workout_exercise_client;not null"`
+	ClientID             string         `json:"client_id" gorm:"uniqueIndex:idx_workout_exercise_client;size:64;not null"`
+	Position             int            `json:"position"`
+	ExerciseID           *uint          `json:"exercise_id" gorm:"index"` // catalog entry, if the exercise comes from the library
+	ExerciseName         string         `json:"exercise_name" gorm:"not null"`
+	PrescriptionPosition int            `json:"prescription_position"` // planned exercise it follows, 0 when unplanned
+	Notes                string         `json:"notes"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	Sets []WorkoutSetLog `json:"sets,omitempty" gorm:"foreignKey:ExerciseLogID"`
+}
+
+// WorkoutSetLog is a single set as performed
+type WorkoutSetLog struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	ExerciseLogID   uint           `json:"exercise_log_id" gorm:"index;not null"`
+	SetNumber       int            `json:"set_number"`
+	Reps            int            `json:"reps"`
+	WeightKg        float64        `json:"weight_kg"`
+	DurationSeconds int            `json:"duration_seconds"`
+	DistanceMeters  float64        `json:"distance_meters"`
+	RPE             float64        `json:"rpe"` // rate of perceived exertion, 1-10
+	IsWarmup        bool           `json:"is_warmup" gorm:"default:false"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
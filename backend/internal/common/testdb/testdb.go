@@ -0,0 +1,50 @@
+// Package testdb connects integration tests to the test database
+package testdb
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+)
+
+// Connect connects to the test database (DB_* settings, database TEST_DB_NAME or
+// fittrackplus_test) and migrates it. Tests are skipped when it cannot be reached
+func Connect(t *testing.T) *config.Config {
+	t.Helper()
+
+	cfg := config.LoadConfig()
+	cfg.DBName = "fittrackplus_test"
+	if name := os.Getenv("TEST_DB_NAME"); name != "" {
+		cfg.DBName = name
+	}
+	cfg.JWTAlgorithm = "HS256"
+	cfg.JWTSecret = "test-secret-key"
+
+	if err := database.Connect(cfg); err != nil {
+		t.Skipf("test database unavailable: %v", err)
+	}
+	return cfg
+}
+
+// CreateUser creates a user with a unique email
+func CreateUser(t *testing.T, role string) *models.User {
+	t.Helper()
+
+	user := &models.User{
+		Email:     fmt.Sprintf("%s-%d@test.example.com", role, time.Now().UnixNano()),
+		Password:  "unused",
+		FirstName: "Test",
+		LastName:  role,
+		Role:      role,
+		IsActive:  true,
+	}
+	if err := database.GetDB().Create(user).Error; err != nil {
+		t.Fatalf("failed to create %s: %v", role, err)
+	}
+	return user
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "time"
+
+// DateOnly returns the UTC calendar date of a time, at midnight UTC
+// Every calendar date in the API (plan schedules, logs, goals, photos) is a UTC date,
+// so dates parsed from YYYY-MM-DD and dates derived from timestamps compare equal
+// whatever the server time zone
+func DateOnly(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Today returns the current UTC calendar date
+func Today() time.Time {
+	return DateOnly(time.Now())
+}
@@ -0,0 +1,14 @@
+package utils
+
+import "math"
+
+// Round rounds a value to the given number of decimal places
+func Round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
+
+// RoundTenth rounds a value to one decimal place
+func RoundTenth(value float64) float64 {
+	return Round(value, 1)
+}
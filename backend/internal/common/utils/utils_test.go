@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateOnlyUsesUTCDate(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	// 08:00 on June 2nd in Tokyo is still June 1st in UTC
+	got := DateOnly(time.Date(2026, 6, 2, 8, 0, 0, 0, tokyo))
+	want := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) || got.Location() != time.UTC {
+		t.Fatalf("DateOnly = %v, want %v", got, want)
+	}
+
+	parsed, _ := time.Parse("2006-01-02", "2026-06-01")
+	if !DateOnly(parsed).Equal(want) {
+		t.Fatalf("parsed date %v should be unchanged", parsed)
+	}
+}
+
+func TestRoundTenth(t *testing.T) {
+	if got := RoundTenth(72.345); got != 72.3 {
+		t.Fatalf("RoundTenth = %v, want 72.3", got)
+	}
+	if got := RoundTenth(-1.25); got != -1.3 {
+		t.Fatalf("RoundTenth = %v, want -1.3", got)
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		value    float64
+		decimals int
+		want     float64
+	}{
+		{1234.56, 0, 1235},
+		{72.345, 1, 72.3},
+		{0.4567, 2, 0.46},
+		{-1.255, 2, -1.25},
+	}
+	for _, tt := range tests {
+		if got := Round(tt.value, tt.decimals); got != tt.want {
+			t.Errorf("Round(%v, %d) = %v, want %v", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+}
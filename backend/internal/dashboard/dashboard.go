@@ -2,11 +2,17 @@ package dashboard
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"fittrackplus/internal/common/config"
 	"fittrackplus/internal/common/database"
 	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/goal"
+	"fittrackplus/internal/notification"
+	"fittrackplus/internal/plan"
+	"fittrackplus/internal/progress"
+	"fittrackplus/internal/workout"
 
 	"gorm.io/gorm"
 )
@@ -15,6 +21,10 @@ import (
 type DashboardService struct {
 	db  *gorm.DB
 	cfg *config.Config
+	goalService         *goal.GoalService
+	notificationService *notification.NotificationService
+	planService         *plan.PlanService
+	progressService     *progress.ProgressService
 }
 
 // NewDashboardService creates a new dashboard service
@@ -22,6 +32,10 @@ func NewDashboardService(cfg *config.Config) *DashboardService {
 	return &DashboardService{
 		db:  database.GetDB(),
 		cfg: cfg,
+		goalService:         goal.NewGoalService(cfg),
+		notificationService: notification.NewNotificationService(cfg),
+		planService:         plan.NewPlanService(cfg),
+		progressService:     progress.NewProgressService(cfg),
 	}
 }
 
@@ -60,7 +74,7 @@ type DashboardStats struct {
 // RecentActivity represents recent user activities
 type RecentActivity struct {
 	ID          uint      `json:"id"`
-	Type        string    `json:"type"` // "login", "profile_update", "plan_assigned", etc.
+	Type        string    `json:"type"` // "login", "profile_update", "plan_assigned", "personal_record", etc.
 	Description string    `json:"description"`
 	CreatedAt   time.Time `json:"created_at"`
 }
@@ -77,10 +91,12 @@ type QuickAction struct {
 
 // Notification represents user notifications
 type Notification struct {
-	ID          uint      `json:"id"`
+	ID          uint      `json:"id"` // 0 for generated notifications that aren't stored
 	Type        string    `json:"type"` // "info", "warning", "success", "error"
+	Category    string    `json:"category,omitempty"` // "security", "plan", "progress", ...
 	Title       string    `json:"title"`
 	Message     string    `json:"message"`
+	Link        string    `json:"link,omitempty"`
 	IsRead      bool      `json:"is_read"`
 	CreatedAt   time.Time `json:"created_at"`
 }
@@ -113,7 +129,7 @@ type AdminDashboardData struct {
 type PlanSummary struct {
 	ID          uint      `json:"id"`
 	Name        string    `json:"name"`
-	Type        string    `json:"type"` // "workout", "diet", "mixed"
+	Type        string    `json:"type"` // "workout", "diet", "physio"
 	Status      string    `json:"status"` // "active", "completed", "paused"
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
@@ -122,12 +138,18 @@ type PlanSummary struct {
 
 // ProgressSummary represents user progress
 type ProgressSummary struct {
-	CurrentWeight    float64 `json:"current_weight"`
-	TargetWeight     float64 `json:"target_weight"`
-	WeightLost       float64 `json:"weight_lost"`
-	BodyFatReduction float64 `json:"body_fat_reduction"`
-	MuscleGain       float64 `json:"muscle_gain"`
-	OverallProgress  float64 `json:"overall_progress"`
+	HasData          bool       `json:"has_data"`               // false until the member logs a weight
+	GoalDirection    string     `json:"goal_direction"`         // loss, gain, maintain
+	StartWeight      float64    `json:"start_weight"`
+	CurrentWeight    float64    `json:"current_weight"`
+	TargetWeight     float64    `json:"target_weight"`
+	WeightLost       float64    `json:"weight_lost"`            // negative when weight was gained
+	BodyFatReduction float64    `json:"body_fat_reduction"`
+	MuscleGain       float64    `json:"muscle_gain"`
+	OverallProgress  float64    `json:"overall_progress"`
+	TrendStatus      string     `json:"trend_status,omitempty"` // on_track, ahead, behind, reached
+	WeeklyRate       float64    `json:"weekly_rate"`            // kg per week of the smoothed trend
+	ProjectedDate    *time.Time `json:"projected_date,omitempty"`
 }
 
 // SessionInfo represents session information
@@ -145,11 +167,15 @@ type SessionInfo struct {
 // GoalInfo represents user goals
 type GoalInfo struct {
 	ID          uint    `json:"id"`
+	Type        string  `json:"type"`
 	Title       string  `json:"title"`
 	Description string  `json:"description"`
+	Unit        string  `json:"unit"`
+	Baseline    float64 `json:"baseline"`
 	Target      float64 `json:"target"`
 	Current     float64 `json:"current"`
 	Progress    float64 `json:"progress"`
+	Status      string  `json:"status"`
 	Deadline    time.Time `json:"deadline"`
 }
 
@@ -400,6 +426,24 @@ func (s *DashboardService) getRecentActivity(userID uint) ([]RecentActivity, err
 		},
 	}
 
+	// Personal records improved in the last 30 days
+	var records []models.PersonalRecord
+	s.db.Where("user_id = ? AND previous_value IS NOT NULL AND achieved_at >= ?", userID, time.Now().AddDate(0, 0, -30)).
+		Order("achieved_at DESC").Limit(5).
+		Find(&records)
+	for i := range records {
+		activities = append(activities, RecentActivity{
+			ID:          records[i].ID,
+			Type:        "personal_record",
+			Description: "New PR! " + workout.DescribeRecord(&records[i]),
+			CreatedAt:   records[i].AchievedAt,
+		})
+	}
+
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].CreatedAt.After(activities[j].CreatedAt)
+	})
+
 	return activities, nil
 }
 
@@ -531,7 +575,6 @@ func (s *DashboardService) getNotifications(userID uint) ([]Notification, error)
 		if err == gorm.ErrRecordNotFound {
 			// No profile found - show profile completion notification
 			notifications = append(notifications, Notification{
-				ID:        1,
 				Type:      "warning",
 				Title:     "Profile Incomplete",
 				Message:   "Please complete your profile to access all features.",
@@ -542,7 +585,6 @@ func (s *DashboardService) getNotifications(userID uint) ([]Notification, error)
 	} else if !userProfile.IsProfileComplete {
 		// Profile exists but incomplete
 		notifications = append(notifications, Notification{
-			ID:        1,
 			Type:      "warning",
 			Title:     "Profile Incomplete",
 			Message:   "Your profile is incomplete. Complete it to get personalized plans.",
@@ -552,7 +594,6 @@ func (s *DashboardService) getNotifications(userID uint) ([]Notification, error)
 	} else {
 		// Profile complete - show welcome message
 		notifications = append(notifications, Notification{
-			ID:        1,
 			Type:      "success",
 			Title:     "Welcome!",
 			Message:   "Your profile is complete. You can now access all features.",
@@ -561,6 +602,24 @@ func (s *DashboardService) getNotifications(userID uint) ([]Notification, error)
 		})
 	}
 
+	// Add stored notifications (security alerts, plan updates, achievements...)
+	stored, err := s.notificationService.GetNotifications(userID, false, 20)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range stored {
+		notifications = append(notifications, Notification{
+			ID:        n.ID,
+			Type:      n.Type,
+			Category:  n.Category,
+			Title:     n.Title,
+			Message:   n.Message,
+			Link:      n.Link,
+			IsRead:    n.IsRead,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+
 	return notifications, nil
 }
 
@@ -603,7 +662,7 @@ func (s *DashboardService) getMemberDashboardData(userID uint) (*MemberDashboard
 		return nil, err
 	}
 
-	// Get progress summary (mock for now)
+	// Get progress summary
 	progressSummary, err := s.getProgressSummary(userID)
 	if err != nil {
 		return nil, err
@@ -692,30 +751,54 @@ func (s *DashboardService) getAdminDashboardData() (*AdminDashboardData, error)
 
 // Helper methods for member dashboard
 func (s *DashboardService) getCurrentPlan(userID uint) (*PlanSummary, error) {
-	// For now, return mock data
-	// In a real app, you'd query the user_plans table
+	userPlan, err := s.planService.GetCurrentPlan(userID)
+	if err != nil || userPlan == nil {
+		return nil, err
+	}
+
+	planType := userPlan.Plan.PlanType
+	if planType == "fitness" {
+		planType = "workout"
+	}
+
 	return &PlanSummary{
-		ID:        1,
-		Name:      "Beginner Fitness Plan",
-		Type:      "workout",
-		Status:    "active",
-		StartDate: time.Now().AddDate(0, 0, -7),
-		EndDate:   time.Now().AddDate(0, 1, 0),
-		Progress:  25.0,
+		ID:        userPlan.ID,
+		Name:      userPlan.Plan.Name,
+		Type:      planType,
+		Status:    userPlan.Status,
+		StartDate: userPlan.AssignedAt,
+		EndDate:   userPlan.EndsAt,
+		Progress:  userPlan.Progress,
 	}, nil
 }
 
 func (s *DashboardService) getProgressSummary(userID uint) (*ProgressSummary, error) {
-	// For now, return mock data
-	// In a real app, you'd calculate from progress_logs
-	return &ProgressSummary{
-		CurrentWeight:    75.0,
-		TargetWeight:     70.0,
-		WeightLost:       2.5,
-		BodyFatReduction: 1.2,
-		MuscleGain:       1.0,
-		OverallProgress:  25.0,
-	}, nil
+	summary, err := s.progressService.GetSummary(userID, userID, "member")
+	if err != nil {
+		return nil, err
+	}
+	if !summary.HasData {
+		return &ProgressSummary{TargetWeight: summary.TargetWeight}, nil
+	}
+
+	response := &ProgressSummary{
+		HasData:          true,
+		GoalDirection:    summary.GoalDirection,
+		StartWeight:      summary.StartWeight,
+		CurrentWeight:    summary.CurrentWeight,
+		TargetWeight:     summary.TargetWeight,
+		WeightLost:       -summary.WeightChange,
+		BodyFatReduction: -summary.BodyFatChange,
+		MuscleGain:       summary.MuscleMassChange,
+		OverallProgress:  summary.OverallProgress,
+	}
+
+	if trend, err := s.progressService.GetTrend(userID, userID, "member", 1); err == nil {
+		response.TrendStatus = trend.Status
+		response.WeeklyRate = trend.WeeklyRate
+		response.ProjectedDate = trend.ProjectedDate
+	}
+	return response, nil
 }
 
 func (s *DashboardService) getUpcomingSessions(userID uint) ([]SessionInfo, error) {
@@ -735,19 +818,28 @@ func (s *DashboardService) getUpcomingSessions(userID uint) ([]SessionInfo, erro
 }
 
 func (s *DashboardService) getGoals(userID uint) ([]GoalInfo, error) {
-	// For now, return mock data
-	// In a real app, you'd query the goals table
-	return []GoalInfo{
-		{
-			ID:          1,
-			Title:       "Weight Loss",
-			Description: "Lose 5kg in 3 months",
-			Target:      70.0,
-			Current:     75.0,
-			Progress:    50.0,
-			Deadline:    time.Now().AddDate(0, 2, 0),
-		},
-	}, nil
+	goals, err := s.goalService.GetActiveGoals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]GoalInfo, 0, len(goals))
+	for _, g := range goals {
+		infos = append(infos, GoalInfo{
+			ID:          g.ID,
+			Type:        g.Type,
+			Title:       g.Title,
+			Description: g.Description,
+			Unit:        g.Unit,
+			Baseline:    g.BaselineValue,
+			Target:      g.TargetValue,
+			Current:     g.CurrentValue,
+			Progress:    g.Progress,
+			Status:      g.Status,
+			Deadline:    g.Deadline,
+		})
+	}
+	return infos, nil
 }
 
 // Helper methods for trainer dashboard
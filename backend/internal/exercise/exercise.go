@@ -0,0 +1,435 @@
+package exercise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/notification"
+
+	"gorm.io/gorm"
+)
+
+// ExerciseService handles the shared exercise catalog
+type ExerciseService struct {
+	db                  *gorm.DB
+	cfg                 *config.Config
+	notificationService *notification.NotificationService
+}
+
+// NewExerciseService creates a new exercise service
+func NewExerciseService(cfg *config.Config) *ExerciseService {
+	return &ExerciseService{
+		db:                  database.GetDB(),
+		cfg:                 cfg,
+		notificationService: notification.NewNotificationService(cfg),
+	}
+}
+
+// ExerciseRequest represents an exercise creation/update request
+type ExerciseRequest struct {
+	Name              string   `json:"name" binding:"required"`
+	Category          string   `json:"category" binding:"required"` // strength, cardio, mobility, physio
+	PrimaryMuscles    []string `json:"primary_muscles" binding:"required,min=1"`
+	SecondaryMuscles  []string `json:"secondary_muscles"`
+	Equipment         []string `json:"equipment"`
+	Difficulty        string   `json:"difficulty" binding:"required"` // beginner, intermediate, advanced
+	Instructions      string   `json:"instructions"`
+	MediaURLs         []string `json:"media_urls"`
+	Contraindications []string `json:"contraindications"`
+	Aliases           []string `json:"aliases"`
+}
+
+// ExerciseFilter holds the catalog search parameters
+type ExerciseFilter struct {
+	Query      string // matches name or aliases
+	Muscle     string // primary or secondary muscle group
+	Equipment  string
+	Difficulty string
+	Category   string
+	Status     string // approved by default
+}
+
+// ExerciseResponse represents a catalog entry
+type ExerciseResponse struct {
+	ID                uint       `json:"id"`
+	Name              string     `json:"name"`
+	Category          string     `json:"category"`
+	PrimaryMuscles    []string   `json:"primary_muscles"`
+	SecondaryMuscles  []string   `json:"secondary_muscles"`
+	Equipment         []string   `json:"equipment"`
+	Difficulty        string     `json:"difficulty"`
+	Instructions      string     `json:"instructions"`
+	MediaURLs         []string   `json:"media_urls"`
+	Contraindications []string   `json:"contraindications"`
+	Aliases           []string   `json:"aliases"`
+	Status            string     `json:"status"`
+	IsCustom          bool       `json:"is_custom"`
+	CreatedBy         *uint      `json:"created_by,omitempty"`
+	ReviewNote        string     `json:"review_note,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// MuscleGroups is the controlled vocabulary for muscle tags
+var MuscleGroups = []string{
+	"chest", "upper_back", "lats", "traps", "lower_back", "shoulders", "rear_delts",
+	"biceps", "triceps", "forearms", "abs", "obliques", "glutes", "quadriceps",
+	"hamstrings", "calves", "hip_flexors", "adductors", "abductors", "neck",
+}
+
+// Difficulties lists the allowed difficulty levels
+var Difficulties = []string{"beginner", "intermediate", "advanced"}
+
+// Categories lists the allowed exercise categories
+var Categories = []string{"strength", "cardio", "mobility", "physio"}
+
+// SearchExercises searches the catalog
+// Trainers also see their own pending submissions when includeOwnPendingFor is set
+func (s *ExerciseService) SearchExercises(filter ExerciseFilter, includeOwnPendingFor *uint) ([]ExerciseResponse, error) {
+	var exercises []models.Exercise
+	query := s.db.Model(&models.Exercise{})
+
+	status := filter.Status
+	if status == "" {
+		status = "approved"
+	}
+	if includeOwnPendingFor != nil && status == "approved" {
+		query = query.Where("status = ? OR (status = ? AND created_by = ?)", "approved", "pending", *includeOwnPendingFor)
+	} else {
+		query = query.Where("status = ?", status)
+	}
+
+	if q := strings.ToLower(strings.TrimSpace(filter.Query)); q != "" {
+		like := "%" + q + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(aliases) LIKE ?", like, like)
+	}
+	if filter.Muscle != "" {
+		// Muscle tags are stored as JSON arrays, so match the quoted tag
+		tag := `%"` + strings.ToLower(filter.Muscle) + `"%`
+		query = query.Where("primary_muscles LIKE ? OR secondary_muscles LIKE ?", tag, tag)
+	}
+	if filter.Equipment != "" {
+		query = query.Where("LOWER(equipment) LIKE ?", `%"`+strings.ToLower(filter.Equipment)+`"%`)
+	}
+	if filter.Difficulty != "" {
+		query = query.Where("difficulty = ?", filter.Difficulty)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+
+	if err := query.Order("name").Find(&exercises).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []ExerciseResponse{}
+	for _, exercise := range exercises {
+		responses = append(responses, *buildExerciseResponse(&exercise))
+	}
+	return responses, nil
+}
+
+// GetExercise retrieves a catalog entry by ID
+func (s *ExerciseService) GetExercise(exerciseID uint) (*ExerciseResponse, error) {
+	var exercise models.Exercise
+	if err := s.db.First(&exercise, exerciseID).Error; err != nil {
+		return nil, errors.New("exercise not found")
+	}
+	return buildExerciseResponse(&exercise), nil
+}
+
+// CreateExercise adds an exercise to the catalog
+// Admin entries are approved immediately; trainer contributions wait for review.
+// Creating a deleted exercise again restores its entry, so old logs keep pointing at it
+func (s *ExerciseService) CreateExercise(req *ExerciseRequest, createdBy uint, creatorRole string) (*ExerciseResponse, error) {
+	if err := validateExerciseRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Deleted entries keep their name, so creating one again restores it
+	var deleted models.Exercise
+	s.db.Unscoped().Where("LOWER(name) = ? AND deleted_at IS NOT NULL", strings.ToLower(strings.TrimSpace(req.Name))).First(&deleted)
+
+	if err := s.ensureUniqueName(req.Name, deleted.ID); err != nil {
+		return nil, err
+	}
+
+	exercise := models.Exercise{ID: deleted.ID, IsCustom: true, CreatedBy: &createdBy, CreatedAt: deleted.CreatedAt}
+	applyExerciseRequest(&exercise, req)
+
+	if creatorRole == "admin" {
+		now := time.Now()
+		exercise.Status = "approved"
+		exercise.ReviewedBy = &createdBy
+		exercise.ReviewedAt = &now
+	} else {
+		exercise.Status = "pending"
+	}
+
+	if err := s.db.Unscoped().Save(&exercise).Error; err != nil {
+		return nil, err
+	}
+
+	return buildExerciseResponse(&exercise), nil
+}
+
+// UpdateExercise edits a catalog entry
+// Trainers may only edit their own submissions while they are pending or rejected
+func (s *ExerciseService) UpdateExercise(exerciseID uint, req *ExerciseRequest, userID uint, userRole string) (*ExerciseResponse, error) {
+	var exercise models.Exercise
+	if err := s.db.First(&exercise, exerciseID).Error; err != nil {
+		return nil, errors.New("exercise not found")
+	}
+
+	if userRole != "admin" {
+		if exercise.CreatedBy == nil || *exercise.CreatedBy != userID || exercise.Status == "approved" {
+			return nil, errors.New("you can only edit your own exercises that are awaiting review")
+		}
+	}
+
+	if err := validateExerciseRequest(req); err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueName(req.Name, exercise.ID); err != nil {
+		return nil, err
+	}
+
+	applyExerciseRequest(&exercise, req)
+
+	// A rejected submission goes back into the review queue once edited
+	if userRole != "admin" && exercise.Status == "rejected" {
+		exercise.Status = "pending"
+	}
+
+	if err := s.db.Save(&exercise).Error; err != nil {
+		return nil, err
+	}
+
+	return buildExerciseResponse(&exercise), nil
+}
+
+// ReviewExercise approves or rejects a pending trainer contribution and tells the author
+func (s *ExerciseService) ReviewExercise(exerciseID uint, approve bool, note string, reviewerID uint) (*ExerciseResponse, error) {
+	var exercise models.Exercise
+	if err := s.db.First(&exercise, exerciseID).Error; err != nil {
+		return nil, errors.New("exercise not found")
+	}
+	if exercise.Status != "pending" {
+		return nil, errors.New("only pending exercises can be reviewed")
+	}
+
+	now := time.Now()
+	exercise.ReviewedBy = &reviewerID
+	exercise.ReviewedAt = &now
+	exercise.ReviewNote = note
+	if approve {
+		exercise.Status = "approved"
+	} else {
+		exercise.Status = "rejected"
+	}
+
+	if err := s.db.Save(&exercise).Error; err != nil {
+		return nil, err
+	}
+
+	if exercise.CreatedBy != nil {
+		msg := notification.Message{
+			Type:     "success",
+			Category: "exercise",
+			Title:    "Exercise approved",
+			Message:  fmt.Sprintf("%q is now part of the exercise library.", exercise.Name),
+			Link:     fmt.Sprintf("/exercises/%d", exercise.ID),
+		}
+		if !approve {
+			msg.Type = "warning"
+			msg.Title = "Exercise not approved"
+			msg.Message = fmt.Sprintf("%q was not added to the exercise library. %s", exercise.Name, note)
+		}
+		s.notificationService.Notify(*exercise.CreatedBy, msg)
+	}
+
+	return buildExerciseResponse(&exercise), nil
+}
+
+// DeleteExercise removes a catalog entry (existing plan prescriptions keep their copied name)
+func (s *ExerciseService) DeleteExercise(exerciseID uint) error {
+	result := s.db.Delete(&models.Exercise{}, exerciseID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("exercise not found")
+	}
+	return nil
+}
+
+// SeedDefaultExercises fills an empty catalog with common exercises
+func (s *ExerciseService) SeedDefaultExercises() error {
+	var count int64
+	if err := s.db.Model(&models.Exercise{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for i := range defaultExercises {
+		req := defaultExercises[i]
+		exercise := models.Exercise{Status: "approved"}
+		applyExerciseRequest(&exercise, &req)
+		if err := s.db.Create(&exercise).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseMuscles returns the primary and secondary muscle tags of a catalog entry
+func ParseMuscles(exercise *models.Exercise) (primary, secondary []string) {
+	return decodeList(exercise.PrimaryMuscles), decodeList(exercise.SecondaryMuscles)
+}
+
+// ensureUniqueName checks the name against every catalog entry, including deleted ones,
+// which still hold the name in the unique index
+func (s *ExerciseService) ensureUniqueName(name string, exceptID uint) error {
+	var existing models.Exercise
+	err := s.db.Unscoped().
+		Where("LOWER(name) = ? AND id <> ?", strings.ToLower(strings.TrimSpace(name)), exceptID).
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.DeletedAt.Valid {
+		return errors.New("a deleted exercise with this name already exists: create it again to restore it")
+	}
+	return errors.New("an exercise with this name already exists")
+}
+
+// Helper methods
+func validateExerciseRequest(req *ExerciseRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	if !contains(Categories, req.Category) {
+		return errors.New("invalid category: must be one of strength, cardio, mobility, physio")
+	}
+	if !contains(Difficulties, req.Difficulty) {
+		return errors.New("invalid difficulty: must be one of beginner, intermediate, advanced")
+	}
+	for _, muscle := range append(append([]string{}, req.PrimaryMuscles...), req.SecondaryMuscles...) {
+		if !contains(MuscleGroups, muscle) {
+			return fmt.Errorf("invalid muscle group %q: must be one of %s", muscle, strings.Join(MuscleGroups, ", "))
+		}
+	}
+	for _, url := range req.MediaURLs {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "/uploads/") {
+			return fmt.Errorf("invalid media URL %q", url)
+		}
+	}
+	return nil
+}
+
+func applyExerciseRequest(exercise *models.Exercise, req *ExerciseRequest) {
+	exercise.Name = strings.TrimSpace(req.Name)
+	exercise.Category = req.Category
+	exercise.PrimaryMuscles = encodeList(req.PrimaryMuscles)
+	exercise.SecondaryMuscles = encodeList(req.SecondaryMuscles)
+	exercise.Equipment = encodeList(lowerAll(req.Equipment))
+	exercise.Difficulty = req.Difficulty
+	exercise.Instructions = req.Instructions
+	exercise.MediaURLs = encodeList(req.MediaURLs)
+	exercise.Contraindications = encodeList(req.Contraindications)
+	exercise.Aliases = encodeList(req.Aliases)
+}
+
+func buildExerciseResponse(exercise *models.Exercise) *ExerciseResponse {
+	return &ExerciseResponse{
+		ID:                exercise.ID,
+		Name:              exercise.Name,
+		Category:          exercise.Category,
+		PrimaryMuscles:    decodeList(exercise.PrimaryMuscles),
+		SecondaryMuscles:  decodeList(exercise.SecondaryMuscles),
+		Equipment:         decodeList(exercise.Equipment),
+		Difficulty:        exercise.Difficulty,
+		Instructions:      exercise.Instructions,
+		MediaURLs:         decodeList(exercise.MediaURLs),
+		Contraindications: decodeList(exercise.Contraindications),
+		Aliases:           decodeList(exercise.Aliases),
+		Status:            exercise.Status,
+		IsCustom:          exercise.IsCustom,
+		CreatedBy:         exercise.CreatedBy,
+		ReviewNote:        exercise.ReviewNote,
+		ReviewedAt:        exercise.ReviewedAt,
+		CreatedAt:         exercise.CreatedAt,
+	}
+}
+
+func encodeList(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+func decodeList(value string) []string {
+	values := []string{}
+	if value != "" {
+		json.Unmarshal([]byte(value), &values)
+	}
+	return values
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, 0, len(values))
+	for _, value := range values {
+		lowered = append(lowered, strings.ToLower(strings.TrimSpace(value)))
+	}
+	return lowered
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultExercises seeds the catalog on first start
+var defaultExercises = []ExerciseRequest{
+	{Name: "Barbell Back Squat", Category: "strength", PrimaryMuscles: []string{"quadriceps", "glutes"}, SecondaryMuscles: []string{"hamstrings", "lower_back", "abs"}, Equipment: []string{"barbell", "squat rack"}, Difficulty: "intermediate", Instructions: "Bar on upper back, brace, sit down between the hips until thighs are at least parallel, drive up through the mid-foot.", Contraindications: []string{"acute knee injury", "acute lower back pain"}, Aliases: []string{"Back Squat", "Squat"}},
+	{Name: "Goblet Squat", Category: "strength", PrimaryMuscles: []string{"quadriceps", "glutes"}, SecondaryMuscles: []string{"abs"}, Equipment: []string{"dumbbell", "kettlebell"}, Difficulty: "beginner", Instructions: "Hold a dumbbell or kettlebell at the chest and squat with an upright torso.", Contraindications: []string{"acute knee injury"}},
+	{Name: "Conventional Deadlift", Category: "strength", PrimaryMuscles: []string{"hamstrings", "glutes", "lower_back"}, SecondaryMuscles: []string{"traps", "forearms", "quadriceps"}, Equipment: []string{"barbell"}, Difficulty: "intermediate", Instructions: "Hinge at the hips, grip the bar outside the knees, keep a neutral spine and stand up by pushing the floor away.", Contraindications: []string{"acute lower back pain", "disc herniation"}, Aliases: []string{"Deadlift"}},
+	{Name: "Romanian Deadlift", Category: "strength", PrimaryMuscles: []string{"hamstrings", "glutes"}, SecondaryMuscles: []string{"lower_back", "forearms"}, Equipment: []string{"barbell", "dumbbell"}, Difficulty: "intermediate", Instructions: "With soft knees, hinge the hips back until a stretch is felt in the hamstrings, then return to standing.", Contraindications: []string{"acute lower back pain"}, Aliases: []string{"RDL"}},
+	{Name: "Barbell Bench Press", Category: "strength", PrimaryMuscles: []string{"chest"}, SecondaryMuscles: []string{"triceps", "shoulders"}, Equipment: []string{"barbell", "bench"}, Difficulty: "intermediate", Instructions: "Lower the bar to the mid-chest under control and press back up, keeping shoulder blades retracted.", Contraindications: []string{"shoulder impingement"}, Aliases: []string{"Bench Press", "Bench"}},
+	{Name: "Dumbbell Bench Press", Category: "strength", PrimaryMuscles: []string{"chest"}, SecondaryMuscles: []string{"triceps", "shoulders"}, Equipment: []string{"dumbbell", "bench"}, Difficulty: "beginner", Instructions: "Press the dumbbells from chest level to lockout, lowering them under control."},
+	{Name: "Push-up", Category: "strength", PrimaryMuscles: []string{"chest"}, SecondaryMuscles: []string{"triceps", "shoulders", "abs"}, Equipment: []string{"bodyweight"}, Difficulty: "beginner", Instructions: "Keep a straight line from head to heels and lower the chest to just above the floor.", Contraindications: []string{"wrist injury"}, Aliases: []string{"Press-up"}},
+	{Name: "Overhead Press", Category: "strength", PrimaryMuscles: []string{"shoulders"}, SecondaryMuscles: []string{"triceps", "traps", "abs"}, Equipment: []string{"barbell"}, Difficulty: "intermediate", Instructions: "Press the bar from the front of the shoulders to overhead lockout while bracing the core.", Contraindications: []string{"shoulder impingement"}, Aliases: []string{"Military Press", "OHP"}},
+	{Name: "Pull-up", Category: "strength", PrimaryMuscles: []string{"lats"}, SecondaryMuscles: []string{"biceps", "upper_back", "forearms"}, Equipment: []string{"pull-up bar"}, Difficulty: "intermediate", Instructions: "From a dead hang, pull until the chin clears the bar and lower under control.", Aliases: []string{"Chin-up"}},
+	{Name: "Lat Pulldown", Category: "strength", PrimaryMuscles: []string{"lats"}, SecondaryMuscles: []string{"biceps", "upper_back"}, Equipment: []string{"cable machine"}, Difficulty: "beginner", Instructions: "Pull the bar to the upper chest, driving the elbows down and back."},
+	{Name: "Barbell Row", Category: "strength", PrimaryMuscles: []string{"upper_back", "lats"}, SecondaryMuscles: []string{"biceps", "rear_delts", "lower_back"}, Equipment: []string{"barbell"}, Difficulty: "intermediate", Instructions: "Hinge forward with a flat back and row the bar to the lower ribs.", Contraindications: []string{"acute lower back pain"}, Aliases: []string{"Bent-over Row"}},
+	{Name: "Seated Cable Row", Category: "strength", PrimaryMuscles: []string{"upper_back"}, SecondaryMuscles: []string{"lats", "biceps", "rear_delts"}, Equipment: []string{"cable machine"}, Difficulty: "beginner", Instructions: "Sit tall and row the handle to the stomach, squeezing the shoulder blades together."},
+	{Name: "Walking Lunge", Category: "strength", PrimaryMuscles: []string{"quadriceps", "glutes"}, SecondaryMuscles: []string{"hamstrings", "adductors"}, Equipment: []string{"bodyweight", "dumbbell"}, Difficulty: "beginner", Instructions: "Step forward into a lunge, lower the back knee towards the floor and step through."},
+	{Name: "Hip Thrust", Category: "strength", PrimaryMuscles: []string{"glutes"}, SecondaryMuscles: []string{"hamstrings"}, Equipment: []string{"barbell", "bench"}, Difficulty: "beginner", Instructions: "With upper back on a bench, drive the hips up until the body is straight from shoulders to knees."},
+	{Name: "Biceps Curl", Category: "strength", PrimaryMuscles: []string{"biceps"}, SecondaryMuscles: []string{"forearms"}, Equipment: []string{"dumbbell", "barbell"}, Difficulty: "beginner", Instructions: "Curl the weight without swinging, keeping elbows by the sides."},
+	{Name: "Triceps Pushdown", Category: "strength", PrimaryMuscles: []string{"triceps"}, Equipment: []string{"cable machine"}, Difficulty: "beginner", Instructions: "Extend the elbows fully while keeping the upper arms still."},
+	{Name: "Standing Calf Raise", Category: "strength", PrimaryMuscles: []string{"calves"}, Equipment: []string{"bodyweight", "machine"}, Difficulty: "beginner", Instructions: "Rise onto the toes, pause, and lower the heels below the step."},
+	{Name: "Plank", Category: "strength", PrimaryMuscles: []string{"abs"}, SecondaryMuscles: []string{"obliques", "shoulders"}, Equipment: []string{"bodyweight"}, Difficulty: "beginner", Instructions: "Hold a straight line from head to heels on forearms and toes."},
+	{Name: "Face Pull", Category: "strength", PrimaryMuscles: []string{"rear_delts"}, SecondaryMuscles: []string{"upper_back", "traps"}, Equipment: []string{"cable machine"}, Difficulty: "beginner", Instructions: "Pull the rope towards the face with elbows high, rotating the hands apart."},
+	{Name: "Running", Category: "cardio", PrimaryMuscles: []string{"quadriceps", "calves"}, SecondaryMuscles: []string{"hamstrings", "glutes"}, Equipment: []string{"treadmill", "none"}, Difficulty: "beginner", Instructions: "Run at the prescribed pace or heart-rate zone.", Contraindications: []string{"acute ankle or knee injury"}, Aliases: []string{"Jogging", "Run"}},
+	{Name: "Rowing Machine", Category: "cardio", PrimaryMuscles: []string{"upper_back", "quadriceps"}, SecondaryMuscles: []string{"lats", "glutes", "biceps"}, Equipment: []string{"rowing machine"}, Difficulty: "beginner", Instructions: "Drive with the legs, then lean back and pull the handle to the lower ribs.", Aliases: []string{"Erg", "Rower"}},
+	{Name: "Glute Bridge", Category: "physio", PrimaryMuscles: []string{"glutes"}, SecondaryMuscles: []string{"hamstrings", "lower_back"}, Equipment: []string{"bodyweight"}, Difficulty: "beginner", Instructions: "Lying on your back with knees bent, lift the hips until the body is straight from shoulders to knees."},
+	{Name: "Cat-Cow Stretch", Category: "mobility", PrimaryMuscles: []string{"lower_back"}, SecondaryMuscles: []string{"abs"}, Equipment: []string{"bodyweight"}, Difficulty: "beginner", Instructions: "On hands and knees, alternate between arching and rounding the spine slowly."},
+	{Name: "Band Pull-apart", Category: "physio", PrimaryMuscles: []string{"rear_delts"}, SecondaryMuscles: []string{"upper_back"}, Equipment: []string{"resistance band"}, Difficulty: "beginner", Instructions: "Hold a band at shoulder height and pull it apart until it touches the chest."},
+}
@@ -0,0 +1,54 @@
+package exercise
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/testdb"
+)
+
+func TestRecreateDeletedExercise(t *testing.T) {
+	cfg := testdb.Connect(t)
+	service := NewExerciseService(cfg)
+	admin := testdb.CreateUser(t, "admin")
+
+	request := func(name string) *ExerciseRequest {
+		return &ExerciseRequest{Name: name, Category: "strength", PrimaryMuscles: []string{"quadriceps"}, Difficulty: "beginner"}
+	}
+	name := fmt.Sprintf("Test Split Squat %d", time.Now().UnixNano())
+
+	created, err := service.CreateExercise(request(name), admin.ID, "admin")
+	if err != nil {
+		t.Fatalf("failed to create exercise: %v", err)
+	}
+	if err := service.DeleteExercise(created.ID); err != nil {
+		t.Fatalf("failed to delete exercise: %v", err)
+	}
+
+	// Renaming another exercise to the deleted name is a conflict, not a database error
+	other, err := service.CreateExercise(request(name+" Other"), admin.ID, "admin")
+	if err != nil {
+		t.Fatalf("failed to create exercise: %v", err)
+	}
+	if _, err := service.UpdateExercise(other.ID, request(name), admin.ID, "admin"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected a name conflict, got %v", err)
+	}
+
+	// Creating it again restores the deleted entry
+	restored, err := service.CreateExercise(request(strings.ToLower(name)), admin.ID, "admin")
+	if err != nil {
+		t.Fatalf("failed to recreate deleted exercise: %v", err)
+	}
+	if restored.ID != created.ID || restored.Status != "approved" {
+		t.Fatalf("expected exercise %d to be restored and approved, got %d (%s)", created.ID, restored.ID, restored.Status)
+	}
+	if _, err := service.GetExercise(created.ID); err != nil {
+		t.Fatalf("restored exercise is not visible: %v", err)
+	}
+
+	if _, err := service.CreateExercise(request(name), admin.ID, "admin"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected a name conflict, got %v", err)
+	}
+}
@@ -0,0 +1,457 @@
+package exercise
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fittrackplus/internal/auth"
+	"fittrackplus/internal/common/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExerciseHandler handles exercise catalog HTTP requests
+type ExerciseHandler struct {
+	exerciseService *ExerciseService
+}
+
+// NewExerciseHandler creates a new exercise handler
+func NewExerciseHandler(cfg *config.Config) *ExerciseHandler {
+	return &ExerciseHandler{
+		exerciseService: NewExerciseService(cfg),
+	}
+}
+
+// SearchExercises godoc
+// @Summary Search the exercise library
+// @Description Search approved exercises by name or alias and filter by muscle group, equipment, difficulty and category. Trainers also see their own pending submissions.
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Name or alias search"
+// @Param muscle query string false "Muscle group (primary or secondary)"
+// @Param equipment query string false "Equipment"
+// @Param difficulty query string false "beginner, intermediate or advanced"
+// @Param category query string false "strength, cardio, mobility or physio"
+// @Success 200 {object} map[string]interface{} "Matching exercises"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /exercises [get]
+func (h *ExerciseHandler) SearchExercises(c *gin.Context) {
+	filter := ExerciseFilter{
+		Query:      c.Query("q"),
+		Muscle:     c.Query("muscle"),
+		Equipment:  c.Query("equipment"),
+		Difficulty: c.Query("difficulty"),
+		Category:   c.Query("category"),
+	}
+
+	var ownPending *uint
+	if userRole, _ := auth.GetCurrentUserRole(c); userRole == "trainer" {
+		if userID, exists := auth.GetCurrentUserID(c); exists {
+			ownPending = &userID
+		}
+	}
+
+	exercises, err := h.exerciseService.SearchExercises(filter, ownPending)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to search exercises",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"exercises": exercises,
+		"total":     len(exercises),
+	})
+}
+
+// GetExerciseFilters godoc
+// @Summary Get exercise filter vocabulary
+// @Description Get the allowed muscle groups, difficulties and categories for the exercise library
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Filter vocabulary"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /exercises/filters [get]
+func (h *ExerciseHandler) GetExerciseFilters(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"muscle_groups": MuscleGroups,
+		"difficulties":  Difficulties,
+		"categories":    Categories,
+	})
+}
+
+// GetPendingExercises godoc
+// @Summary Get exercises awaiting review
+// @Description Get trainer-submitted exercises waiting for admin approval (Admin only)
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} ExerciseResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin only"
+// @Router /exercises/pending [get]
+func (h *ExerciseHandler) GetPendingExercises(c *gin.Context) {
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return
+	}
+
+	if userRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins can review exercises",
+		})
+		return
+	}
+
+	exercises, err := h.exerciseService.SearchExercises(ExerciseFilter{Status: "pending"}, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get pending exercises",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, exercises)
+}
+
+// GetExercise godoc
+// @Summary Get an exercise
+// @Description Get a single exercise from the library by ID
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Exercise ID"
+// @Success 200 {object} ExerciseResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Exercise not found"
+// @Router /exercises/{id} [get]
+func (h *ExerciseHandler) GetExercise(c *gin.Context) {
+	exerciseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid exercise ID",
+		})
+		return
+	}
+
+	exercise, err := h.exerciseService.GetExercise(uint(exerciseID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Exercise not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Unapproved entries are only visible to admins and their author
+	if exercise.Status != "approved" {
+		userRole, _ := auth.GetCurrentUserRole(c)
+		userID, _ := auth.GetCurrentUserID(c)
+		if userRole != "admin" && (exercise.CreatedBy == nil || *exercise.CreatedBy != userID) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Exercise not found",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, exercise)
+}
+
+// CreateExercise godoc
+// @Summary Add an exercise to the library
+// @Description Add a custom exercise. Admin entries are published immediately; trainer entries wait for admin approval (Admin/Trainer only)
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param exercise body ExerciseRequest true "Exercise details"
+// @Success 201 {object} ExerciseResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin/Trainer only"
+// @Router /exercises [post]
+func (h *ExerciseHandler) CreateExercise(c *gin.Context) {
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return
+	}
+
+	if userRole != "admin" && userRole != "trainer" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins and trainers can add exercises",
+		})
+		return
+	}
+
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	var req ExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	exercise, err := h.exerciseService.CreateExercise(&req, userID, userRole)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "already exists") {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to create exercise",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, exercise)
+}
+
+// UpdateExercise godoc
+// @Summary Update an exercise
+// @Description Update a library exercise. Admins can edit any entry; trainers can edit their own submissions until approved
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Exercise ID"
+// @Param exercise body ExerciseRequest true "Exercise details"
+// @Success 200 {object} ExerciseResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Exercise not found"
+// @Router /exercises/{id} [put]
+func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return
+	}
+
+	if userRole != "admin" && userRole != "trainer" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins and trainers can edit exercises",
+		})
+		return
+	}
+
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	exerciseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid exercise ID",
+		})
+		return
+	}
+
+	var req ExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	exercise, err := h.exerciseService.UpdateExercise(uint(exerciseID), &req, userID, userRole)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case err.Error() == "exercise not found":
+			status = http.StatusNotFound
+		case strings.HasPrefix(err.Error(), "you can only edit"):
+			status = http.StatusForbidden
+		case strings.Contains(err.Error(), "already exists"):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to update exercise",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, exercise)
+}
+
+// ApproveExercise godoc
+// @Summary Approve a submitted exercise
+// @Description Publish a trainer-submitted exercise to the library (Admin only)
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Exercise ID"
+// @Param review body map[string]interface{} false "Review note (note)"
+// @Success 200 {object} ExerciseResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin only"
+// @Router /exercises/{id}/approve [post]
+func (h *ExerciseHandler) ApproveExercise(c *gin.Context) {
+	h.reviewExercise(c, true)
+}
+
+// RejectExercise godoc
+// @Summary Reject a submitted exercise
+// @Description Reject a trainer-submitted exercise with a note for the author (Admin only)
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Exercise ID"
+// @Param review body map[string]interface{} true "Review note (note)"
+// @Success 200 {object} ExerciseResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin only"
+// @Router /exercises/{id}/reject [post]
+func (h *ExerciseHandler) RejectExercise(c *gin.Context) {
+	h.reviewExercise(c, false)
+}
+
+func (h *ExerciseHandler) reviewExercise(c *gin.Context, approve bool) {
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return
+	}
+
+	if userRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins can review exercises",
+		})
+		return
+	}
+
+	reviewerID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	exerciseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid exercise ID",
+		})
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	c.ShouldBindJSON(&req)
+
+	if !approve && strings.TrimSpace(req.Note) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "A note explaining the rejection is required",
+		})
+		return
+	}
+
+	exercise, err := h.exerciseService.ReviewExercise(uint(exerciseID), approve, req.Note, reviewerID)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err.Error() == "exercise not found" {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to review exercise",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, exercise)
+}
+
+// DeleteExercise godoc
+// @Summary Delete an exercise
+// @Description Remove an exercise from the library. Plans that prescribe it keep the exercise name (Admin only)
+// @Tags Exercises
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Exercise ID"
+// @Success 200 {object} map[string]interface{} "Exercise deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin only"
+// @Failure 404 {object} map[string]interface{} "Exercise not found"
+// @Router /exercises/{id} [delete]
+func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return
+	}
+
+	if userRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins can delete exercises",
+		})
+		return
+	}
+
+	exerciseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid exercise ID",
+		})
+		return
+	}
+
+	if err := h.exerciseService.DeleteExercise(uint(exerciseID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to delete exercise",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exercise deleted successfully",
+	})
+}
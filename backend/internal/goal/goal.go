@@ -0,0 +1,651 @@
+package goal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+	"fittrackplus/internal/notification"
+	"fittrackplus/internal/plan"
+	"fittrackplus/internal/progress"
+	"fittrackplus/internal/workout"
+
+	"gorm.io/gorm"
+)
+
+// Goal types
+const (
+	TypeWeight      = "weight"
+	TypeBodyFat     = "body_fat"
+	TypeMeasurement = "measurement"
+	TypePerformance = "performance"
+	TypeHabit       = "habit"
+)
+
+// Goal statuses
+const (
+	StatusActive   = "active"
+	StatusAchieved = "achieved"
+	StatusMissed   = "missed"
+)
+
+// Habits are the behaviours habit goals count per week
+var Habits = []string{"workouts_per_week", "weigh_ins_per_week"}
+
+// GoalService handles member goals and their automatic evaluation
+type GoalService struct {
+	db                  *gorm.DB
+	cfg                 *config.Config
+	progressService     *progress.ProgressService
+	notificationService *notification.NotificationService
+}
+
+// NewGoalService creates a new goal service
+func NewGoalService(cfg *config.Config) *GoalService {
+	return &GoalService{
+		db:                  database.GetDB(),
+		cfg:                 cfg,
+		progressService:     progress.NewProgressService(cfg),
+		notificationService: notification.NewNotificationService(cfg),
+	}
+}
+
+// CreateGoalRequest represents a goal creation request
+// Which tracking fields are required depends on the type
+type CreateGoalRequest struct {
+	Type        string   `json:"type" binding:"required,oneof=weight body_fat measurement performance habit"`
+	Title       string   `json:"title" binding:"max=200"` // generated from the target when empty
+	Description string   `json:"description" binding:"max=1000"`
+	Target      float64  `json:"target" binding:"required,gt=0"`    // in the goal's unit: kg, %, cm, the record unit or times per week
+	Baseline    *float64 `json:"baseline" binding:"omitempty,gt=0"` // defaults to the current value
+	Deadline    string   `json:"deadline" binding:"required"`       // YYYY-MM-DD
+
+	MeasurementSite string  `json:"measurement_site"` // measurement goals, see GET /progress/measurements/sites
+	MeasurementSide string  `json:"measurement_side"`
+	ExerciseID      *uint   `json:"exercise_id"` // performance goals: catalog exercise, or exercise_name for custom ones
+	ExerciseName    string  `json:"exercise_name"`
+	RecordType      string  `json:"record_type"`     // performance goals: heaviest_weight, most_reps, best_e1rm, fastest_time, longest_distance
+	DistanceMeters  float64 `json:"distance_meters"` // fastest_time goals, e.g. 5000 for a 5k
+	Habit           string  `json:"habit"`           // habit goals: workouts_per_week, weigh_ins_per_week
+}
+
+// UpdateGoalRequest changes an active goal
+type UpdateGoalRequest struct {
+	Title       *string  `json:"title" binding:"omitempty,min=1,max=200"`
+	Description *string  `json:"description" binding:"omitempty,max=1000"`
+	Target      *float64 `json:"target" binding:"omitempty,gt=0"`
+	Deadline    *string  `json:"deadline"` // YYYY-MM-DD
+}
+
+// GoalResponse represents a goal with its latest evaluation
+type GoalResponse struct {
+	models.Goal
+	DaysRemaining int `json:"days_remaining"` // 0 once the deadline has passed
+}
+
+// CreateGoal sets a new goal for a member and evaluates it
+func (s *GoalService) CreateGoal(userID uint, req *CreateGoalRequest) (*GoalResponse, error) {
+	goal, err := buildGoal(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	goal.UserID = userID
+
+	if goal.ExerciseID != nil {
+		var exercise models.Exercise
+		if err := s.db.First(&exercise, *goal.ExerciseID).Error; err != nil {
+			return nil, errors.New("exercise not found")
+		}
+		goal.ExerciseName = exercise.Name
+	}
+
+	if req.Baseline != nil {
+		goal.BaselineValue = *req.Baseline
+	} else if goal.Type != TypeHabit {
+		current, ok, err := s.currentValue(goal, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("no %s has been logged yet, log one or pass a baseline", describeSource(goal))
+		}
+		goal.BaselineValue = current
+	}
+	if goal.Type != TypeHabit && goal.BaselineValue == goal.TargetValue {
+		return nil, errors.New("the target must differ from the baseline")
+	}
+	if goal.Title == "" {
+		goal.Title = describeGoal(goal)
+	}
+	goal.CurrentValue = goal.BaselineValue
+
+	if err := s.db.Create(goal).Error; err != nil {
+		return nil, err
+	}
+	if err := s.evaluate(goal, time.Now()); err != nil {
+		return nil, err
+	}
+	return buildGoalResponse(goal, time.Now()), nil
+}
+
+// GetGoals evaluates a member's active goals and lists their goals, active ones first
+func (s *GoalService) GetGoals(memberID, viewerID uint, viewerRole, status string) ([]GoalResponse, error) {
+	if err := s.canViewGoals(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	if status != "" && status != StatusActive && status != StatusAchieved && status != StatusMissed {
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+	if err := s.EvaluateGoals(memberID); err != nil {
+		return nil, err
+	}
+
+	query := s.db.Where("user_id = ?", memberID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var goals []models.Goal
+	err := query.Order(gorm.Expr("CASE WHEN status = ? THEN 0 ELSE 1 END", StatusActive)).
+		Order("deadline, id").Find(&goals).Error
+	if err != nil {
+		return nil, errors.New("failed to load goals")
+	}
+
+	now := time.Now()
+	responses := []GoalResponse{}
+	for i := range goals {
+		responses = append(responses, *buildGoalResponse(&goals[i], now))
+	}
+	return responses, nil
+}
+
+// GetGoal retrieves a single goal visible to the viewer
+func (s *GoalService) GetGoal(goalID, viewerID uint, viewerRole string) (*GoalResponse, error) {
+	var goal models.Goal
+	if err := s.db.First(&goal, goalID).Error; err != nil {
+		return nil, errors.New("goal not found")
+	}
+	if err := s.canViewGoals(goal.UserID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	if goal.Status == StatusActive {
+		if err := s.evaluate(&goal, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return buildGoalResponse(&goal, time.Now()), nil
+}
+
+// UpdateGoal changes the title, description, target or deadline of an active goal
+func (s *GoalService) UpdateGoal(goalID, userID uint, req *UpdateGoalRequest) (*GoalResponse, error) {
+	goal, err := s.findOwnGoal(goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Status != StatusActive {
+		return nil, errors.New("only active goals can be changed")
+	}
+
+	if req.Title != nil {
+		goal.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		goal.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Target != nil {
+		if goal.Type != TypeHabit && *req.Target == goal.BaselineValue {
+			return nil, errors.New("the target must differ from the baseline")
+		}
+		goal.TargetValue = *req.Target
+	}
+	if req.Deadline != nil {
+		deadline, err := parseDeadline(*req.Deadline, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		goal.Deadline = deadline
+	}
+
+	if err := s.db.Save(goal).Error; err != nil {
+		return nil, err
+	}
+	if err := s.evaluate(goal, time.Now()); err != nil {
+		return nil, err
+	}
+	return buildGoalResponse(goal, time.Now()), nil
+}
+
+// DeleteGoal deletes one of the member's goals
+func (s *GoalService) DeleteGoal(goalID, userID uint) error {
+	goal, err := s.findOwnGoal(goalID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(goal).Error
+}
+
+// EvaluateGoals recomputes a member's active goals
+func (s *GoalService) EvaluateGoals(userID uint) error {
+	var goals []models.Goal
+	if err := s.db.Where("user_id = ? AND status = ?", userID, StatusActive).Find(&goals).Error; err != nil {
+		return errors.New("failed to load goals")
+	}
+	now := time.Now()
+	for i := range goals {
+		if err := s.evaluate(&goals[i], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EvaluateActiveGoals recomputes every active goal, so deadlines pass even for members who are away
+func (s *GoalService) EvaluateActiveGoals() error {
+	var goals []models.Goal
+	if err := s.db.Where("status = ?", StatusActive).Find(&goals).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range goals {
+		if err := s.evaluate(&goals[i], now); err != nil {
+			log.Printf("❌ Failed to evaluate goal %d: %v", goals[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// StartEvaluationScheduler evaluates active goals in the background
+func (s *GoalService) StartEvaluationScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.EvaluateActiveGoals(); err != nil {
+				log.Printf("❌ Goal evaluation failed: %v", err)
+			}
+		}
+	}()
+}
+
+// GetActiveGoals evaluates and returns a member's active goals, nearest deadline first
+func (s *GoalService) GetActiveGoals(userID uint) ([]models.Goal, error) {
+	goals, err := s.GetGoals(userID, userID, "member", StatusActive)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]models.Goal, len(goals))
+	for i := range goals {
+		active[i] = goals[i].Goal
+	}
+	return active, nil
+}
+
+// evaluate recomputes a goal's current value and progress and applies a due status change
+func (s *GoalService) evaluate(goal *models.Goal, now time.Time) error {
+	current, ok, err := s.currentValue(goal, now)
+	if err != nil {
+		return err
+	}
+	if ok {
+		goal.CurrentValue = current
+	}
+	goal.Progress = goalProgress(goal)
+	goal.EvaluatedAt = &now
+
+	updates := map[string]interface{}{
+		"current_value": goal.CurrentValue,
+		"progress":      goal.Progress,
+		"evaluated_at":  now,
+	}
+	transition := nextStatus(goal, ok, now)
+	if transition != "" {
+		goal.Status = transition
+		goal.CompletedAt = &now
+		updates["status"] = transition
+		updates["completed_at"] = now
+	}
+
+	// Only the evaluation that moves the goal out of active announces it
+	result := s.db.Model(&models.Goal{}).Where("id = ? AND status = ?", goal.ID, StatusActive).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if transition != "" && result.RowsAffected == 1 {
+		s.notifyTransition(goal)
+	}
+	return nil
+}
+
+// currentValue reads the latest value of what the goal tracks; ok is false when nothing was logged yet
+func (s *GoalService) currentValue(goal *models.Goal, now time.Time) (float64, bool, error) {
+	switch goal.Type {
+	case TypeWeight:
+		var entry models.ProgressLog
+		err := s.db.Where("user_id = ? AND weight > 0", goal.UserID).Order("logged_at DESC, id DESC").First(&entry).Error
+		if err != nil {
+			return 0, false, nil
+		}
+		return entry.Weight, true, nil
+
+	case TypeBodyFat:
+		var entries []models.ProgressLog
+		err := s.db.Where("user_id = ? AND measurements <> ''", goal.UserID).Order("logged_at DESC, id DESC").Find(&entries).Error
+		if err != nil {
+			return 0, false, errors.New("failed to load progress entries")
+		}
+		for _, entry := range entries {
+			var measurements map[string]float64
+			json.Unmarshal([]byte(entry.Measurements), &measurements)
+			if value := progress.MeasurementValue(measurements, progress.BodyFatKeys); value > 0 {
+				return value, true, nil
+			}
+		}
+		return 0, false, nil
+
+	case TypeMeasurement:
+		return s.progressService.LatestMeasurement(goal.UserID, goal.MeasurementSite, goal.MeasurementSide)
+
+	case TypePerformance:
+		query := s.db.Model(&models.PersonalRecord{}).
+			Where("user_id = ? AND exercise_key = ? AND record_type = ?", goal.UserID, workout.ExerciseKey(goal.ExerciseID, goal.ExerciseName), goal.RecordType)
+		aggregate := "MAX(value)"
+		if goal.RecordType == "fastest_time" {
+			query = query.Where("distance_meters = ?", goal.DistanceMeters)
+			aggregate = "MIN(value)"
+		}
+		var best *float64
+		if err := query.Select(aggregate).Scan(&best).Error; err != nil {
+			return 0, false, errors.New("failed to load personal records")
+		}
+		if best == nil {
+			return 0, false, nil
+		}
+		return *best, true, nil
+
+	case TypeHabit:
+		end := now
+		if deadlineEnd := endOfDay(goal.Deadline); deadlineEnd.Before(end) {
+			end = deadlineEnd
+		}
+		var count int64
+		var err error
+		switch goal.Habit {
+		case "workouts_per_week":
+			err = s.db.Model(&models.WorkoutSession{}).
+				Where("user_id = ? AND status = ? AND finished_at >= ? AND finished_at < ?", goal.UserID, "completed", goal.StartDate, end).
+				Count(&count).Error
+		case "weigh_ins_per_week":
+			err = s.db.Model(&models.ProgressLog{}).
+				Where("user_id = ? AND weight > 0 AND logged_at >= ? AND logged_at < ?", goal.UserID, goal.StartDate, end).
+				Count(&count).Error
+		}
+		if err != nil {
+			return 0, false, errors.New("failed to count habit entries")
+		}
+		return weeklyRate(int(count), goal.StartDate, end), true, nil
+	}
+	return 0, false, nil
+}
+
+// notifyTransition tells the member, and their trainer, that a goal was achieved or missed
+func (s *GoalService) notifyTransition(goal *models.Goal) {
+	msg := notification.Message{
+		Type:     "success",
+		Category: "goal",
+		Title:    "Goal achieved!",
+		Message:  fmt.Sprintf("You reached your goal: %s", goal.Title),
+		Link:     fmt.Sprintf("/goals/%d", goal.ID),
+	}
+	if goal.Status == StatusMissed {
+		msg.Type = "warning"
+		msg.Title = "Goal deadline passed"
+		msg.Message = fmt.Sprintf("%s was not reached by %s (currently %s %s). Set a new goal to keep going",
+			goal.Title, goal.Deadline.Format("2 Jan 2006"), formatNumber(goal.CurrentValue), goal.Unit)
+	}
+	s.notificationService.Notify(goal.UserID, msg)
+
+	trainerID := plan.MemberTrainerID(s.db, goal.UserID)
+	if trainerID == nil {
+		return
+	}
+	var member models.User
+	if err := s.db.First(&member, goal.UserID).Error; err != nil {
+		return
+	}
+	msg.Title = fmt.Sprintf("%s %s %s a goal", member.FirstName, member.LastName, goal.Status)
+	msg.Message = goal.Title
+	msg.Link = fmt.Sprintf("/goals?user_id=%d", goal.UserID)
+	s.notificationService.Notify(*trainerID, msg)
+}
+
+// canViewGoals checks that the viewer may see a member's goals
+func (s *GoalService) canViewGoals(memberID, viewerID uint, viewerRole string) error {
+	switch {
+	case memberID == viewerID, viewerRole == "admin":
+		return nil
+	case viewerRole == "trainer" && plan.IsAssignedTrainer(s.db, viewerID, memberID):
+		return nil
+	default:
+		return errors.New("you can only view your own goals or those of your clients")
+	}
+}
+
+func (s *GoalService) findOwnGoal(goalID, userID uint) (*models.Goal, error) {
+	var goal models.Goal
+	if err := s.db.Where("id = ? AND user_id = ?", goalID, userID).First(&goal).Error; err != nil {
+		return nil, errors.New("goal not found")
+	}
+	return &goal, nil
+}
+
+// buildGoal validates a creation request and turns it into a goal starting today
+func buildGoal(req *CreateGoalRequest, now time.Time) (*models.Goal, error) {
+	deadline, err := parseDeadline(req.Deadline, now)
+	if err != nil {
+		return nil, err
+	}
+
+	goal := &models.Goal{
+		Type:        req.Type,
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		TargetValue: req.Target,
+		StartDate:   utils.DateOnly(now),
+		Deadline:    deadline,
+		Status:      StatusActive,
+	}
+
+	switch req.Type {
+	case TypeWeight:
+		goal.Unit = "kg"
+	case TypeBodyFat:
+		if req.Target >= 70 {
+			return nil, errors.New("body fat targets must be below 70%")
+		}
+		goal.Unit = "%"
+	case TypeMeasurement:
+		key := strings.TrimSpace(req.MeasurementSide + " " + req.MeasurementSite)
+		site, side, ok := progress.ParseMeasurementKey(key)
+		if !ok {
+			return nil, fmt.Errorf("invalid measurement site %q", key)
+		}
+		if side == "" && isBilateral(site) {
+			return nil, fmt.Errorf("measurement_side (left or right) is required for %s goals", site)
+		}
+		goal.MeasurementSite, goal.MeasurementSide = site, side
+		goal.Unit = progress.UnitCm
+	case TypePerformance:
+		if !isRecordType(req.RecordType) {
+			return nil, fmt.Errorf("invalid record type %q, expected one of %s", req.RecordType, strings.Join(workout.RecordTypes, ", "))
+		}
+		if req.ExerciseID == nil && strings.TrimSpace(req.ExerciseName) == "" {
+			return nil, errors.New("performance goals need an exercise_id or exercise_name")
+		}
+		if req.RecordType == "fastest_time" && req.DistanceMeters <= 0 {
+			return nil, errors.New("fastest_time goals need the distance_meters the time is for")
+		}
+		goal.ExerciseID = req.ExerciseID
+		goal.ExerciseName = strings.TrimSpace(req.ExerciseName)
+		goal.RecordType = req.RecordType
+		if req.RecordType == "fastest_time" {
+			goal.DistanceMeters = req.DistanceMeters
+		}
+		goal.Unit = workout.RecordUnit(req.RecordType)
+	case TypeHabit:
+		if !isHabit(req.Habit) {
+			return nil, fmt.Errorf("invalid habit %q, expected one of %s", req.Habit, strings.Join(Habits, ", "))
+		}
+		if req.Target > 14 {
+			return nil, errors.New("habit targets are counted per week and cannot exceed 14")
+		}
+		goal.Habit = req.Habit
+		goal.Unit = "per week"
+	}
+	return goal, nil
+}
+
+// goalProgress is the share of the way from baseline to target covered, 0-100
+// Habit goals compare the weekly rate so far with the target rate
+func goalProgress(goal *models.Goal) float64 {
+	var ratio float64
+	if goal.Type == TypeHabit {
+		ratio = goal.CurrentValue / goal.TargetValue
+	} else if goal.TargetValue != goal.BaselineValue {
+		ratio = (goal.CurrentValue - goal.BaselineValue) / (goal.TargetValue - goal.BaselineValue)
+	}
+	return math.Round(math.Max(0, math.Min(ratio, 1))*1000) / 10
+}
+
+// nextStatus returns the status an active goal moves to, or "" when it stays active
+// Targets count as reached as soon as they are hit; habit goals are only judged at the deadline
+func nextStatus(goal *models.Goal, hasValue bool, now time.Time) string {
+	reached := hasValue && targetReached(goal)
+	if goal.Type != TypeHabit && reached {
+		return StatusAchieved
+	}
+	if !now.Before(endOfDay(goal.Deadline)) {
+		if reached {
+			return StatusAchieved
+		}
+		return StatusMissed
+	}
+	return ""
+}
+
+// targetReached reports whether the current value is at or past the target,
+// in the direction of travel from the baseline
+func targetReached(goal *models.Goal) bool {
+	if goal.Type == TypeHabit || goal.TargetValue > goal.BaselineValue {
+		return goal.CurrentValue >= goal.TargetValue
+	}
+	return goal.CurrentValue <= goal.TargetValue
+}
+
+// describeGoal generates a title from the target
+func describeGoal(goal *models.Goal) string {
+	target := formatNumber(goal.TargetValue)
+	switch goal.Type {
+	case TypeWeight:
+		return fmt.Sprintf("Reach %s kg", target)
+	case TypeBodyFat:
+		return fmt.Sprintf("Reach %s%% body fat", target)
+	case TypeMeasurement:
+		site := strings.TrimSpace(goal.MeasurementSide + " " + goal.MeasurementSite)
+		return fmt.Sprintf("%s%s to %s cm", strings.ToUpper(site[:1]), site[1:], target)
+	case TypePerformance:
+		record := strings.ReplaceAll(goal.RecordType, "_", " ")
+		if goal.RecordType == "fastest_time" {
+			record = fmt.Sprintf("%s m in", formatNumber(goal.DistanceMeters))
+		}
+		return fmt.Sprintf("%s %s %s %s", goal.ExerciseName, record, target, goal.Unit)
+	case TypeHabit:
+		return fmt.Sprintf("%s %s a week", target, strings.ReplaceAll(strings.TrimSuffix(goal.Habit, "_per_week"), "_", "-"))
+	}
+	return target
+}
+
+// describeSource names what a goal is computed from, for error messages
+func describeSource(goal *models.Goal) string {
+	switch goal.Type {
+	case TypeWeight:
+		return "weight"
+	case TypeBodyFat:
+		return "body fat"
+	case TypeMeasurement:
+		return strings.TrimSpace(goal.MeasurementSide+" "+goal.MeasurementSite) + " measurement"
+	default:
+		return "matching personal record"
+	}
+}
+
+func buildGoalResponse(goal *models.Goal, now time.Time) *GoalResponse {
+	response := &GoalResponse{Goal: *goal}
+	if goal.Status == StatusActive {
+		if remaining := endOfDay(goal.Deadline).Sub(now).Hours() / 24; remaining > 0 {
+			response.DaysRemaining = int(math.Ceil(remaining))
+		}
+	}
+	return response
+}
+
+func parseDeadline(value string, now time.Time) (time.Time, error) {
+	deadline, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New("invalid deadline, expected YYYY-MM-DD")
+	}
+	if !deadline.After(utils.DateOnly(now)) {
+		return time.Time{}, errors.New("the deadline must be in the future")
+	}
+	return deadline, nil
+}
+
+// weeklyRate averages a count over the weeks between two times, counting at least one week
+func weeklyRate(count int, from, to time.Time) float64 {
+	weeks := math.Max(1, to.Sub(from).Hours()/(24*7))
+	return utils.RoundTenth(float64(count) / weeks)
+}
+
+func isRecordType(recordType string) bool {
+	for _, t := range workout.RecordTypes {
+		if t == recordType {
+			return true
+		}
+	}
+	return false
+}
+
+func isBilateral(site string) bool {
+	for _, definition := range progress.MeasurementSites {
+		if definition.Name == site {
+			return definition.Bilateral
+		}
+	}
+	return false
+}
+
+func isHabit(habit string) bool {
+	for _, h := range Habits {
+		if h == habit {
+			return true
+		}
+	}
+	return false
+}
+
+func endOfDay(date time.Time) time.Time {
+	return utils.DateOnly(date).AddDate(0, 0, 1)
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,98 @@
+package goal
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestBuildGoal(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	if _, err := buildGoal(&CreateGoalRequest{Type: TypeWeight, Target: 75, Deadline: "2024-03-01"}, now); err == nil {
+		t.Error("expected a deadline of today to be rejected")
+	}
+	if _, err := buildGoal(&CreateGoalRequest{Type: TypeMeasurement, Target: 80, Deadline: "2024-06-01", MeasurementSite: "arm"}, now); err == nil {
+		t.Error("expected a bilateral site without a side to be rejected")
+	}
+	if _, err := buildGoal(&CreateGoalRequest{Type: TypePerformance, Target: 1500, Deadline: "2024-06-01", ExerciseName: "Running", RecordType: "fastest_time"}, now); err == nil {
+		t.Error("expected a fastest_time goal without a distance to be rejected")
+	}
+	if _, err := buildGoal(&CreateGoalRequest{Type: TypeHabit, Target: 3, Deadline: "2024-06-01", Habit: "meditation"}, now); err == nil {
+		t.Error("expected an unknown habit to be rejected")
+	}
+
+	goal, err := buildGoal(&CreateGoalRequest{Type: TypeMeasurement, Target: 38, Deadline: "2024-06-01", MeasurementSite: "arm", MeasurementSide: "left"}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if goal.MeasurementSite != "arm" || goal.MeasurementSide != "left" || goal.Unit != "cm" || !goal.StartDate.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected measurement goal %+v", goal)
+	}
+	if title := describeGoal(goal); title != "Left arm to 38 cm" {
+		t.Errorf("unexpected title %q", title)
+	}
+
+	goal, err = buildGoal(&CreateGoalRequest{Type: TypePerformance, Target: 1500, Deadline: "2024-06-01", ExerciseName: "Running", RecordType: "fastest_time", DistanceMeters: 5000}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if title := describeGoal(goal); title != "Running 5000 m in 1500 seconds" {
+		t.Errorf("unexpected title %q", title)
+	}
+}
+
+func TestGoalProgressAndStatus(t *testing.T) {
+	deadline := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := deadline.Add(-24 * time.Hour)
+	after := deadline.Add(25 * time.Hour)
+
+	// Losing weight: 90 -> 80, currently 85
+	goal := &models.Goal{Type: TypeWeight, BaselineValue: 90, TargetValue: 80, CurrentValue: 85, Deadline: deadline}
+	if p := goalProgress(goal); p != 50 {
+		t.Errorf("expected 50%% progress, got %v", p)
+	}
+	if status := nextStatus(goal, true, before); status != "" {
+		t.Errorf("expected the goal to stay active, got %q", status)
+	}
+	if status := nextStatus(goal, true, deadline.Add(12*time.Hour)); status != "" {
+		t.Errorf("expected the goal to stay active on its deadline day, got %q", status)
+	}
+	if status := nextStatus(goal, true, after); status != StatusMissed {
+		t.Errorf("expected the goal to be missed after the deadline, got %q", status)
+	}
+
+	goal.CurrentValue = 79.5
+	if p := goalProgress(goal); p != 100 {
+		t.Errorf("expected progress capped at 100%%, got %v", p)
+	}
+	if status := nextStatus(goal, true, before); status != StatusAchieved {
+		t.Errorf("expected the goal to be achieved, got %q", status)
+	}
+
+	// Gaining: a heavier bench press
+	goal = &models.Goal{Type: TypePerformance, BaselineValue: 80, TargetValue: 100, CurrentValue: 75, Deadline: deadline}
+	if p := goalProgress(goal); p != 0 {
+		t.Errorf("expected regressions to count as 0%%, got %v", p)
+	}
+
+	// Habits are only judged at the deadline
+	goal = &models.Goal{Type: TypeHabit, TargetValue: 3, CurrentValue: 3.5, Deadline: deadline}
+	if status := nextStatus(goal, true, before); status != "" {
+		t.Errorf("expected the habit goal to stay active, got %q", status)
+	}
+	if status := nextStatus(goal, true, after); status != StatusAchieved {
+		t.Errorf("expected the habit goal to be achieved, got %q", status)
+	}
+}
+
+func TestWeeklyRate(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if rate := weeklyRate(6, start, start.AddDate(0, 0, 14)); rate != 3 {
+		t.Errorf("expected 3 per week, got %v", rate)
+	}
+	if rate := weeklyRate(2, start, start.AddDate(0, 0, 2)); rate != 2 {
+		t.Errorf("expected the first week to count as a full week, got %v", rate)
+	}
+}
@@ -0,0 +1,261 @@
+package goal
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fittrackplus/internal/auth"
+	"fittrackplus/internal/common/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GoalHandler handles goal HTTP requests
+type GoalHandler struct {
+	goalService *GoalService
+}
+
+// NewGoalHandler creates a new goal handler
+func NewGoalHandler(cfg *config.Config) *GoalHandler {
+	return &GoalHandler{
+		goalService: NewGoalService(cfg),
+	}
+}
+
+// CreateGoal godoc
+// @Summary Set a goal
+// @Description Set a weight, body fat, measurement, performance or habit goal with a target and deadline. The baseline defaults to the latest logged value. Progress is computed from progress entries, measurements, personal records and completed workouts, and the goal is marked achieved or missed automatically with a notification
+// @Tags Goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param goal body CreateGoalRequest true "Goal"
+// @Success 201 {object} GoalResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Exercise not found"
+// @Router /goals [post]
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	var req CreateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	goal, err := h.goalService.CreateGoal(userID, &req)
+	if err != nil {
+		c.JSON(goalErrorStatus(err), gin.H{
+			"error":   "Failed to create goal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// GetGoals godoc
+// @Summary List goals
+// @Description List a member's goals, active ones first by deadline. Active goals are re-evaluated against the latest logs. Trainers can pass user_id for the members they assigned plans to
+// @Tags Goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param status query string false "Filter by status (active, achieved, missed)"
+// @Success 200 {array} GoalResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /goals [get]
+func (h *GoalHandler) GetGoals(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+
+	goals, err := h.goalService.GetGoals(memberID, userID, userRole, c.Query("status"))
+	if err != nil {
+		c.JSON(goalErrorStatus(err), gin.H{
+			"error":   "Failed to get goals",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+// GetGoal godoc
+// @Summary Get a goal
+// @Description Get one goal with its current value and progress. Members see their own; trainers see their clients'
+// @Tags Goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Goal ID"
+// @Success 200 {object} GoalResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Failure 404 {object} map[string]interface{} "Goal not found"
+// @Router /goals/{id} [get]
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	goalID, userID, ok := goalParams(c)
+	if !ok {
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	goal, err := h.goalService.GetGoal(goalID, userID, userRole)
+	if err != nil {
+		c.JSON(goalErrorStatus(err), gin.H{
+			"error":   "Failed to get goal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// UpdateGoal godoc
+// @Summary Update a goal
+// @Description Change the title, description, target or deadline of one of the current user's active goals
+// @Tags Goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Goal ID"
+// @Param goal body UpdateGoalRequest true "Goal changes"
+// @Success 200 {object} GoalResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Goal not found"
+// @Router /goals/{id} [put]
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	goalID, userID, ok := goalParams(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	goal, err := h.goalService.UpdateGoal(goalID, userID, &req)
+	if err != nil {
+		c.JSON(goalErrorStatus(err), gin.H{
+			"error":   "Failed to update goal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// DeleteGoal godoc
+// @Summary Delete a goal
+// @Description Delete one of the current user's goals
+// @Tags Goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Goal ID"
+// @Success 200 {object} map[string]interface{} "Goal deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Goal not found"
+// @Router /goals/{id} [delete]
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	goalID, userID, ok := goalParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.goalService.DeleteGoal(goalID, userID); err != nil {
+		c.JSON(goalErrorStatus(err), gin.H{
+			"error":   "Failed to delete goal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Goal deleted successfully",
+	})
+}
+
+// goalParams reads the caller and the goal ID, writing an error response when either is missing
+func goalParams(c *gin.Context) (goalID, userID uint, ok bool) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid goal ID",
+		})
+		return 0, 0, false
+	}
+
+	return uint(id), userID, true
+}
+
+// memberParam reads the optional user_id query parameter, defaulting to the caller
+func memberParam(c *gin.Context, userID uint) (uint, bool) {
+	value := c.Query("user_id")
+	if value == "" {
+		return userID, true
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func goalErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "you can only"):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
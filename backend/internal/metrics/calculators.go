@@ -0,0 +1,137 @@
+package metrics
+
+// ActivityLevels lists the supported activity levels from least to most active
+var ActivityLevels = []string{"sedentary", "light", "moderate", "active", "very_active"}
+
+// ActivityMultipliers scale BMR to total daily energy expenditure
+var ActivityMultipliers = map[string]float64{
+	"sedentary":   1.2,   // little or no exercise
+	"light":       1.375, // light exercise 1-3 days a week
+	"moderate":    1.55,  // moderate exercise 3-5 days a week
+	"active":      1.725, // hard exercise 6-7 days a week
+	"very_active": 1.9,   // physical job or twice-daily training
+}
+
+// BMI returns the body mass index for a weight in kg and a height in cm
+func BMI(weightKg, heightCm float64) float64 {
+	if weightKg <= 0 || heightCm <= 0 {
+		return 0
+	}
+	meters := heightCm / 100
+	return weightKg / (meters * meters)
+}
+
+// BMICategory classifies a BMI using the WHO adult ranges
+func BMICategory(bmi float64) string {
+	switch {
+	case bmi < 18.5:
+		return "underweight"
+	case bmi < 25:
+		return "normal"
+	case bmi < 30:
+		return "overweight"
+	default:
+		return "obese"
+	}
+}
+
+// BMR returns the basal metabolic rate in kcal/day using the Mifflin-St Jeor equation
+// The sex constant is averaged for genders other than male and female
+func BMR(weightKg, heightCm float64, age int, gender string) float64 {
+	base := 10*weightKg + 6.25*heightCm - 5*float64(age)
+	switch gender {
+	case "male":
+		return base + 5
+	case "female":
+		return base - 161
+	default:
+		return base - 78
+	}
+}
+
+// TDEE returns the total daily energy expenditure for a BMR and activity level
+func TDEE(bmr float64, activityLevel string) (float64, bool) {
+	multiplier, ok := ActivityMultipliers[activityLevel]
+	if !ok {
+		return 0, false
+	}
+	return bmr * multiplier, true
+}
+
+// IdealWeightRange returns the weights (kg) giving a normal BMI of 18.5-24.9 at a height
+func IdealWeightRange(heightCm float64) (lower, upper float64) {
+	meters := heightCm / 100
+	return 18.5 * meters * meters, 24.9 * meters * meters
+}
+
+// DevineWeight returns the Devine formula ideal weight in kg
+func DevineWeight(heightCm float64, gender string) float64 {
+	inchesOver5ft := heightCm/2.54 - 60
+	switch gender {
+	case "male":
+		return 50 + 2.3*inchesOver5ft
+	case "female":
+		return 45.5 + 2.3*inchesOver5ft
+	default:
+		return 47.75 + 2.3*inchesOver5ft
+	}
+}
+
+// WaistToHeightCategory classifies a waist-to-height ratio
+func WaistToHeightCategory(ratio float64) string {
+	switch {
+	case ratio < 0.4:
+		return "low"
+	case ratio < 0.5:
+		return "healthy"
+	case ratio < 0.6:
+		return "increased_risk"
+	default:
+		return "high_risk"
+	}
+}
+
+// LeanBodyMass returns the fat-free mass in kg. A measured body fat percentage is
+// used when known; otherwise the mass is estimated with the Boer formula
+func LeanBodyMass(weightKg, heightCm float64, gender string, bodyFatPercentage float64) (value float64, measured bool) {
+	if bodyFatPercentage > 0 {
+		return weightKg * (1 - bodyFatPercentage/100), true
+	}
+	male := 0.407*weightKg + 0.267*heightCm - 19.2
+	female := 0.252*weightKg + 0.473*heightCm - 48.3
+	switch gender {
+	case "male":
+		return male, false
+	case "female":
+		return female, false
+	default:
+		return (male + female) / 2, false
+	}
+}
+
+// BodyFatCategory classifies a body fat percentage using the ACE ranges
+// Genders other than male and female are not categorised
+func BodyFatCategory(percentage float64, gender string) string {
+	var limits [4]float64
+	switch gender {
+	case "male":
+		limits = [4]float64{6, 14, 18, 25}
+	case "female":
+		limits = [4]float64{14, 21, 25, 32}
+	default:
+		return ""
+	}
+
+	switch {
+	case percentage < limits[0]:
+		return "essential"
+	case percentage < limits[1]:
+		return "athletic"
+	case percentage < limits[2]:
+		return "fitness"
+	case percentage < limits[3]:
+		return "average"
+	default:
+		return "obese"
+	}
+}
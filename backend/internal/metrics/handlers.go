@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/auth"
+	"fittrackplus/internal/common/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler handles health metric HTTP requests
+type MetricsHandler struct {
+	metricsService *MetricsService
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(cfg *config.Config) *MetricsHandler {
+	return &MetricsHandler{
+		metricsService: NewMetricsService(cfg),
+	}
+}
+
+// GetMetrics godoc
+// @Summary Get health metrics
+// @Description Derive BMI, BMR (Mifflin-St Jeor), TDEE, the ideal weight range, waist-to-height ratio, lean body mass and body fat category from the profile and the latest progress entries. Metrics whose inputs are missing are omitted and the inputs listed. Trainers can pass user_id for the members they assigned plans to
+// @Tags Metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param activity_level query string false "Activity level for TDEE instead of the profile's (sedentary, light, moderate, active, very_active)"
+// @Success 200 {object} Metrics
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Failure 404 {object} map[string]interface{} "Profile not found"
+// @Router /metrics [get]
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+
+	metrics, err := h.metricsService.GetMetrics(memberID, userID, userRole, c.Query("activity_level"))
+	if err != nil {
+		c.JSON(metricsErrorStatus(err), gin.H{
+			"error":   "Failed to get metrics",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
+
+// GetMetricsHistory godoc
+// @Summary Get health metrics over time
+// @Description Recalculate the metrics at each progress entry, carrying weight, waist and body fat forward between entries. Trainers can pass user_id for the members they assigned plans to
+// @Tags Metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param from query string false "Entries logged on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Entries logged on or before this date (YYYY-MM-DD)"
+// @Success 200 {object} History
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Failure 404 {object} map[string]interface{} "Profile not found"
+// @Router /metrics/history [get]
+func (h *MetricsHandler) GetMetricsHistory(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+
+	var from, to *time.Time
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		from = &date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid to date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		date = date.AddDate(0, 0, 1) // include the whole day
+		to = &date
+	}
+
+	history, err := h.metricsService.GetHistory(memberID, userID, userRole, from, to)
+	if err != nil {
+		c.JSON(metricsErrorStatus(err), gin.H{
+			"error":   "Failed to get metrics history",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// memberParam reads the optional user_id query parameter, defaulting to the caller
+func memberParam(c *gin.Context, userID uint) (uint, bool) {
+	value := c.Query("user_id")
+	if value == "" {
+		return userID, true
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func metricsErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "you can only"):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
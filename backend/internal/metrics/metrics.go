@@ -0,0 +1,354 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+	"fittrackplus/internal/plan"
+	"fittrackplus/internal/progress"
+
+	"gorm.io/gorm"
+)
+
+// MetricsService derives health and body composition metrics from profiles and progress logs
+type MetricsService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewMetricsService creates a new metrics service
+func NewMetricsService(cfg *config.Config) *MetricsService {
+	return &MetricsService{
+		db:  database.GetDB(),
+		cfg: cfg,
+	}
+}
+
+// Metric is a derived value with its category and the reference it was judged against
+type Metric struct {
+	Value     float64 `json:"value"`
+	Unit      string  `json:"unit"`
+	Category  string  `json:"category,omitempty"`
+	Reference string  `json:"reference"`
+}
+
+// IdealWeight is the healthy weight range for a height
+type IdealWeight struct {
+	MinKg     float64 `json:"min_kg"`
+	MaxKg     float64 `json:"max_kg"`
+	DevineKg  float64 `json:"devine_kg"`
+	Reference string  `json:"reference"`
+}
+
+// Inputs are the body data metrics are derived from
+type Inputs struct {
+	WeightKg          float64 `json:"weight_kg"`
+	HeightCm          float64 `json:"height_cm"`
+	Age               int     `json:"age"`
+	Gender            string  `json:"gender"`
+	ActivityLevel     string  `json:"activity_level,omitempty"`
+	WaistCm           float64 `json:"waist_cm,omitempty"`
+	BodyFatPercentage float64 `json:"body_fat_percentage,omitempty"`
+}
+
+// Metrics holds the metrics derived from a member's latest data
+type Metrics struct {
+	UserID         uint               `json:"user_id"`
+	Inputs         Inputs             `json:"inputs"`
+	BMI            *Metric            `json:"bmi,omitempty"`
+	BMR            *Metric            `json:"bmr,omitempty"`
+	TDEE           *Metric            `json:"tdee,omitempty"`
+	TDEEByActivity map[string]float64 `json:"tdee_by_activity,omitempty"` // kcal/day for every activity level
+	IdealWeight    *IdealWeight       `json:"ideal_weight,omitempty"`
+	WaistToHeight  *Metric            `json:"waist_to_height,omitempty"`
+	LeanBodyMass   *Metric            `json:"lean_body_mass,omitempty"`
+	BodyFat        *Metric            `json:"body_fat,omitempty"`
+	Missing        []string           `json:"missing"` // inputs needed by the metrics that could not be derived
+	CalculatedAt   time.Time          `json:"calculated_at"`
+}
+
+// Snapshot is the metrics as of one progress entry
+type Snapshot struct {
+	Date          time.Time `json:"date"`
+	WeightKg      float64   `json:"weight_kg"`
+	BMI           float64   `json:"bmi,omitempty"`
+	BMR           float64   `json:"bmr,omitempty"`
+	TDEE          float64   `json:"tdee,omitempty"`
+	WaistToHeight float64   `json:"waist_to_height,omitempty"`
+	LeanBodyMass  float64   `json:"lean_body_mass,omitempty"`
+	BodyFat       float64   `json:"body_fat,omitempty"`
+}
+
+// History is a member's metrics recalculated at each progress entry
+type History struct {
+	UserID    uint       `json:"user_id"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// GetMetrics derives a member's current metrics from their profile and latest progress entries
+// activityLevel overrides the profile's activity level when set
+func (s *MetricsService) GetMetrics(memberID, viewerID uint, viewerRole, activityLevel string) (*Metrics, error) {
+	if err := s.canViewMetrics(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	if _, ok := ActivityMultipliers[activityLevel]; activityLevel != "" && !ok {
+		return nil, fmt.Errorf("invalid activity level %q", activityLevel)
+	}
+
+	profile, logs, waists, err := s.loadData(memberID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := profileInputs(profile)
+	for i := range logs {
+		applyLog(&inputs, &logs[i], waists)
+	}
+	if activityLevel != "" {
+		inputs.ActivityLevel = activityLevel
+	}
+
+	metrics := Calculate(inputs)
+	metrics.UserID = memberID
+	return metrics, nil
+}
+
+// GetHistory recalculates a member's metrics at each progress entry in the range
+// Values carry forward between entries; age is derived backwards from the profile age
+func (s *MetricsService) GetHistory(memberID, viewerID uint, viewerRole string, from, to *time.Time) (*History, error) {
+	if err := s.canViewMetrics(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	profile, logs, waists, err := s.loadData(memberID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	inputs := profileInputs(profile)
+	history := &History{UserID: memberID, Snapshots: []Snapshot{}}
+	for i := range logs {
+		applyLog(&inputs, &logs[i], waists)
+		if from != nil && logs[i].LoggedAt.Before(*from) {
+			continue
+		}
+		if logs[i].Weight <= 0 && logs[i].Measurements == "" && waists[logs[i].ID] == 0 {
+			continue
+		}
+
+		at := inputs
+		at.Age = ageAt(profile.Age, logs[i].LoggedAt, now)
+		metrics := Calculate(at)
+		snapshot := Snapshot{Date: logs[i].LoggedAt, WeightKg: at.WeightKg}
+		snapshot.BMI = metricValue(metrics.BMI)
+		snapshot.BMR = metricValue(metrics.BMR)
+		snapshot.TDEE = metricValue(metrics.TDEE)
+		snapshot.WaistToHeight = metricValue(metrics.WaistToHeight)
+		snapshot.LeanBodyMass = metricValue(metrics.LeanBodyMass)
+		snapshot.BodyFat = metricValue(metrics.BodyFat)
+		history.Snapshots = append(history.Snapshots, snapshot)
+	}
+	return history, nil
+}
+
+// Calculate derives every metric the inputs allow, listing the inputs that are missing for the rest
+func Calculate(inputs Inputs) *Metrics {
+	metrics := &Metrics{Inputs: inputs, Missing: []string{}, CalculatedAt: time.Now()}
+	missing := func(name string) { metrics.Missing = append(metrics.Missing, name) }
+
+	if inputs.HeightCm <= 0 {
+		missing("height")
+	}
+	if inputs.WeightKg <= 0 {
+		missing("weight")
+	}
+	if inputs.HeightCm <= 0 {
+		return metrics
+	}
+
+	lower, upper := IdealWeightRange(inputs.HeightCm)
+	metrics.IdealWeight = &IdealWeight{
+		MinKg:     utils.Round(lower, 1),
+		MaxKg:     utils.Round(upper, 1),
+		DevineKg:  utils.Round(DevineWeight(inputs.HeightCm, inputs.Gender), 1),
+		Reference: "Weights giving a BMI of 18.5-24.9; Devine formula for comparison",
+	}
+
+	if inputs.WaistCm > 0 {
+		ratio := inputs.WaistCm / inputs.HeightCm
+		metrics.WaistToHeight = &Metric{
+			Value:     utils.Round(ratio, 2),
+			Category:  WaistToHeightCategory(ratio),
+			Reference: "low < 0.40, healthy 0.40-0.49, increased risk 0.50-0.59, high risk >= 0.60",
+		}
+	} else {
+		missing("waist")
+	}
+
+	if inputs.WeightKg <= 0 {
+		return metrics
+	}
+
+	bmi := BMI(inputs.WeightKg, inputs.HeightCm)
+	metrics.BMI = &Metric{
+		Value:     utils.Round(bmi, 1),
+		Unit:      "kg/m2",
+		Category:  BMICategory(bmi),
+		Reference: "WHO: underweight < 18.5, normal 18.5-24.9, overweight 25-29.9, obese >= 30",
+	}
+
+	lean, measured := LeanBodyMass(inputs.WeightKg, inputs.HeightCm, inputs.Gender, inputs.BodyFatPercentage)
+	metrics.LeanBodyMass = &Metric{Value: utils.Round(lean, 1), Unit: "kg", Reference: "Boer formula estimate"}
+	if measured {
+		metrics.LeanBodyMass.Reference = "Weight minus measured body fat"
+	}
+
+	if inputs.BodyFatPercentage > 0 {
+		metrics.BodyFat = &Metric{
+			Value:     utils.Round(inputs.BodyFatPercentage, 1),
+			Unit:      "%",
+			Category:  BodyFatCategory(inputs.BodyFatPercentage, inputs.Gender),
+			Reference: "ACE: men athletic 6-13%, fitness 14-17%, average 18-24%; women athletic 14-20%, fitness 21-24%, average 25-31%",
+		}
+	}
+
+	if inputs.Age <= 0 || inputs.Gender == "" {
+		if inputs.Age <= 0 {
+			missing("age")
+		}
+		if inputs.Gender == "" {
+			missing("gender")
+		}
+		return metrics
+	}
+
+	bmr := BMR(inputs.WeightKg, inputs.HeightCm, inputs.Age, inputs.Gender)
+	metrics.BMR = &Metric{
+		Value:     utils.Round(bmr, 0),
+		Unit:      "kcal/day",
+		Reference: "Mifflin-St Jeor: 10 x weight + 6.25 x height - 5 x age + 5 (men) or - 161 (women)",
+	}
+
+	metrics.TDEEByActivity = map[string]float64{}
+	for _, level := range ActivityLevels {
+		tdee, _ := TDEE(bmr, level)
+		metrics.TDEEByActivity[level] = utils.Round(tdee, 0)
+	}
+	if tdee, ok := TDEE(bmr, inputs.ActivityLevel); ok {
+		metrics.TDEE = &Metric{
+			Value:     utils.Round(tdee, 0),
+			Unit:      "kcal/day",
+			Category:  inputs.ActivityLevel,
+			Reference: fmt.Sprintf("BMR x %.3g activity multiplier", ActivityMultipliers[inputs.ActivityLevel]),
+		}
+	} else {
+		missing("activity_level")
+	}
+	return metrics
+}
+
+// canViewMetrics checks that the viewer may see a member's metrics
+func (s *MetricsService) canViewMetrics(memberID, viewerID uint, viewerRole string) error {
+	switch {
+	case memberID == viewerID, viewerRole == "admin":
+		return nil
+	case viewerRole == "trainer" && plan.IsAssignedTrainer(s.db, viewerID, memberID):
+		return nil
+	default:
+		return errors.New("you can only view your own metrics or those of your clients")
+	}
+}
+
+// loadData loads a member's profile and progress entries before an optional date, oldest
+// first, with the waist measured at each entry
+func (s *MetricsService) loadData(memberID uint, to *time.Time) (*models.UserProfile, []models.ProgressLog, map[uint]float64, error) {
+	var profile models.UserProfile
+	if err := s.db.Where("user_id = ?", memberID).First(&profile).Error; err != nil {
+		return nil, nil, nil, errors.New("profile not found")
+	}
+
+	query := s.db.Where("user_id = ?", memberID)
+	if to != nil {
+		query = query.Where("logged_at < ?", *to)
+	}
+	var logs []models.ProgressLog
+	if err := query.Order("logged_at, id").Find(&logs).Error; err != nil {
+		return nil, nil, nil, errors.New("failed to load progress entries")
+	}
+
+	var measurements []models.BodyMeasurement
+	if err := s.db.Where("user_id = ? AND site = ?", memberID, "waist").Find(&measurements).Error; err != nil {
+		return nil, nil, nil, errors.New("failed to load body measurements")
+	}
+	waists := map[uint]float64{}
+	for _, measurement := range measurements {
+		waists[measurement.ProgressLogID] = measurement.ValueCm
+	}
+	return &profile, logs, waists, nil
+}
+
+// profileInputs reads the baseline inputs from a profile
+func profileInputs(profile *models.UserProfile) Inputs {
+	inputs := Inputs{
+		WeightKg:          profile.Weight,
+		HeightCm:          profile.Height,
+		Age:               profile.Age,
+		Gender:            profile.Gender,
+		ActivityLevel:     profile.ActivityLevel,
+		BodyFatPercentage: profile.BodyFatPercentage,
+	}
+	if profile.BodyMeasurements != "" {
+		var measurements map[string]float64
+		json.Unmarshal([]byte(profile.BodyMeasurements), &measurements)
+		inputs.WaistCm = measurements["waist"]
+	}
+	return inputs
+}
+
+// applyLog overlays the values recorded in a progress entry
+// Entries recorded before measurements were typed keep the waist in their measurement map
+func applyLog(inputs *Inputs, log *models.ProgressLog, waists map[uint]float64) {
+	if log.Weight > 0 {
+		inputs.WeightKg = log.Weight
+	}
+	if waist := waists[log.ID]; waist > 0 {
+		inputs.WaistCm = waist
+	}
+	if log.Measurements == "" {
+		return
+	}
+	var measurements map[string]float64
+	json.Unmarshal([]byte(log.Measurements), &measurements)
+	if waist := measurements["waist"]; waist > 0 {
+		inputs.WaistCm = waist
+	}
+	if bodyFat := progress.MeasurementValue(measurements, progress.BodyFatKeys); bodyFat > 0 {
+		inputs.BodyFatPercentage = bodyFat
+	}
+}
+
+// ageAt estimates the age at a past date from the current profile age
+func ageAt(currentAge int, at, now time.Time) int {
+	if currentAge <= 0 {
+		return 0
+	}
+	years := now.Year() - at.Year()
+	if now.YearDay() < at.YearDay() {
+		years--
+	}
+	return currentAge - years
+}
+
+func metricValue(metric *Metric) float64 {
+	if metric == nil {
+		return 0
+	}
+	return metric.Value
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculate(t *testing.T) {
+	metrics := Calculate(Inputs{
+		WeightKg:      80,
+		HeightCm:      180,
+		Age:           30,
+		Gender:        "male",
+		ActivityLevel: "moderate",
+		WaistCm:       85,
+	})
+
+	if len(metrics.Missing) != 0 {
+		t.Errorf("expected no missing inputs, got %v", metrics.Missing)
+	}
+	if metrics.BMI.Value != 24.7 || metrics.BMI.Category != "normal" {
+		t.Errorf("unexpected BMI %+v", metrics.BMI)
+	}
+	if metrics.BMR.Value != 1780 || metrics.TDEE.Value != 2759 || metrics.TDEEByActivity["sedentary"] != 2136 {
+		t.Errorf("unexpected energy expenditure: BMR %+v, TDEE %+v, by activity %v", metrics.BMR, metrics.TDEE, metrics.TDEEByActivity)
+	}
+	if metrics.IdealWeight.MinKg != 59.9 || metrics.IdealWeight.MaxKg != 80.7 || metrics.IdealWeight.DevineKg != 75 {
+		t.Errorf("unexpected ideal weight %+v", metrics.IdealWeight)
+	}
+	if metrics.WaistToHeight.Value != 0.47 || metrics.WaistToHeight.Category != "healthy" {
+		t.Errorf("unexpected waist-to-height %+v", metrics.WaistToHeight)
+	}
+	if metrics.LeanBodyMass.Value != 61.4 || metrics.BodyFat != nil {
+		t.Errorf("expected a Boer estimate without body fat, got %+v", metrics.LeanBodyMass)
+	}
+}
+
+func TestCalculateMissingInputs(t *testing.T) {
+	metrics := Calculate(Inputs{WeightKg: 70, HeightCm: 165, Gender: "female", BodyFatPercentage: 28})
+
+	if metrics.BMR != nil || metrics.TDEE != nil || metrics.WaistToHeight != nil {
+		t.Errorf("expected BMR, TDEE and waist-to-height to be omitted, got %+v", metrics)
+	}
+	if len(metrics.Missing) != 2 || metrics.Missing[0] != "waist" || metrics.Missing[1] != "age" {
+		t.Errorf("expected waist and age to be missing, got %v", metrics.Missing)
+	}
+	if metrics.LeanBodyMass.Value != 50.4 || metrics.BodyFat.Category != "average" {
+		t.Errorf("expected lean mass from the measured body fat, got %+v / %+v", metrics.LeanBodyMass, metrics.BodyFat)
+	}
+
+	if metrics := Calculate(Inputs{WeightKg: 70}); metrics.BMI != nil || metrics.IdealWeight != nil {
+		t.Errorf("expected nothing without a height, got %+v", metrics)
+	}
+}
+
+func TestAgeAt(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	if got := ageAt(40, time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), now); got != 39 {
+		t.Errorf("expected 39, got %d", got)
+	}
+	if got := ageAt(40, time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), now); got != 38 {
+		t.Errorf("expected 38, got %d", got)
+	}
+}
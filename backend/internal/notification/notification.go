@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"errors"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+
+	"gorm.io/gorm"
+)
+
+// NotificationService stores and retrieves in-app notifications
+// Other services call Notify to tell a user about something that happened
+type NotificationService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(cfg *config.Config) *NotificationService {
+	return &NotificationService{
+		db:  database.GetDB(),
+		cfg: cfg,
+	}
+}
+
+// Message describes a notification to send
+type Message struct {
+	Type     string // info, warning, success, error
+	Category string // security, plan, progress, goal, ...
+	Title    string
+	Message  string
+	Link     string
+}
+
+// Notify stores a notification for a user
+func (s *NotificationService) Notify(userID uint, msg Message) (*models.Notification, error) {
+	if msg.Title == "" {
+		return nil, errors.New("notification title is required")
+	}
+	if msg.Type == "" {
+		msg.Type = "info"
+	}
+
+	notification := models.Notification{
+		UserID:   userID,
+		Type:     msg.Type,
+		Category: msg.Category,
+		Title:    msg.Title,
+		Message:  msg.Message,
+		Link:     msg.Link,
+	}
+
+	if err := s.db.Create(&notification).Error; err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// NotifyMany sends the same notification to several users
+func (s *NotificationService) NotifyMany(userIDs []uint, msg Message) error {
+	for _, userID := range userIDs {
+		if _, err := s.Notify(userID, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNotifications returns a user's most recent notifications
+func (s *NotificationService) GetNotifications(userID uint, unreadOnly bool, limit int) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+
+	query := s.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Order("created_at DESC").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// CountUnread returns the number of unread notifications for a user
+func (s *NotificationService) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks a single notification as read
+func (s *NotificationService) MarkRead(userID, notificationID uint) error {
+	result := s.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("notification not found")
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of a user as read
+func (s *NotificationService) MarkAllRead(userID uint) error {
+	return s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()}).Error
+}
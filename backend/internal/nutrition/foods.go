@@ -0,0 +1,62 @@
+package nutrition
+
+// defaultFoods seeds the food database on first start, nutrients per 100 g
+var defaultFoods = []FoodRequest{
+	// Protein
+	{Name: "Chicken Breast, cooked", Category: "protein", Calories: 165, Protein: 31, Fat: 3.6, Sodium: 74, ServingGrams: 120, ServingLabel: "1 breast fillet"},
+	{Name: "Turkey Breast, roasted", Category: "protein", Calories: 135, Protein: 30, Fat: 1, Sodium: 55, ServingGrams: 100, ServingLabel: "100 g"},
+	{Name: "Lean Beef Mince (5% fat), cooked", Category: "protein", Calories: 174, Protein: 26, Fat: 7.5, Sodium: 70, ServingGrams: 125, ServingLabel: "1 portion"},
+	{Name: "Salmon, baked", Category: "protein", Calories: 206, Protein: 22, Fat: 12.4, Sodium: 61, ServingGrams: 125, ServingLabel: "1 fillet", Allergens: []string{"fish"}},
+	{Name: "Tuna, canned in water", Category: "protein", Calories: 116, Protein: 26, Fat: 0.8, Sodium: 338, ServingGrams: 112, ServingLabel: "1 can, drained", Allergens: []string{"fish"}},
+	{Name: "Cod, baked", Category: "protein", Calories: 105, Protein: 23, Fat: 0.9, Sodium: 78, ServingGrams: 125, ServingLabel: "1 fillet", Allergens: []string{"fish"}},
+	{Name: "Egg, whole", Category: "protein", Calories: 143, Protein: 12.6, Carbs: 0.7, Fat: 9.5, Sugar: 0.4, Sodium: 142, ServingGrams: 50, ServingLabel: "1 large egg", Allergens: []string{"eggs"}},
+	{Name: "Egg White", Category: "protein", Calories: 52, Protein: 10.9, Carbs: 0.7, Fat: 0.2, Sugar: 0.7, Sodium: 166, ServingGrams: 33, ServingLabel: "1 large egg white", Allergens: []string{"eggs"}},
+	{Name: "Tofu, firm", Category: "protein", Calories: 144, Protein: 17.3, Carbs: 2.8, Fat: 8.7, Fiber: 2.3, Sodium: 14, ServingGrams: 100, ServingLabel: "100 g", Allergens: []string{"soy"}},
+	{Name: "Whey Protein Powder", Category: "protein", Calories: 390, Protein: 78, Carbs: 8, Fat: 6, Sugar: 5, Sodium: 200, ServingGrams: 30, ServingLabel: "1 scoop", Allergens: []string{"milk"}},
+
+	// Dairy
+	{Name: "Greek Yogurt, 0% fat", Category: "dairy", Calories: 59, Protein: 10.3, Carbs: 3.6, Fat: 0.4, Sugar: 3.2, Sodium: 36, ServingGrams: 170, ServingLabel: "1 pot", Allergens: []string{"milk"}},
+	{Name: "Milk, semi-skimmed", Category: "dairy", Calories: 50, Protein: 3.4, Carbs: 4.8, Fat: 1.8, Sugar: 4.8, Sodium: 44, ServingGrams: 250, ServingLabel: "1 glass", Allergens: []string{"milk"}},
+	{Name: "Cottage Cheese", Category: "dairy", Calories: 98, Protein: 11.1, Carbs: 3.4, Fat: 4.3, Sugar: 2.7, Sodium: 364, ServingGrams: 100, ServingLabel: "100 g", Allergens: []string{"milk"}},
+	{Name: "Cheddar Cheese", Category: "dairy", Calories: 403, Protein: 24.9, Carbs: 1.3, Fat: 33.1, Sugar: 0.5, Sodium: 621, ServingGrams: 30, ServingLabel: "1 slice", Allergens: []string{"milk"}},
+
+	// Grains
+	{Name: "Oats, rolled", Category: "grains", Calories: 379, Protein: 13.2, Carbs: 60, Fat: 6.5, Fiber: 10.1, Sugar: 1, Sodium: 6, ServingGrams: 40, ServingLabel: "1/2 cup", Allergens: []string{"gluten"}},
+	{Name: "White Rice, cooked", Category: "grains", Calories: 130, Protein: 2.7, Carbs: 28.2, Fat: 0.3, Fiber: 0.4, Sodium: 1, ServingGrams: 158, ServingLabel: "1 cup"},
+	{Name: "Brown Rice, cooked", Category: "grains", Calories: 123, Protein: 2.7, Carbs: 25.6, Fat: 1, Fiber: 1.6, Sodium: 4, ServingGrams: 195, ServingLabel: "1 cup"},
+	{Name: "Quinoa, cooked", Category: "grains", Calories: 120, Protein: 4.4, Carbs: 19.4, Fat: 1.9, Fiber: 2.8, Sugar: 0.9, Sodium: 7, ServingGrams: 185, ServingLabel: "1 cup"},
+	{Name: "Wholemeal Pasta, cooked", Category: "grains", Calories: 149, Protein: 5.8, Carbs: 26.5, Fat: 1.7, Fiber: 3.9, Sugar: 0.8, Sodium: 4, ServingGrams: 140, ServingLabel: "1 cup", Allergens: []string{"gluten"}},
+	{Name: "Wholemeal Bread", Category: "grains", Calories: 247, Protein: 13, Carbs: 34.9, Fat: 3.4, Fiber: 7, Sugar: 5.6, Sodium: 450, ServingGrams: 36, ServingLabel: "1 slice", Allergens: []string{"gluten"}},
+	{Name: "Sweet Potato, baked", Category: "vegetables", Calories: 90, Protein: 2, Carbs: 17.6, Fat: 0.2, Fiber: 3.3, Sugar: 6.5, Sodium: 36, ServingGrams: 150, ServingLabel: "1 medium"},
+	{Name: "Potato, boiled", Category: "vegetables", Calories: 87, Protein: 1.9, Carbs: 18.2, Fat: 0.1, Fiber: 1.8, Sugar: 0.9, Sodium: 4, ServingGrams: 170, ServingLabel: "1 medium"},
+
+	// Fruit
+	{Name: "Banana", Category: "fruit", Calories: 89, Protein: 1.1, Carbs: 20.2, Fat: 0.3, Fiber: 2.6, Sugar: 12.2, Sodium: 1, ServingGrams: 118, ServingLabel: "1 medium"},
+	{Name: "Apple", Category: "fruit", Calories: 52, Protein: 0.3, Carbs: 11.4, Fat: 0.2, Fiber: 2.4, Sugar: 10.4, Sodium: 1, ServingGrams: 182, ServingLabel: "1 medium"},
+	{Name: "Blueberries", Category: "fruit", Calories: 57, Protein: 0.7, Carbs: 12.1, Fat: 0.3, Fiber: 2.4, Sugar: 10, Sodium: 1, ServingGrams: 75, ServingLabel: "1/2 cup"},
+	{Name: "Orange", Category: "fruit", Calories: 47, Protein: 0.9, Carbs: 9.4, Fat: 0.1, Fiber: 2.4, Sugar: 9.4, ServingGrams: 131, ServingLabel: "1 medium"},
+
+	// Vegetables
+	{Name: "Broccoli, steamed", Category: "vegetables", Calories: 35, Protein: 2.4, Carbs: 4.1, Fat: 0.4, Fiber: 3.3, Sugar: 1.4, Sodium: 41, ServingGrams: 80, ServingLabel: "1 portion"},
+	{Name: "Spinach, raw", Category: "vegetables", Calories: 23, Protein: 2.9, Carbs: 1.4, Fat: 0.4, Fiber: 2.2, Sugar: 0.4, Sodium: 79, ServingGrams: 30, ServingLabel: "1 cup"},
+	{Name: "Carrot, raw", Category: "vegetables", Calories: 41, Protein: 0.9, Carbs: 7, Fat: 0.2, Fiber: 2.8, Sugar: 4.7, Sodium: 69, ServingGrams: 61, ServingLabel: "1 medium"},
+	{Name: "Tomato", Category: "vegetables", Calories: 18, Protein: 0.9, Carbs: 2.7, Fat: 0.2, Fiber: 1.2, Sugar: 2.6, Sodium: 5, ServingGrams: 123, ServingLabel: "1 medium"},
+	{Name: "Bell Pepper, red", Category: "vegetables", Calories: 31, Protein: 1, Carbs: 4, Fat: 0.3, Fiber: 2.1, Sugar: 4, Sodium: 4, ServingGrams: 120, ServingLabel: "1 medium"},
+
+	// Legumes
+	{Name: "Lentils, cooked", Category: "legumes", Calories: 116, Protein: 9, Carbs: 12.2, Fat: 0.4, Fiber: 7.9, Sugar: 1.8, Sodium: 2, ServingGrams: 100, ServingLabel: "1/2 cup"},
+	{Name: "Chickpeas, cooked", Category: "legumes", Calories: 164, Protein: 8.9, Carbs: 19.7, Fat: 2.6, Fiber: 7.6, Sugar: 4.8, Sodium: 7, ServingGrams: 82, ServingLabel: "1/2 cup"},
+	{Name: "Black Beans, cooked", Category: "legumes", Calories: 132, Protein: 8.9, Carbs: 15.0, Fat: 0.5, Fiber: 8.7, Sugar: 0.3, Sodium: 1, ServingGrams: 86, ServingLabel: "1/2 cup"},
+
+	// Fats
+	{Name: "Olive Oil", Category: "fats", Calories: 884, Fat: 100, Sodium: 2, ServingGrams: 14, ServingLabel: "1 tbsp"},
+	{Name: "Avocado", Category: "fats", Calories: 160, Protein: 2, Carbs: 2.1, Fat: 14.7, Fiber: 6.7, Sugar: 0.7, Sodium: 7, ServingGrams: 100, ServingLabel: "1/2 avocado"},
+	{Name: "Almonds", Category: "fats", Calories: 579, Protein: 21.2, Carbs: 9.1, Fat: 49.9, Fiber: 12.5, Sugar: 4.4, Sodium: 1, ServingGrams: 28, ServingLabel: "1 handful", Allergens: []string{"tree_nuts"}},
+	{Name: "Peanut Butter", Category: "fats", Calories: 588, Protein: 25.1, Carbs: 14.2, Fat: 50.4, Fiber: 6, Sugar: 9.2, Sodium: 429, ServingGrams: 16, ServingLabel: "1 tbsp", Allergens: []string{"peanuts"}},
+
+	// Snacks and beverages
+	{Name: "Dark Chocolate (70-85%)", Category: "snacks", Calories: 598, Protein: 7.8, Carbs: 34.9, Fat: 42.6, Fiber: 10.9, Sugar: 24, Sodium: 20, ServingGrams: 20, ServingLabel: "2 squares", Allergens: []string{"milk", "soy"}},
+	{Name: "Rice Cake", Category: "snacks", Calories: 387, Protein: 8.2, Carbs: 80.9, Fat: 2.8, Fiber: 4.2, Sugar: 0.9, Sodium: 6, ServingGrams: 9, ServingLabel: "1 cake"},
+	{Name: "Orange Juice", Category: "beverages", Calories: 45, Protein: 0.7, Carbs: 10.4, Fat: 0.2, Fiber: 0.2, Sugar: 8.4, Sodium: 1, ServingGrams: 250, ServingLabel: "1 glass"},
+	{Name: "Coffee, black", Category: "beverages", Calories: 1, Protein: 0.1, Sodium: 2, ServingGrams: 240, ServingLabel: "1 cup"},
+}
@@ -0,0 +1,759 @@
+package nutrition
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/auth"
+	"fittrackplus/internal/common/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NutritionHandler handles food database and meal logging HTTP requests
+type NutritionHandler struct {
+	nutritionService *NutritionService
+}
+
+// NewNutritionHandler creates a new nutrition handler
+func NewNutritionHandler(cfg *config.Config) *NutritionHandler {
+	return &NutritionHandler{
+		nutritionService: NewNutritionService(cfg),
+	}
+}
+
+// SearchFoods godoc
+// @Summary Search the food database
+// @Description Search shared foods and the current user's custom foods by name or brand. Nutrients are per 100 g
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Name or brand search"
+// @Param category query string false "Food category"
+// @Success 200 {object} map[string]interface{} "Matching foods"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /nutrition/foods [get]
+func (h *NutritionHandler) SearchFoods(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	filter := FoodFilter{
+		Query:    c.Query("q"),
+		Category: c.Query("category"),
+	}
+	foods, err := h.nutritionService.SearchFoods(filter, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to search foods",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"foods": foods,
+		"total": len(foods),
+	})
+}
+
+// GetFoodCategories godoc
+// @Summary Get nutrition vocabulary
+// @Description Get the food categories and the meals entries can be logged for
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Categories and meals"
+// @Router /nutrition/foods/categories [get]
+func (h *NutritionHandler) GetFoodCategories(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"categories": FoodCategories,
+		"meals":      Meals,
+	})
+}
+
+// GetFood godoc
+// @Summary Get a food
+// @Description Get a food with its nutrients per 100 g and default serving
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Food ID"
+// @Success 200 {object} models.Food
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Food not found"
+// @Router /nutrition/foods/{id} [get]
+func (h *NutritionHandler) GetFood(c *gin.Context) {
+	foodID, userID, ok := idParams(c, "food")
+	if !ok {
+		return
+	}
+
+	food, err := h.nutritionService.GetFood(foodID, userID)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to get food",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, food)
+}
+
+// CreateFood godoc
+// @Summary Add a food
+// @Description Add a food with nutrients per 100 g. Admin and trainer entries are shared with everyone; member entries are private custom foods
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param food body FoodRequest true "Food details"
+// @Success 201 {object} models.Food
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Food already exists"
+// @Router /nutrition/foods [post]
+func (h *NutritionHandler) CreateFood(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	var req FoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	food, err := h.nutritionService.CreateFood(&req, userID, userRole)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to create food",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, food)
+}
+
+// UpdateFood godoc
+// @Summary Update a food
+// @Description Update a food you added (admins can update any). Meals already logged keep their nutrients
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Food ID"
+// @Param food body FoodRequest true "Food details"
+// @Success 200 {object} models.Food
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your food"
+// @Failure 404 {object} map[string]interface{} "Food not found"
+// @Failure 409 {object} map[string]interface{} "Food already exists"
+// @Router /nutrition/foods/{id} [put]
+func (h *NutritionHandler) UpdateFood(c *gin.Context) {
+	foodID, userID, ok := idParams(c, "food")
+	if !ok {
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	var req FoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	food, err := h.nutritionService.UpdateFood(foodID, &req, userID, userRole)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to update food",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, food)
+}
+
+// DeleteFood godoc
+// @Summary Delete a food
+// @Description Delete a food you added (admins can delete any). Meals already logged keep its name and nutrients
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Food ID"
+// @Success 200 {object} map[string]interface{} "Food deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your food"
+// @Failure 404 {object} map[string]interface{} "Food not found"
+// @Router /nutrition/foods/{id} [delete]
+func (h *NutritionHandler) DeleteFood(c *gin.Context) {
+	foodID, userID, ok := idParams(c, "food")
+	if !ok {
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	if err := h.nutritionService.DeleteFood(foodID, userID, userRole); err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to delete food",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Food deleted successfully",
+	})
+}
+
+// LogMeal godoc
+// @Summary Log food for a meal
+// @Description Log a portion of a food or recipe for breakfast, lunch, dinner or a snack on a day. Give the portion in grams or in servings; nutrients are calculated from the food's values per 100 g or the recipe's values per serving
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param entry body MealEntryRequest true "Meal entry"
+// @Success 201 {object} models.MealEntry
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Food or recipe not found"
+// @Router /nutrition/meals [post]
+func (h *NutritionHandler) LogMeal(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	var req MealEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.nutritionService.LogMeal(userID, &req)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to log meal",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateMealEntry godoc
+// @Summary Edit a meal entry
+// @Description Replace the food, portion, meal or date of one of the current user's entries
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Meal entry ID"
+// @Param entry body MealEntryRequest true "Meal entry"
+// @Success 200 {object} models.MealEntry
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Meal entry not found"
+// @Router /nutrition/meals/{id} [put]
+func (h *NutritionHandler) UpdateMealEntry(c *gin.Context) {
+	entryID, userID, ok := idParams(c, "meal entry")
+	if !ok {
+		return
+	}
+
+	var req MealEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.nutritionService.UpdateMealEntry(entryID, userID, &req)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to update meal entry",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteMealEntry godoc
+// @Summary Delete a meal entry
+// @Description Delete one of the current user's meal entries
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Meal entry ID"
+// @Success 200 {object} map[string]interface{} "Meal entry deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Meal entry not found"
+// @Router /nutrition/meals/{id} [delete]
+func (h *NutritionHandler) DeleteMealEntry(c *gin.Context) {
+	entryID, userID, ok := idParams(c, "meal entry")
+	if !ok {
+		return
+	}
+
+	if err := h.nutritionService.DeleteMealEntry(entryID, userID); err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to delete meal entry",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Meal entry deleted successfully",
+	})
+}
+
+// GetDay godoc
+// @Summary Get a day's nutrition
+// @Description Get the meals logged on a day with per-meal and daily calorie and macro totals, the member's current targets and, for today, what remains. Trainers can pass user_id for the members they assigned plans to
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date path string true "Day (YYYY-MM-DD)"
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Success 200 {object} DailyNutrition
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /nutrition/days/{date} [get]
+func (h *NutritionHandler) GetDay(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+
+	day, err := h.nutritionService.GetDay(memberID, userID, userRole, c.Param("date"))
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to get nutrition for the day",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, day)
+}
+
+// GetTargets godoc
+// @Summary Get nutrition targets
+// @Description Derive daily calorie, protein, carb, fat and fiber targets from TDEE and the weight goal (an active weight goal, else the profile's target weight). Trainers can pass user_id for the members they assigned plans to
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Success 200 {object} Targets
+// @Failure 400 {object} map[string]interface{} "Bad request - profile incomplete"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /nutrition/targets [get]
+func (h *NutritionHandler) GetTargets(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+
+	targets, err := h.nutritionService.GetTargets(memberID, userID, userRole)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to get nutrition targets",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+// GetAdherence godoc
+// @Summary Get nutrition adherence
+// @Description Compare each day's intake with the current targets. A logged day is on target when calories are within 10% of the target and protein reaches 90% of it. Defaults to the last 7 days. Trainers can pass user_id for the members they assigned plans to
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Success 200 {object} Adherence
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /nutrition/adherence [get]
+func (h *NutritionHandler) GetAdherence(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+	from, to, ok := dateRangeParams(c)
+	if !ok {
+		return
+	}
+
+	adherence, err := h.nutritionService.GetAdherence(memberID, userID, userRole, from, to)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to get nutrition adherence",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, adherence)
+}
+
+// idParams reads the caller and the path ID, writing an error response when either is missing
+func idParams(c *gin.Context, name string) (id, userID uint, ok bool) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return 0, 0, false
+	}
+
+	value, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid " + name + " ID",
+		})
+		return 0, 0, false
+	}
+
+	return uint(value), userID, true
+}
+
+// memberParam reads the optional user_id query parameter, defaulting to the caller
+func memberParam(c *gin.Context, userID uint) (uint, bool) {
+	value := c.Query("user_id")
+	if value == "" {
+		return userID, true
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// dateRangeParams reads the optional from and to query dates; to includes the whole day
+func dateRangeParams(c *gin.Context) (from, to *time.Time, ok bool) {
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from date, expected YYYY-MM-DD",
+			})
+			return nil, nil, false
+		}
+		from = &date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid to date, expected YYYY-MM-DD",
+			})
+			return nil, nil, false
+		}
+		date = date.AddDate(0, 0, 1)
+		to = &date
+	}
+	return from, to, true
+}
+
+func nutritionErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "you can only"):
+		return http.StatusForbidden
+	case strings.Contains(err.Error(), "already exists"):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// SearchRecipes godoc
+// @Summary Search recipes
+// @Description Search shared recipes and the current user's own recipes by name, description or tag. Nutrition is per serving and for the servings the recipe is written for
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Name or description search"
+// @Param tag query string false "Recipe tag"
+// @Param mine query bool false "Only recipes I added"
+// @Success 200 {object} map[string]interface{} "Matching recipes"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /nutrition/recipes [get]
+func (h *NutritionHandler) SearchRecipes(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	filter := RecipeFilter{
+		Query: c.Query("q"),
+		Tag:   c.Query("tag"),
+		Mine:  c.Query("mine") == "true",
+	}
+	recipes, err := h.nutritionService.SearchRecipes(filter, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to search recipes",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipes": recipes,
+		"total":   len(recipes),
+	})
+}
+
+// GetRecipe godoc
+// @Summary Get a recipe
+// @Description Get a recipe with its ingredients, steps and nutrition. Pass servings to scale the ingredient quantities and total nutrition
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Recipe ID"
+// @Param servings query number false "Servings to scale to (defaults to the recipe's servings)"
+// @Success 200 {object} RecipeResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Recipe not found"
+// @Router /nutrition/recipes/{id} [get]
+func (h *NutritionHandler) GetRecipe(c *gin.Context) {
+	recipeID, userID, ok := idParams(c, "recipe")
+	if !ok {
+		return
+	}
+
+	var servings float64
+	if value := c.Query("servings"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid servings",
+			})
+			return
+		}
+		servings = parsed
+	}
+
+	recipe, err := h.nutritionService.GetRecipe(recipeID, userID, servings)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to get recipe",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// CreateRecipe godoc
+// @Summary Add a recipe
+// @Description Add a recipe from foods in the food database. Nutrition per serving is computed from the ingredients. Admin and trainer recipes are shared with everyone and can be used in diet plans; member recipes are private
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param recipe body RecipeRequest true "Recipe details"
+// @Success 201 {object} RecipeResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Ingredient food not found"
+// @Failure 409 {object} map[string]interface{} "Recipe already exists"
+// @Router /nutrition/recipes [post]
+func (h *NutritionHandler) CreateRecipe(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	var req RecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recipe, err := h.nutritionService.CreateRecipe(&req, userID, userRole)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to create recipe",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, recipe)
+}
+
+// UpdateRecipe godoc
+// @Summary Update a recipe
+// @Description Update a recipe you added (admins can update any) and recompute its nutrition. Diet plans and meals already logged keep their nutrients
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Recipe ID"
+// @Param recipe body RecipeRequest true "Recipe details"
+// @Success 200 {object} RecipeResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your recipe"
+// @Failure 404 {object} map[string]interface{} "Recipe not found"
+// @Failure 409 {object} map[string]interface{} "Recipe already exists"
+// @Router /nutrition/recipes/{id} [put]
+func (h *NutritionHandler) UpdateRecipe(c *gin.Context) {
+	recipeID, userID, ok := idParams(c, "recipe")
+	if !ok {
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	var req RecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recipe, err := h.nutritionService.UpdateRecipe(recipeID, &req, userID, userRole)
+	if err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to update recipe",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// DeleteRecipe godoc
+// @Summary Delete a recipe
+// @Description Delete a recipe you added (admins can delete any). Diet plans and meals already logged keep its name and nutrients
+// @Tags Nutrition
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Recipe ID"
+// @Success 200 {object} map[string]interface{} "Recipe deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your recipe"
+// @Failure 404 {object} map[string]interface{} "Recipe not found"
+// @Router /nutrition/recipes/{id} [delete]
+func (h *NutritionHandler) DeleteRecipe(c *gin.Context) {
+	recipeID, userID, ok := idParams(c, "recipe")
+	if !ok {
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	if err := h.nutritionService.DeleteRecipe(recipeID, userID, userRole); err != nil {
+		c.JSON(nutritionErrorStatus(err), gin.H{
+			"error":   "Failed to delete recipe",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recipe deleted successfully",
+	})
+}
@@ -0,0 +1,517 @@
+package nutrition
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+	"fittrackplus/internal/metrics"
+	"fittrackplus/internal/plan"
+
+	"gorm.io/gorm"
+)
+
+// NutritionService handles the food database, meal logging and nutrition targets
+type NutritionService struct {
+	db             *gorm.DB
+	cfg            *config.Config
+	metricsService *metrics.MetricsService
+}
+
+// NewNutritionService creates a new nutrition service
+func NewNutritionService(cfg *config.Config) *NutritionService {
+	return &NutritionService{
+		db:             database.GetDB(),
+		cfg:            cfg,
+		metricsService: metrics.NewMetricsService(cfg),
+	}
+}
+
+// Meals lists the meals a day's entries are grouped into, in order
+var Meals = []string{"breakfast", "lunch", "dinner", "snack"}
+
+// FoodCategories is the controlled vocabulary for food categories
+var FoodCategories = []string{"protein", "dairy", "grains", "fruit", "vegetables", "legumes", "fats", "snacks", "beverages", "other"}
+
+// FoodRequest represents a food creation/update request, nutrients per 100 g
+type FoodRequest struct {
+	Name         string   `json:"name" binding:"required,max=200"`
+	Brand        string   `json:"brand" binding:"max=100"`
+	Category     string   `json:"category" binding:"required"`
+	Calories     float64  `json:"calories" binding:"gte=0,lte=900"`
+	Protein      float64  `json:"protein" binding:"gte=0,lte=100"`
+	Carbs        float64  `json:"carbs" binding:"gte=0,lte=100"`
+	Fat          float64  `json:"fat" binding:"gte=0,lte=100"`
+	Fiber        float64  `json:"fiber" binding:"gte=0,lte=100"`
+	Sugar        float64  `json:"sugar" binding:"gte=0,lte=100"`
+	Sodium       float64  `json:"sodium" binding:"gte=0,lte=40000"` // mg
+	ServingGrams float64  `json:"serving_grams" binding:"gte=0,lte=2000"`
+	ServingLabel string   `json:"serving_label" binding:"max=100"`
+	Allergens    []string `json:"allergens"` // e.g. milk, gluten; see plan.Allergens
+}
+
+// FoodFilter holds the food search parameters
+type FoodFilter struct {
+	Query    string // matches name or brand
+	Category string
+}
+
+// MealEntryRequest logs a portion of a food or recipe, given in grams or in servings
+type MealEntryRequest struct {
+	Date     string   `json:"date" binding:"required"` // YYYY-MM-DD
+	Meal     string   `json:"meal" binding:"required,oneof=breakfast lunch dinner snack"`
+	FoodID   uint     `json:"food_id"`
+	RecipeID uint     `json:"recipe_id"` // instead of food_id
+	Grams    *float64 `json:"grams" binding:"omitempty,gt=0,lte=5000"`
+	Servings *float64 `json:"servings" binding:"omitempty,gt=0,lte=50"`
+	Notes    string   `json:"notes" binding:"max=500"`
+}
+
+// Nutrients are calorie and macro amounts
+type Nutrients struct {
+	Calories float64 `json:"calories"` // kcal
+	Protein  float64 `json:"protein"`  // g
+	Carbs    float64 `json:"carbs"`    // g
+	Fat      float64 `json:"fat"`      // g
+	Fiber    float64 `json:"fiber"`    // g
+}
+
+// MealSummary is one meal of a day with its entries and totals
+type MealSummary struct {
+	Meal    string             `json:"meal"`
+	Entries []models.MealEntry `json:"entries"`
+	Totals  Nutrients          `json:"totals"`
+}
+
+// DailyNutrition is a member's food log for one day against their targets
+type DailyNutrition struct {
+	UserID         uint          `json:"user_id"`
+	Date           string        `json:"date"`
+	Meals          []MealSummary `json:"meals"`
+	Totals         Nutrients     `json:"totals"`
+	Targets        *Targets      `json:"targets,omitempty"`         // current targets, not those in effect on past days
+	Remaining      *Nutrients    `json:"remaining,omitempty"`       // targets minus totals, negative when over; omitted for past days
+	TargetsMissing []string      `json:"targets_missing,omitempty"` // profile data needed before targets can be derived
+}
+
+// SearchFoods searches the shared food database and the user's own custom foods
+func (s *NutritionService) SearchFoods(filter FoodFilter, userID uint) ([]models.Food, error) {
+	query := s.db.Where("is_private = ? OR created_by = ?", false, userID)
+	if q := strings.ToLower(strings.TrimSpace(filter.Query)); q != "" {
+		like := "%" + q + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(brand) LIKE ?", like, like)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+
+	foods := []models.Food{}
+	if err := query.Order("name, brand").Find(&foods).Error; err != nil {
+		return nil, err
+	}
+	return foods, nil
+}
+
+// GetFood retrieves a food visible to the user
+func (s *NutritionService) GetFood(foodID, userID uint) (*models.Food, error) {
+	var food models.Food
+	if err := s.db.First(&food, foodID).Error; err != nil {
+		return nil, errors.New("food not found")
+	}
+	if food.IsPrivate && (food.CreatedBy == nil || *food.CreatedBy != userID) {
+		return nil, errors.New("food not found")
+	}
+	return &food, nil
+}
+
+// CreateFood adds a food to the database
+// Admin and trainer entries are shared; members' entries stay private to them
+func (s *NutritionService) CreateFood(req *FoodRequest, userID uint, userRole string) (*models.Food, error) {
+	if err := validateFoodRequest(req); err != nil {
+		return nil, err
+	}
+
+	food := models.Food{
+		CreatedBy: &userID,
+		IsPrivate: userRole != "admin" && userRole != "trainer",
+	}
+	applyFoodRequest(&food, req)
+	if err := s.ensureUniqueFood(&food); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&food).Error; err != nil {
+		return nil, err
+	}
+	return &food, nil
+}
+
+// UpdateFood edits a food; logged meals keep the nutrients they were saved with
+func (s *NutritionService) UpdateFood(foodID uint, req *FoodRequest, userID uint, userRole string) (*models.Food, error) {
+	food, err := s.findEditableFood(foodID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateFoodRequest(req); err != nil {
+		return nil, err
+	}
+
+	applyFoodRequest(food, req)
+	if err := s.ensureUniqueFood(food); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Save(food).Error; err != nil {
+		return nil, err
+	}
+	return food, nil
+}
+
+// DeleteFood removes a food; logged meals keep its name and nutrients
+func (s *NutritionService) DeleteFood(foodID, userID uint, userRole string) error {
+	food, err := s.findEditableFood(foodID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(food).Error
+}
+
+// SeedDefaultFoods fills an empty food database with common foods
+func (s *NutritionService) SeedDefaultFoods() error {
+	var count int64
+	if err := s.db.Model(&models.Food{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return s.backfillAllergens()
+	}
+
+	for i := range defaultFoods {
+		var food models.Food
+		applyFoodRequest(&food, &defaultFoods[i])
+		if err := s.db.Create(&food).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillAllergens tags seeded foods from before allergens were tracked
+func (s *NutritionService) backfillAllergens() error {
+	for i := range defaultFoods {
+		if len(defaultFoods[i].Allergens) == 0 {
+			continue
+		}
+		err := s.db.Model(&models.Food{}).
+			Where("name = ? AND created_by IS NULL AND (allergens = '' OR allergens IS NULL)", defaultFoods[i].Name).
+			Update("allergens", encodeList(defaultFoods[i].Allergens)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogMeal logs a portion of food for one of the member's meals
+func (s *NutritionService) LogMeal(userID uint, req *MealEntryRequest) (*models.MealEntry, error) {
+	entry := models.MealEntry{UserID: userID}
+	if err := s.applyMealEntryRequest(&entry, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// UpdateMealEntry changes the food, portion, meal or date of one of the member's entries
+func (s *NutritionService) UpdateMealEntry(entryID, userID uint, req *MealEntryRequest) (*models.MealEntry, error) {
+	entry, err := s.findOwnEntry(entryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyMealEntryRequest(entry, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(entry).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// DeleteMealEntry deletes one of the member's entries
+func (s *NutritionService) DeleteMealEntry(entryID, userID uint) error {
+	entry, err := s.findOwnEntry(entryID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(entry).Error
+}
+
+// GetDay returns a member's meals and totals for a day against their targets
+func (s *NutritionService) GetDay(memberID, viewerID uint, viewerRole, date string) (*DailyNutrition, error) {
+	if err := s.canViewNutrition(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	day, err := parseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.MealEntry
+	if err := s.db.Where("user_id = ? AND date = ?", memberID, day).Order("created_at, id").Find(&entries).Error; err != nil {
+		return nil, errors.New("failed to load meals")
+	}
+
+	daily := buildDailyNutrition(entries)
+	daily.UserID = memberID
+	daily.Date = day.Format("2006-01-02")
+
+	// Targets are derived from the member's current metrics and goal, so what remains
+	// is only meaningful for today (allowing a day behind UTC for members west of it)
+	now := time.Now()
+	targets, missing, err := s.loadTargets(memberID, now)
+	if err != nil {
+		return nil, err
+	}
+	if targets != nil {
+		daily.Targets = targets
+		if !day.Before(utils.DateOnly(now).AddDate(0, 0, -1)) {
+			remaining := subtract(targets.Nutrients, daily.Totals)
+			daily.Remaining = &remaining
+		}
+	}
+	daily.TargetsMissing = missing
+	return daily, nil
+}
+
+func (s *NutritionService) applyMealEntryRequest(entry *models.MealEntry, req *MealEntryRequest) error {
+	date, err := parseDate(req.Date)
+	if err != nil {
+		return err
+	}
+	// Allow a day ahead for members in time zones past UTC
+	if date.After(time.Now().AddDate(0, 0, 1)) {
+		return errors.New("meals cannot be logged for future dates")
+	}
+	if (req.FoodID == 0) == (req.RecipeID == 0) {
+		return errors.New("give either food_id or recipe_id")
+	}
+
+	var food *models.Food
+	entry.FoodID, entry.RecipeID = nil, nil
+	if req.RecipeID != 0 {
+		// Recipes are logged like a food, one serving being the recipe's serving weight
+		recipe, err := s.findRecipe(req.RecipeID, entry.UserID)
+		if err != nil {
+			return err
+		}
+		food = plan.RecipeFood(recipe)
+		entry.RecipeID = &recipe.ID
+	} else {
+		if food, err = s.GetFood(req.FoodID, entry.UserID); err != nil {
+			return err
+		}
+		entry.FoodID = &food.ID
+	}
+	grams, err := portionGrams(food, req.Grams, req.Servings)
+	if err != nil {
+		return err
+	}
+
+	nutrients := scaleFood(food, grams)
+	entry.Date = date
+	entry.Meal = req.Meal
+	entry.FoodName = food.Name
+	entry.Grams = grams
+	entry.Calories = nutrients.Calories
+	entry.Protein = nutrients.Protein
+	entry.Carbs = nutrients.Carbs
+	entry.Fat = nutrients.Fat
+	entry.Fiber = nutrients.Fiber
+	entry.Notes = strings.TrimSpace(req.Notes)
+	return nil
+}
+
+// canViewNutrition checks that the viewer may see a member's food log
+func (s *NutritionService) canViewNutrition(memberID, viewerID uint, viewerRole string) error {
+	switch {
+	case memberID == viewerID, viewerRole == "admin":
+		return nil
+	case viewerRole == "trainer" && plan.IsAssignedTrainer(s.db, viewerID, memberID):
+		return nil
+	default:
+		return errors.New("you can only view your own nutrition or that of your clients")
+	}
+}
+
+func (s *NutritionService) findEditableFood(foodID, userID uint, userRole string) (*models.Food, error) {
+	food, err := s.GetFood(foodID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userRole != "admin" && (food.CreatedBy == nil || *food.CreatedBy != userID) {
+		return nil, errors.New("you can only change foods you added")
+	}
+	return food, nil
+}
+
+func (s *NutritionService) findOwnEntry(entryID, userID uint) (*models.MealEntry, error) {
+	var entry models.MealEntry
+	if err := s.db.Where("id = ? AND user_id = ?", entryID, userID).First(&entry).Error; err != nil {
+		return nil, errors.New("meal entry not found")
+	}
+	return &entry, nil
+}
+
+// ensureUniqueFood rejects a second food with the same name and brand among the shared
+// foods, or among the creator's own custom foods
+func (s *NutritionService) ensureUniqueFood(food *models.Food) error {
+	query := s.db.Model(&models.Food{}).
+		Where("LOWER(name) = ? AND LOWER(brand) = ? AND id <> ?", strings.ToLower(food.Name), strings.ToLower(food.Brand), food.ID)
+	if food.IsPrivate {
+		query = query.Where("is_private = ? AND created_by = ?", true, *food.CreatedBy)
+	} else {
+		query = query.Where("is_private = ?", false)
+	}
+
+	var count int64
+	query.Count(&count)
+	if count > 0 {
+		return errors.New("a food with this name and brand already exists")
+	}
+	return nil
+}
+
+func validateFoodRequest(req *FoodRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	if !contains(FoodCategories, req.Category) {
+		return fmt.Errorf("invalid category %q, expected one of %s", req.Category, strings.Join(FoodCategories, ", "))
+	}
+	if req.Protein+req.Carbs+req.Fat+req.Fiber > 100 {
+		return errors.New("protein, carbs, fat and fiber cannot add up to more than 100 g per 100 g")
+	}
+	if req.Sugar > req.Carbs {
+		return errors.New("sugar cannot exceed carbs")
+	}
+	for i, allergen := range req.Allergens {
+		req.Allergens[i] = strings.ToLower(strings.TrimSpace(allergen))
+		if !contains(plan.Allergens, req.Allergens[i]) {
+			return fmt.Errorf("invalid allergen %q, expected one of %s", allergen, strings.Join(plan.Allergens, ", "))
+		}
+	}
+	return nil
+}
+
+func applyFoodRequest(food *models.Food, req *FoodRequest) {
+	food.Name = strings.TrimSpace(req.Name)
+	food.Brand = strings.TrimSpace(req.Brand)
+	food.Category = req.Category
+	food.Calories = req.Calories
+	food.Protein = req.Protein
+	food.Carbs = req.Carbs
+	food.Fat = req.Fat
+	food.Fiber = req.Fiber
+	food.Sugar = req.Sugar
+	food.Sodium = req.Sodium
+	food.ServingGrams = req.ServingGrams
+	food.ServingLabel = strings.TrimSpace(req.ServingLabel)
+	food.Allergens = encodeList(req.Allergens)
+}
+
+// portionGrams resolves a portion given in grams or in the food's servings
+func portionGrams(food *models.Food, grams, servings *float64) (float64, error) {
+	switch {
+	case grams != nil && servings != nil:
+		return 0, errors.New("give the portion in grams or servings, not both")
+	case grams != nil:
+		return *grams, nil
+	case servings != nil:
+		if food.ServingGrams <= 0 {
+			return 0, fmt.Errorf("%s has no serving size, give the portion in grams", food.Name)
+		}
+		return utils.Round(*servings*food.ServingGrams, 1), nil
+	default:
+		return 0, errors.New("a portion in grams or servings is required")
+	}
+}
+
+// scaleFood returns the nutrients in a portion of a food
+func scaleFood(food *models.Food, grams float64) Nutrients {
+	factor := grams / 100
+	return Nutrients{
+		Calories: utils.Round(food.Calories*factor, 0),
+		Protein:  utils.Round(food.Protein*factor, 1),
+		Carbs:    utils.Round(food.Carbs*factor, 1),
+		Fat:      utils.Round(food.Fat*factor, 1),
+		Fiber:    utils.Round(food.Fiber*factor, 1),
+	}
+}
+
+// buildDailyNutrition groups a day's entries by meal and totals them
+func buildDailyNutrition(entries []models.MealEntry) *DailyNutrition {
+	daily := &DailyNutrition{Meals: []MealSummary{}}
+	for _, meal := range Meals {
+		summary := MealSummary{Meal: meal, Entries: []models.MealEntry{}}
+		for _, entry := range entries {
+			if entry.Meal == meal {
+				summary.Entries = append(summary.Entries, entry)
+				summary.Totals = add(summary.Totals, entryNutrients(&entry))
+			}
+		}
+		daily.Meals = append(daily.Meals, summary)
+		daily.Totals = add(daily.Totals, summary.Totals)
+	}
+	return daily
+}
+
+func entryNutrients(entry *models.MealEntry) Nutrients {
+	return Nutrients{Calories: entry.Calories, Protein: entry.Protein, Carbs: entry.Carbs, Fat: entry.Fat, Fiber: entry.Fiber}
+}
+
+func add(a, b Nutrients) Nutrients {
+	return Nutrients{
+		Calories: utils.Round(a.Calories+b.Calories, 0),
+		Protein:  utils.Round(a.Protein+b.Protein, 1),
+		Carbs:    utils.Round(a.Carbs+b.Carbs, 1),
+		Fat:      utils.Round(a.Fat+b.Fat, 1),
+		Fiber:    utils.Round(a.Fiber+b.Fiber, 1),
+	}
+}
+
+func subtract(a, b Nutrients) Nutrients {
+	return add(a, Nutrients{Calories: -b.Calories, Protein: -b.Protein, Carbs: -b.Carbs, Fat: -b.Fat, Fiber: -b.Fiber})
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New("invalid date, expected YYYY-MM-DD")
+	}
+	return date, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeList(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
@@ -0,0 +1,177 @@
+package nutrition
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestPortionAndScaling(t *testing.T) {
+	egg := &models.Food{Name: "Egg", Calories: 143, Protein: 12.6, Carbs: 0.7, Fat: 9.5, ServingGrams: 50}
+	grams, servings := 150.0, 2.0
+
+	if _, err := portionGrams(egg, &grams, &servings); err == nil {
+		t.Error("expected grams and servings together to be rejected")
+	}
+	if _, err := portionGrams(egg, nil, nil); err == nil {
+		t.Error("expected a missing portion to be rejected")
+	}
+	if _, err := portionGrams(&models.Food{Name: "Soup"}, nil, &servings); err == nil {
+		t.Error("expected servings of a food without a serving size to be rejected")
+	}
+
+	portion, err := portionGrams(egg, nil, &servings)
+	if err != nil || portion != 100 {
+		t.Fatalf("expected 2 eggs to be 100 g, got %v (%v)", portion, err)
+	}
+	nutrients := scaleFood(egg, 150)
+	if nutrients.Calories != 215 || nutrients.Protein != 18.9 || nutrients.Fat != 14.3 {
+		t.Errorf("unexpected nutrients %+v", nutrients)
+	}
+}
+
+func TestValidateFoodRequest(t *testing.T) {
+	if err := validateFoodRequest(&FoodRequest{Name: "Bar", Category: "candy"}); err == nil {
+		t.Error("expected an unknown category to be rejected")
+	}
+	if err := validateFoodRequest(&FoodRequest{Name: "Bar", Category: "snacks", Protein: 40, Carbs: 50, Fat: 20}); err == nil {
+		t.Error("expected macros above 100 g per 100 g to be rejected")
+	}
+	if err := validateFoodRequest(&FoodRequest{Name: "Bar", Category: "snacks", Carbs: 10, Sugar: 12}); err == nil {
+		t.Error("expected sugar above carbs to be rejected")
+	}
+	if err := validateFoodRequest(&FoodRequest{Name: "Bar", Category: "snacks", Allergens: []string{"chocolate"}}); err == nil {
+		t.Error("expected an unknown allergen to be rejected")
+	}
+	if err := validateFoodRequest(&defaultFoods[0]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDefaultFoodsAreValid(t *testing.T) {
+	for i := range defaultFoods {
+		if err := validateFoodRequest(&defaultFoods[i]); err != nil {
+			t.Errorf("%s: %v", defaultFoods[i].Name, err)
+		}
+	}
+}
+
+func TestBuildDailyNutrition(t *testing.T) {
+	entries := []models.MealEntry{
+		{Meal: "dinner", Calories: 600, Protein: 40, Carbs: 60, Fat: 20},
+		{Meal: "breakfast", Calories: 300, Protein: 20.5, Carbs: 40, Fat: 6},
+		{Meal: "breakfast", Calories: 100, Protein: 0.5, Carbs: 25, Fat: 0.2, Fiber: 3},
+	}
+	daily := buildDailyNutrition(entries)
+	if len(daily.Meals) != 4 || daily.Meals[0].Meal != "breakfast" || len(daily.Meals[0].Entries) != 2 || len(daily.Meals[1].Entries) != 0 {
+		t.Fatalf("expected entries grouped by meal in order, got %+v", daily.Meals)
+	}
+	if daily.Meals[0].Totals.Calories != 400 || daily.Meals[0].Totals.Protein != 21 {
+		t.Errorf("unexpected breakfast totals %+v", daily.Meals[0].Totals)
+	}
+	if daily.Totals.Calories != 1000 || daily.Totals.Carbs != 125 || daily.Totals.Fat != 26.2 || daily.Totals.Fiber != 3 {
+		t.Errorf("unexpected daily totals %+v", daily.Totals)
+	}
+}
+
+func TestComputeTargets(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	maintain := computeTargets(2500, 1700, 80, 0, nil, now)
+	if maintain.Goal != GoalMaintain || maintain.Calories != 2500 || maintain.Protein != 128 || maintain.Fat != 69 {
+		t.Errorf("unexpected maintenance targets %+v", maintain)
+	}
+	if maintain.Carbs != 342 || maintain.Fiber != 35 {
+		t.Errorf("expected carbs to fill the remaining calories, got %+v", maintain)
+	}
+
+	lose := computeTargets(2500, 1700, 80, 75, nil, now)
+	if lose.Goal != GoalLose || lose.Calories != 2000 || lose.Adjustment != -500 || lose.Protein != 160 {
+		t.Errorf("unexpected weight loss targets %+v", lose)
+	}
+
+	// 10 kg in 4 weeks would need ~2750 kcal/day, capped at 1000 and floored at BMR
+	deadline := now.AddDate(0, 0, 28)
+	aggressive := computeTargets(2300, 1700, 80, 70, &deadline, now)
+	if aggressive.Calories != 1700 {
+		t.Errorf("expected calories floored at BMR, got %+v", aggressive)
+	}
+
+	// 2 kg in 20 weeks needs ~110 kcal/day, raised to the minimum surplus
+	deadline = now.AddDate(0, 0, 140)
+	gain := computeTargets(2500, 1700, 70, 72, &deadline, now)
+	if gain.Goal != GoalGain || gain.Calories != 2650 || gain.Adjustment != 150 {
+		t.Errorf("unexpected weight gain targets %+v", gain)
+	}
+}
+
+func TestBuildAdherence(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	targets := &Targets{Nutrients: Nutrients{Calories: 2000, Protein: 150}}
+	entries := []models.MealEntry{
+		{Date: start, Calories: 1200, Protein: 80},
+		{Date: start, Calories: 750, Protein: 60},
+		{Date: start.AddDate(0, 0, 1), Calories: 2600, Protein: 160},
+	}
+
+	adherence := buildAdherence(entries, targets, start, start.AddDate(0, 0, 4))
+	if len(adherence.Days) != 4 || adherence.From != "2024-03-01" || adherence.To != "2024-03-04" {
+		t.Fatalf("unexpected range %+v", adherence)
+	}
+	if !adherence.Days[0].OnTarget || adherence.Days[0].CaloriePercent != 97.5 {
+		t.Errorf("expected the first day on target, got %+v", adherence.Days[0])
+	}
+	if adherence.Days[1].OnTarget || adherence.Days[2].Logged {
+		t.Errorf("expected the second day over target and the third unlogged, got %+v", adherence.Days)
+	}
+	if adherence.DaysLogged != 2 || adherence.LoggingRate != 50 || adherence.AdherenceRate != 50 || adherence.Averages.Calories != 2275 {
+		t.Errorf("unexpected summary %+v", adherence)
+	}
+}
+
+func TestRecipeNutrition(t *testing.T) {
+	recipe := &models.Recipe{
+		Servings: 2,
+		Ingredients: []models.RecipeIngredient{
+			{FoodName: "Oats, rolled", Grams: 80, Calories: 303, Protein: 10.6, Carbs: 48, Fat: 5.2, Fiber: 8.1},
+			{FoodName: "Milk, semi-skimmed", Grams: 300, Calories: 150, Protein: 10.2, Carbs: 14.4, Fat: 5.4},
+			{FoodName: "Banana", Grams: 118, Calories: 105, Protein: 1.3, Carbs: 23.8, Fat: 0.4, Fiber: 3.1},
+		},
+	}
+	computeRecipeNutrition(recipe)
+	if recipe.YieldGrams != 498 || recipe.ServingGrams != 249 {
+		t.Errorf("expected the yield to default to the ingredient total, got %v / %v", recipe.YieldGrams, recipe.ServingGrams)
+	}
+	if recipe.Calories != 279 || recipe.Protein != 11.1 || recipe.Fiber != 5.6 {
+		t.Errorf("unexpected per-serving nutrition %+v", recipe)
+	}
+
+	scaled := buildRecipeResponse(recipe, 3)
+	if scaled.Total.Calories != 837 || scaled.Ingredients[0].Grams != 120 || scaled.YieldGrams != 747 {
+		t.Errorf("expected quantities scaled by 1.5, got %+v", scaled)
+	}
+}
+
+func TestValidateRecipeRequest(t *testing.T) {
+	grams := 100.0
+	req := &RecipeRequest{
+		Name:        "Overnight Oats",
+		Servings:    1,
+		Steps:       []string{" Mix ", "", "Chill overnight"},
+		Tags:        []string{"Breakfast", "high protein", "breakfast"},
+		Ingredients: []RecipeIngredientRequest{{FoodID: 1, Grams: &grams}},
+	}
+	steps, tags, err := validateRecipeRequest(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(steps) != 2 || steps[0] != "Mix" || len(tags) != 2 || tags[1] != "high-protein" {
+		t.Errorf("unexpected steps %q and tags %q", steps, tags)
+	}
+
+	req.Ingredients = nil
+	if _, _, err := validateRecipeRequest(req); err == nil {
+		t.Error("expected a recipe without ingredients to be rejected")
+	}
+}
@@ -0,0 +1,415 @@
+package nutrition
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+
+	"gorm.io/gorm"
+)
+
+// Limits on recipe content
+const (
+	maxRecipeIngredients = 50
+	maxRecipeSteps       = 50
+	maxRecipeTags        = 10
+)
+
+// RecipeRequest represents a recipe creation/update request
+type RecipeRequest struct {
+	Name        string                    `json:"name" binding:"required,max=200"`
+	Description string                    `json:"description" binding:"max=2000"`
+	Servings    int                       `json:"servings" binding:"required,min=1,max=100"`
+	YieldGrams  float64                   `json:"yield_grams" binding:"gte=0,lte=20000"` // finished weight; 0 uses the ingredient total
+	PrepMinutes int                       `json:"prep_minutes" binding:"gte=0,lte=1440"`
+	CookMinutes int                       `json:"cook_minutes" binding:"gte=0,lte=1440"`
+	Steps       []string                  `json:"steps"`
+	Tags        []string                  `json:"tags"`
+	Ingredients []RecipeIngredientRequest `json:"ingredients" binding:"required,min=1"`
+}
+
+// RecipeIngredientRequest adds a food to a recipe, given in grams or in the food's servings
+type RecipeIngredientRequest struct {
+	FoodID   uint     `json:"food_id" binding:"required"`
+	Grams    *float64 `json:"grams"`
+	Servings *float64 `json:"servings"`
+	Notes    string   `json:"notes"`
+}
+
+// RecipeFilter holds the recipe search parameters
+type RecipeFilter struct {
+	Query string // matches name or description
+	Tag   string
+	Mine  bool // only recipes the caller added
+}
+
+// RecipeResponse is a recipe with its quantities and nutrition scaled to a number of servings
+type RecipeResponse struct {
+	ID           uint                       `json:"id"`
+	Name         string                     `json:"name"`
+	Description  string                     `json:"description"`
+	BaseServings int                        `json:"base_servings"` // servings the recipe is written for
+	Servings     float64                    `json:"servings"`      // servings the quantities are scaled to
+	YieldGrams   float64                    `json:"yield_grams"`   // finished weight for the scaled servings
+	ServingGrams float64                    `json:"serving_grams"`
+	PrepMinutes  int                        `json:"prep_minutes"`
+	CookMinutes  int                        `json:"cook_minutes"`
+	TotalMinutes int                        `json:"total_minutes"`
+	Steps        []string                   `json:"steps"`
+	Tags         []string                   `json:"tags"`
+	Allergens    []string                   `json:"allergens"`
+	PerServing   Nutrients                  `json:"per_serving"`
+	Total        Nutrients                  `json:"total"` // for the scaled servings
+	Ingredients  []RecipeIngredientResponse `json:"ingredients,omitempty"`
+	IsPrivate    bool                       `json:"is_private"`
+	CreatedBy    *uint                      `json:"created_by,omitempty"`
+}
+
+// RecipeIngredientResponse is an ingredient scaled to the requested servings
+type RecipeIngredientResponse struct {
+	FoodID   *uint   `json:"food_id,omitempty"`
+	FoodName string  `json:"food_name"`
+	Category string  `json:"category"`
+	Grams    float64 `json:"grams"`
+	Notes    string  `json:"notes,omitempty"`
+	Nutrients
+}
+
+// SearchRecipes searches shared recipes and the user's own private ones
+func (s *NutritionService) SearchRecipes(filter RecipeFilter, userID uint) ([]RecipeResponse, error) {
+	query := s.db.Where("is_private = ? OR created_by = ?", false, userID)
+	if filter.Mine {
+		query = query.Where("created_by = ?", userID)
+	}
+	if q := strings.ToLower(strings.TrimSpace(filter.Query)); q != "" {
+		like := "%" + q + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", like, like)
+	}
+	if tag := normalizeTag(filter.Tag); tag != "" {
+		// Tags are stored as JSON arrays, so match the quoted tag
+		query = query.Where("tags LIKE ?", `%"`+tag+`"%`)
+	}
+
+	var recipes []models.Recipe
+	if err := query.Order("name").Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []RecipeResponse{}
+	for i := range recipes {
+		responses = append(responses, *buildRecipeResponse(&recipes[i], float64(recipes[i].Servings)))
+	}
+	return responses, nil
+}
+
+// GetRecipe retrieves a recipe visible to the user, scaled to a number of servings
+// (0 keeps the servings the recipe is written for)
+func (s *NutritionService) GetRecipe(recipeID, userID uint, servings float64) (*RecipeResponse, error) {
+	recipe, err := s.findRecipe(recipeID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if servings < 0 || servings > 100 {
+		return nil, errors.New("servings must be between 0 and 100")
+	}
+	if servings == 0 {
+		servings = float64(recipe.Servings)
+	}
+	return buildRecipeResponse(recipe, servings), nil
+}
+
+// CreateRecipe adds a recipe and computes its nutrition from the ingredients
+// Admin and trainer recipes are shared; members' recipes stay private to them
+func (s *NutritionService) CreateRecipe(req *RecipeRequest, userID uint, userRole string) (*RecipeResponse, error) {
+	recipe := models.Recipe{
+		CreatedBy: &userID,
+		IsPrivate: userRole != "admin" && userRole != "trainer",
+	}
+	if err := s.applyRecipeRequest(&recipe, req, userID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueRecipe(&recipe); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&recipe).Error; err != nil {
+		return nil, err
+	}
+	return buildRecipeResponse(&recipe, float64(recipe.Servings)), nil
+}
+
+// UpdateRecipe edits a recipe and recomputes its nutrition
+// Diet plans and logged meals keep the nutrients they were saved with
+func (s *NutritionService) UpdateRecipe(recipeID uint, req *RecipeRequest, userID uint, userRole string) (*RecipeResponse, error) {
+	recipe, err := s.findEditableRecipe(recipeID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRecipeRequest(recipe, req, userID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureUniqueRecipe(recipe); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("recipe_id = ?", recipe.ID).Delete(&models.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Ingredients").Save(recipe).Error; err != nil {
+			return err
+		}
+		for i := range recipe.Ingredients {
+			recipe.Ingredients[i].RecipeID = recipe.ID
+		}
+		return tx.Create(&recipe.Ingredients).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildRecipeResponse(recipe, float64(recipe.Servings)), nil
+}
+
+// DeleteRecipe removes a recipe; diet plans and logged meals keep its name and nutrients
+func (s *NutritionService) DeleteRecipe(recipeID, userID uint, userRole string) error {
+	recipe, err := s.findEditableRecipe(recipeID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(recipe).Error
+}
+
+// findRecipe loads a recipe visible to the user with its ingredients
+func (s *NutritionService) findRecipe(recipeID, userID uint) (*models.Recipe, error) {
+	var recipe models.Recipe
+	err := s.db.Preload("Ingredients", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&recipe, recipeID).Error
+	if err != nil {
+		return nil, errors.New("recipe not found")
+	}
+	if recipe.IsPrivate && (recipe.CreatedBy == nil || *recipe.CreatedBy != userID) {
+		return nil, errors.New("recipe not found")
+	}
+	return &recipe, nil
+}
+
+func (s *NutritionService) findEditableRecipe(recipeID, userID uint, userRole string) (*models.Recipe, error) {
+	recipe, err := s.findRecipe(recipeID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if userRole != "admin" && (recipe.CreatedBy == nil || *recipe.CreatedBy != userID) {
+		return nil, errors.New("you can only change recipes you added")
+	}
+	return recipe, nil
+}
+
+// ensureUniqueRecipe rejects a second recipe with the same name among the shared
+// recipes, or among the creator's own private recipes
+func (s *NutritionService) ensureUniqueRecipe(recipe *models.Recipe) error {
+	query := s.db.Model(&models.Recipe{}).
+		Where("LOWER(name) = ? AND id <> ?", strings.ToLower(recipe.Name), recipe.ID)
+	if recipe.IsPrivate {
+		query = query.Where("is_private = ? AND created_by = ?", true, *recipe.CreatedBy)
+	} else {
+		query = query.Where("is_private = ?", false)
+	}
+
+	var count int64
+	query.Count(&count)
+	if count > 0 {
+		return errors.New("a recipe with this name already exists")
+	}
+	return nil
+}
+
+// applyRecipeRequest validates a request, resolves its ingredients and recomputes nutrition
+// Shared recipes can only use shared foods, since other users cannot see private ones
+func (s *NutritionService) applyRecipeRequest(recipe *models.Recipe, req *RecipeRequest, userID uint) error {
+	steps, tags, err := validateRecipeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	ingredients := []models.RecipeIngredient{}
+	allergens := []string{}
+	for i, item := range req.Ingredients {
+		food, err := s.GetFood(item.FoodID, userID)
+		if err != nil {
+			return fmt.Errorf("ingredient %d: %v", i+1, err)
+		}
+		if food.IsPrivate && !recipe.IsPrivate {
+			return fmt.Errorf("ingredient %d: %q is a private food and cannot be used in shared recipes", i+1, food.Name)
+		}
+		grams, err := portionGrams(food, item.Grams, item.Servings)
+		if err != nil {
+			return fmt.Errorf("ingredient %d: %v", i+1, err)
+		}
+		if grams <= 0 || grams > 5000 {
+			return fmt.Errorf("ingredient %d: grams must be between 0 and 5000", i+1)
+		}
+
+		for _, allergen := range decodeList(food.Allergens) {
+			if !contains(allergens, allergen) {
+				allergens = append(allergens, allergen)
+			}
+		}
+		nutrients := scaleFood(food, grams)
+		ingredients = append(ingredients, models.RecipeIngredient{
+			Position: i + 1,
+			FoodID:   &food.ID,
+			FoodName: food.Name,
+			Category: food.Category,
+			Grams:    grams,
+			Calories: nutrients.Calories,
+			Protein:  nutrients.Protein,
+			Carbs:    nutrients.Carbs,
+			Fat:      nutrients.Fat,
+			Fiber:    nutrients.Fiber,
+			Notes:    strings.TrimSpace(item.Notes),
+		})
+	}
+
+	recipe.Name = strings.TrimSpace(req.Name)
+	recipe.Description = strings.TrimSpace(req.Description)
+	recipe.Servings = req.Servings
+	recipe.YieldGrams = req.YieldGrams
+	recipe.PrepMinutes = req.PrepMinutes
+	recipe.CookMinutes = req.CookMinutes
+	recipe.Steps = encodeList(steps)
+	recipe.Tags = encodeList(tags)
+	recipe.Ingredients = ingredients
+	sort.Strings(allergens)
+	recipe.Allergens = encodeList(allergens)
+	computeRecipeNutrition(recipe)
+	return nil
+}
+
+// computeRecipeNutrition totals the ingredients and derives the per-serving values
+// and the serving weight of the recipe
+func computeRecipeNutrition(recipe *models.Recipe) {
+	var total Nutrients
+	var grams float64
+	for i := range recipe.Ingredients {
+		ingredient := &recipe.Ingredients[i]
+		total = add(total, Nutrients{Calories: ingredient.Calories, Protein: ingredient.Protein, Carbs: ingredient.Carbs, Fat: ingredient.Fat, Fiber: ingredient.Fiber})
+		grams += ingredient.Grams
+	}
+	if recipe.YieldGrams <= 0 {
+		recipe.YieldGrams = utils.Round(grams, 1)
+	}
+
+	servings := float64(recipe.Servings)
+	recipe.ServingGrams = utils.Round(recipe.YieldGrams/servings, 1)
+	recipe.Calories = utils.Round(total.Calories/servings, 0)
+	recipe.Protein = utils.Round(total.Protein/servings, 1)
+	recipe.Carbs = utils.Round(total.Carbs/servings, 1)
+	recipe.Fat = utils.Round(total.Fat/servings, 1)
+	recipe.Fiber = utils.Round(total.Fiber/servings, 1)
+}
+
+// validateRecipeRequest checks the request and returns the cleaned steps and tags
+func validateRecipeRequest(req *RecipeRequest) ([]string, []string, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, nil, errors.New("name is required")
+	}
+	if req.Servings < 1 {
+		return nil, nil, errors.New("servings must be at least 1")
+	}
+	if len(req.Ingredients) == 0 {
+		return nil, nil, errors.New("at least one ingredient is required")
+	}
+	if len(req.Ingredients) > maxRecipeIngredients {
+		return nil, nil, fmt.Errorf("a recipe can have at most %d ingredients", maxRecipeIngredients)
+	}
+
+	steps := []string{}
+	for _, step := range req.Steps {
+		if step = strings.TrimSpace(step); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	if len(steps) > maxRecipeSteps {
+		return nil, nil, fmt.Errorf("a recipe can have at most %d steps", maxRecipeSteps)
+	}
+
+	tags := []string{}
+	for _, tag := range req.Tags {
+		if tag = normalizeTag(tag); tag != "" && !contains(tags, tag) {
+			if len(tag) > 30 {
+				return nil, nil, fmt.Errorf("tag %q is longer than 30 characters", tag)
+			}
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxRecipeTags {
+		return nil, nil, fmt.Errorf("a recipe can have at most %d tags", maxRecipeTags)
+	}
+
+	return steps, tags, nil
+}
+
+// buildRecipeResponse scales a recipe's ingredients and nutrition to a number of servings
+func buildRecipeResponse(recipe *models.Recipe, servings float64) *RecipeResponse {
+	factor := servings / float64(recipe.Servings)
+	perServing := Nutrients{Calories: recipe.Calories, Protein: recipe.Protein, Carbs: recipe.Carbs, Fat: recipe.Fat, Fiber: recipe.Fiber}
+
+	response := &RecipeResponse{
+		ID:           recipe.ID,
+		Name:         recipe.Name,
+		Description:  recipe.Description,
+		BaseServings: recipe.Servings,
+		Servings:     servings,
+		YieldGrams:   utils.Round(recipe.YieldGrams*factor, 1),
+		ServingGrams: recipe.ServingGrams,
+		PrepMinutes:  recipe.PrepMinutes,
+		CookMinutes:  recipe.CookMinutes,
+		TotalMinutes: recipe.PrepMinutes + recipe.CookMinutes,
+		Steps:        decodeList(recipe.Steps),
+		Tags:         decodeList(recipe.Tags),
+		Allergens:    decodeList(recipe.Allergens),
+		PerServing:   perServing,
+		Total:        scaleNutrients(perServing, servings),
+		IsPrivate:    recipe.IsPrivate,
+		CreatedBy:    recipe.CreatedBy,
+	}
+
+	for _, ingredient := range recipe.Ingredients {
+		response.Ingredients = append(response.Ingredients, RecipeIngredientResponse{
+			FoodID:    ingredient.FoodID,
+			FoodName:  ingredient.FoodName,
+			Category:  ingredient.Category,
+			Grams:     utils.Round(ingredient.Grams*factor, 1),
+			Notes:     ingredient.Notes,
+			Nutrients: scaleNutrients(Nutrients{Calories: ingredient.Calories, Protein: ingredient.Protein, Carbs: ingredient.Carbs, Fat: ingredient.Fat, Fiber: ingredient.Fiber}, factor),
+		})
+	}
+	return response
+}
+
+func scaleNutrients(n Nutrients, factor float64) Nutrients {
+	return Nutrients{
+		Calories: utils.Round(n.Calories*factor, 0),
+		Protein:  utils.Round(n.Protein*factor, 1),
+		Carbs:    utils.Round(n.Carbs*factor, 1),
+		Fat:      utils.Round(n.Fat*factor, 1),
+		Fiber:    utils.Round(n.Fiber*factor, 1),
+	}
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
+
+func decodeList(value string) []string {
+	values := []string{}
+	if value != "" {
+		json.Unmarshal([]byte(value), &values)
+	}
+	return values
+}
@@ -0,0 +1,289 @@
+package nutrition
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+)
+
+// Energy in a kilogram of body weight, used to turn a weekly rate into a daily deficit or surplus
+const kcalPerKg = 7700
+
+// Goal directions targets are derived for
+const (
+	GoalLose     = "lose"
+	GoalGain     = "gain"
+	GoalMaintain = "maintain"
+)
+
+// Targets are daily calorie and macro targets derived from TDEE and the weight goal
+type Targets struct {
+	Nutrients
+	TDEE         float64    `json:"tdee"`        // kcal/day before the goal adjustment
+	Adjustment   float64    `json:"adjustment"`  // kcal/day added (surplus) or removed (deficit)
+	Goal         string     `json:"goal"`        // lose, gain, maintain
+	GoalSource   string     `json:"goal_source"` // goal (an active weight goal), profile (target weight) or none
+	WeightKg     float64    `json:"weight_kg"`
+	TargetWeight float64    `json:"target_weight,omitempty"`
+	Deadline     *time.Time `json:"deadline,omitempty"`
+}
+
+// DayAdherence compares one day's intake with the targets
+type DayAdherence struct {
+	Date           string    `json:"date"`
+	Logged         bool      `json:"logged"`
+	Totals         Nutrients `json:"totals"`
+	CaloriePercent float64   `json:"calorie_percent"` // of the calorie target
+	ProteinPercent float64   `json:"protein_percent"` // of the protein target
+	OnTarget       bool      `json:"on_target"`
+}
+
+// Adherence summarises how closely a member followed their targets over a date range
+type Adherence struct {
+	UserID        uint           `json:"user_id"`
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+	Targets       Targets        `json:"targets"`
+	Days          []DayAdherence `json:"days"`
+	DaysLogged    int            `json:"days_logged"`
+	DaysOnTarget  int            `json:"days_on_target"`
+	LoggingRate   float64        `json:"logging_rate"`   // % of days with any entry
+	AdherenceRate float64        `json:"adherence_rate"` // % of logged days on target
+	Averages      Nutrients      `json:"averages"`       // per logged day
+}
+
+// Calories within this share of the target, with at least this share of the protein target, count as on target
+const (
+	calorieTolerance = 0.10
+	proteinMinimum   = 0.90
+)
+
+// maxAdherenceDays bounds the adherence date range
+const maxAdherenceDays = 92
+
+// GetTargets derives a member's daily calorie and macro targets
+func (s *NutritionService) GetTargets(memberID, viewerID uint, viewerRole string) (*Targets, error) {
+	if err := s.canViewNutrition(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	targets, missing, err := s.loadTargets(memberID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if targets == nil {
+		return nil, fmt.Errorf("targets need %s in the profile", strings.Join(missing, ", "))
+	}
+	return targets, nil
+}
+
+// GetAdherence compares a member's daily intake with their current targets
+// The range defaults to the last 7 days; to is exclusive
+func (s *NutritionService) GetAdherence(memberID, viewerID uint, viewerRole string, from, to *time.Time) (*Adherence, error) {
+	if err := s.canViewNutrition(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	end := utils.Today().AddDate(0, 0, 1)
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(0, 0, -7)
+	if from != nil {
+		start = *from
+	}
+	if !start.Before(end) {
+		return nil, errors.New("from must be before to")
+	}
+	if end.Sub(start).Hours()/24 > maxAdherenceDays {
+		return nil, fmt.Errorf("the date range cannot exceed %d days", maxAdherenceDays)
+	}
+
+	targets, missing, err := s.loadTargets(memberID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if targets == nil {
+		return nil, fmt.Errorf("targets need %s in the profile", strings.Join(missing, ", "))
+	}
+
+	var entries []models.MealEntry
+	if err := s.db.Where("user_id = ? AND date >= ? AND date < ?", memberID, start, end).Find(&entries).Error; err != nil {
+		return nil, errors.New("failed to load meals")
+	}
+
+	adherence := buildAdherence(entries, targets, start, end)
+	adherence.UserID = memberID
+	return adherence, nil
+}
+
+// loadTargets derives targets from the member's metrics and weight goal
+// When TDEE cannot be calculated it returns nil with the missing profile fields
+func (s *NutritionService) loadTargets(memberID uint, now time.Time) (*Targets, []string, error) {
+	current, err := s.metricsService.GetMetrics(memberID, memberID, "member", "")
+	if err != nil {
+		if err.Error() == "profile not found" {
+			return nil, []string{"weight", "height", "age", "gender", "activity_level"}, nil
+		}
+		return nil, nil, err
+	}
+	if current.TDEE == nil || current.BMR == nil {
+		return nil, current.Missing, nil
+	}
+
+	targetWeight, deadline, source, err := s.weightGoal(memberID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets := computeTargets(current.TDEE.Value, current.BMR.Value, current.Inputs.WeightKg, targetWeight, deadline, now)
+	targets.GoalSource = source
+	return targets, nil, nil
+}
+
+// weightGoal finds the member's target weight and deadline: the active weight goal with the
+// nearest deadline, otherwise the profile's target weight and timeline
+func (s *NutritionService) weightGoal(memberID uint, now time.Time) (float64, *time.Time, string, error) {
+	var goal models.Goal
+	err := s.db.Where("user_id = ? AND type = ? AND status = ?", memberID, "weight", "active").
+		Order("deadline").First(&goal).Error
+	if err == nil {
+		return goal.TargetValue, &goal.Deadline, "goal", nil
+	}
+
+	var profile models.UserProfile
+	if err := s.db.Where("user_id = ?", memberID).First(&profile).Error; err != nil {
+		return 0, nil, "none", nil
+	}
+	if profile.TargetWeight <= 0 {
+		return 0, nil, "none", nil
+	}
+	var deadline *time.Time
+	if profile.Timeline > 0 {
+		date := profile.CreatedAt.AddDate(0, 0, profile.Timeline)
+		if date.After(now) {
+			deadline = &date
+		}
+	}
+	return profile.TargetWeight, deadline, "profile", nil
+}
+
+// computeTargets turns TDEE into calorie and macro targets for the weight goal
+// Without a deadline the deficit is 500 kcal and the surplus 300 kcal; with one the rate
+// needed to reach the target is used, kept within safe bounds. Calories never go below BMR
+// or 1200 kcal. Protein is set per kg of body weight, fat at 25% of calories and carbs fill
+// the rest
+func computeTargets(tdee, bmr, weightKg, targetWeight float64, deadline *time.Time, now time.Time) *Targets {
+	targets := &Targets{
+		TDEE:         math.Round(tdee),
+		Goal:         GoalMaintain,
+		WeightKg:     weightKg,
+		TargetWeight: targetWeight,
+		Deadline:     deadline,
+	}
+
+	difference := targetWeight - weightKg
+	if targetWeight > 0 && math.Abs(difference) >= 0.5 {
+		adjustment := 500.0
+		targets.Goal = GoalLose
+		if difference > 0 {
+			adjustment = 300
+			targets.Goal = GoalGain
+		}
+		if deadline != nil && deadline.After(now) {
+			weeks := math.Max(1, deadline.Sub(now).Hours()/(24*7))
+			adjustment = math.Abs(difference) / weeks * kcalPerKg / 7
+		}
+		if targets.Goal == GoalLose {
+			targets.Adjustment = -math.Max(250, math.Min(adjustment, 1000))
+		} else {
+			targets.Adjustment = math.Max(150, math.Min(adjustment, 500))
+		}
+	}
+
+	calories := tdee + targets.Adjustment
+	if floor := math.Max(bmr, 1200); calories < floor {
+		calories = floor
+	}
+	calories = math.Round(calories/10) * 10
+	targets.Adjustment = calories - targets.TDEE
+
+	proteinPerKg := 1.6
+	switch targets.Goal {
+	case GoalLose:
+		proteinPerKg = 2.0
+	case GoalGain:
+		proteinPerKg = 1.8
+	}
+	protein := math.Round(weightKg * proteinPerKg)
+	fat := math.Round(calories * 0.25 / 9)
+	carbs := math.Max(0, math.Round((calories-protein*4-fat*9)/4))
+
+	targets.Nutrients = Nutrients{
+		Calories: calories,
+		Protein:  protein,
+		Carbs:    carbs,
+		Fat:      fat,
+		Fiber:    math.Round(calories / 1000 * 14),
+	}
+	return targets
+}
+
+// buildAdherence totals each day in [start, end) and compares it with the targets
+func buildAdherence(entries []models.MealEntry, targets *Targets, start, end time.Time) *Adherence {
+	totals := map[string]Nutrients{}
+	for i := range entries {
+		key := entries[i].Date.Format("2006-01-02")
+		totals[key] = add(totals[key], entryNutrients(&entries[i]))
+	}
+
+	adherence := &Adherence{
+		From:    start.Format("2006-01-02"),
+		To:      end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Targets: *targets,
+		Days:    []DayAdherence{},
+	}
+	var sum Nutrients
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		dayTotals, logged := totals[key]
+		entry := DayAdherence{Date: key, Logged: logged, Totals: dayTotals}
+		if logged {
+			entry.CaloriePercent = percentOf(dayTotals.Calories, targets.Calories)
+			entry.ProteinPercent = percentOf(dayTotals.Protein, targets.Protein)
+			entry.OnTarget = math.Abs(dayTotals.Calories-targets.Calories) <= targets.Calories*calorieTolerance &&
+				dayTotals.Protein >= targets.Protein*proteinMinimum
+			adherence.DaysLogged++
+			if entry.OnTarget {
+				adherence.DaysOnTarget++
+			}
+			sum = add(sum, dayTotals)
+		}
+		adherence.Days = append(adherence.Days, entry)
+	}
+
+	adherence.LoggingRate = percentOf(float64(adherence.DaysLogged), float64(len(adherence.Days)))
+	if adherence.DaysLogged > 0 {
+		days := float64(adherence.DaysLogged)
+		adherence.AdherenceRate = percentOf(float64(adherence.DaysOnTarget), days)
+		adherence.Averages = Nutrients{
+			Calories: utils.Round(sum.Calories/days, 0),
+			Protein:  utils.Round(sum.Protein/days, 1),
+			Carbs:    utils.Round(sum.Carbs/days, 1),
+			Fat:      utils.Round(sum.Fat/days, 1),
+			Fiber:    utils.Round(sum.Fiber/days, 1),
+		}
+	}
+	return adherence
+}
+
+func percentOf(value, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return utils.Round(value/total*100, 1)
+}
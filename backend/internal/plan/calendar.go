@@ -0,0 +1,334 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+
+	"gorm.io/gorm"
+)
+
+// CalendarWorkout is a workout placed on a member's calendar
+type CalendarWorkout struct {
+	UserPlanID      uint                           `json:"user_plan_id"`
+	PlanName        string                         `json:"plan_name"`
+	PlanDay         int                            `json:"plan_day"`
+	WorkoutPosition int                            `json:"workout_position"`
+	Name            string                         `json:"name"`
+	Notes           string                         `json:"notes,omitempty"`
+	Status          string                         `json:"status"` // scheduled, completed, missed
+	RescheduledFrom *time.Time                     `json:"rescheduled_from,omitempty"`
+	Exercises       []ExercisePrescriptionResponse `json:"exercises"`
+}
+
+// CalendarDay lists the workouts scheduled on one date
+type CalendarDay struct {
+	Date      time.Time         `json:"date"`
+	Weekday   string            `json:"weekday"`
+	IsToday   bool              `json:"is_today"`
+	IsRestDay bool              `json:"is_rest_day"`
+	Workouts  []CalendarWorkout `json:"workouts"`
+}
+
+// CalendarResponse holds today's workouts and the surrounding week (Monday to Sunday)
+type CalendarResponse struct {
+	Today CalendarDay   `json:"today"`
+	Week  []CalendarDay `json:"week"`
+}
+
+// GetCalendar returns the member's workouts for the week containing date
+func (s *PlanService) GetCalendar(userID uint, date time.Time) (*CalendarResponse, error) {
+	var userPlans []models.UserPlan
+	err := s.db.Where("user_id = ? AND status = ?", userID, "active").
+		Preload("Plan").Preload("PlanVersion").
+		Find(&userPlans).Error
+	if err != nil {
+		return nil, err
+	}
+
+	today := utils.Today()
+	day := utils.DateOnly(date)
+	weekStart := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	week := make([]CalendarDay, 7)
+	for i := range week {
+		d := weekStart.AddDate(0, 0, i)
+		week[i] = CalendarDay{Date: d, Weekday: d.Weekday().String(), IsToday: d.Equal(today), Workouts: []CalendarWorkout{}}
+	}
+
+	for i := range userPlans {
+		userPlan := &userPlans[i]
+
+		// Plans assigned before the calendar existed get one on first view
+		var entryCount int64
+		s.db.Model(&models.PlanCalendarEntry{}).Where("user_plan_id = ?", userPlan.ID).Count(&entryCount)
+		if entryCount == 0 {
+			if err := s.scheduleCalendar(s.db, userPlan, userPlan.AssignedAt, 1); err != nil {
+				return nil, err
+			}
+		}
+
+		var entries []models.PlanCalendarEntry
+		s.db.Where("user_plan_id = ? AND date BETWEEN ? AND ?", userPlan.ID, weekStart, weekEnd).Find(&entries)
+		if len(entries) == 0 {
+			continue
+		}
+
+		days := s.userPlanDays(userPlan)
+		cycle := cycleLength(days)
+		completed := s.completedWorkouts(userPlan.ID)
+
+		for _, entry := range entries {
+			content, ok := days[(entry.PlanDay-1)%cycle+1]
+			if !ok {
+				continue
+			}
+			index := daysBetween(weekStart, entry.Date)
+			for w, workout := range content.Workouts {
+				position := workout.Position
+				if position == 0 {
+					position = w + 1
+				}
+
+				status := "scheduled"
+				if completed[[2]int{entry.PlanDay, position}] {
+					status = "completed"
+				} else if utils.DateOnly(entry.Date).Before(today) {
+					status = "missed"
+				}
+
+				week[index].Workouts = append(week[index].Workouts, CalendarWorkout{
+					UserPlanID:      userPlan.ID,
+					PlanName:        userPlan.Plan.Name,
+					PlanDay:         entry.PlanDay,
+					WorkoutPosition: position,
+					Name:            workout.Name,
+					Notes:           workout.Notes,
+					Status:          status,
+					RescheduledFrom: entry.RescheduledFrom,
+					Exercises:       workout.Exercises,
+				})
+			}
+		}
+	}
+
+	response := &CalendarResponse{Week: week}
+	for i := range week {
+		week[i].IsRestDay = len(week[i].Workouts) == 0
+		if week[i].Date.Equal(day) {
+			response.Today = week[i]
+		}
+	}
+	return response, nil
+}
+
+// RescheduleWorkout moves a training day that has not been completed to another date
+// With shiftRemaining, that day and every later unfinished day are projected again from
+// the new date onto the member's workout days
+func (s *PlanService) RescheduleWorkout(userID, userPlanID uint, planDay int, date time.Time, shiftRemaining bool) (*CalendarResponse, error) {
+	var userPlan models.UserPlan
+	err := s.db.Where("id = ? AND user_id = ?", userPlanID, userID).
+		Preload("Plan").Preload("PlanVersion").
+		First(&userPlan).Error
+	if err != nil {
+		return nil, errors.New("user plan not found")
+	}
+	if userPlan.Status != "active" {
+		return nil, fmt.Errorf("plan is %s: only active plans can be rescheduled", userPlan.Status)
+	}
+
+	date = utils.DateOnly(date)
+	if date.Before(utils.Today()) {
+		return nil, errors.New("workouts can only be moved to today or a later date")
+	}
+
+	var entry models.PlanCalendarEntry
+	if err := s.db.Where("user_plan_id = ? AND plan_day = ?", userPlan.ID, planDay).First(&entry).Error; err != nil {
+		return nil, fmt.Errorf("plan day %d is not a scheduled training day", planDay)
+	}
+	for key := range s.completedWorkouts(userPlan.ID) {
+		if key[0] == planDay {
+			return nil, fmt.Errorf("plan day %d has already been completed", planDay)
+		}
+	}
+
+	originalDate := utils.DateOnly(entry.Date)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if shiftRemaining {
+			if err := s.scheduleCalendar(tx, &userPlan, date, planDay); err != nil {
+				return err
+			}
+		} else if err := tx.Model(&entry).Update("date", date).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.PlanCalendarEntry{}).
+			Where("user_plan_id = ? AND plan_day = ?", userPlan.ID, planDay).
+			Update("rescheduled_from", originalDate).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCalendar(userID, date)
+}
+
+// scheduleCalendar projects the unfinished training days of a plan, from fromPlanDay on,
+// onto the calendar starting at from. Completed days keep their dates
+func (s *PlanService) scheduleCalendar(tx *gorm.DB, userPlan *models.UserPlan, from time.Time, fromPlanDay int) error {
+	completed := map[int]bool{}
+	for key := range s.completedWorkouts(userPlan.ID) {
+		completed[key[0]] = true
+	}
+
+	trainingDays := []int{}
+	seen := map[int]bool{}
+	for _, workout := range s.userPlanSchedule(userPlan) {
+		if workout.PlanDay >= fromPlanDay && !completed[workout.PlanDay] && !seen[workout.PlanDay] {
+			seen[workout.PlanDay] = true
+			trainingDays = append(trainingDays, workout.PlanDay)
+		}
+	}
+
+	var profile models.UserProfile
+	tx.Where("user_id = ?", userPlan.UserID).First(&profile)
+	dates := projectCalendar(trainingDays, from, parseWorkoutDays(profile.WorkoutDays))
+
+	// Remove entries that are being projected again (or no longer exist in the plan)
+	query := tx.Where("user_plan_id = ? AND plan_day >= ?", userPlan.ID, fromPlanDay)
+	if len(completed) > 0 {
+		completedDays := []int{}
+		for day := range completed {
+			completedDays = append(completedDays, day)
+		}
+		query = query.Where("plan_day NOT IN ?", completedDays)
+	}
+	if err := query.Delete(&models.PlanCalendarEntry{}).Error; err != nil {
+		return err
+	}
+
+	entries := []models.PlanCalendarEntry{}
+	for _, planDay := range trainingDays {
+		entries = append(entries, models.PlanCalendarEntry{
+			UserPlanID: userPlan.ID,
+			UserID:     userPlan.UserID,
+			PlanDay:    planDay,
+			Date:       dates[planDay],
+		})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.Create(&entries).Error
+}
+
+// rescheduleUpcoming projects the days not yet reached onto the calendar again from today,
+// used when a member switches to a new plan version
+func (s *PlanService) rescheduleUpcoming(userPlan *models.UserPlan) error {
+	today := utils.Today()
+
+	var lastPast int
+	s.db.Model(&models.PlanCalendarEntry{}).
+		Where("user_plan_id = ? AND date < ?", userPlan.ID, today).
+		Select("COALESCE(MAX(plan_day), 0)").Scan(&lastPast)
+
+	return s.scheduleCalendar(s.db, userPlan, today, lastPast+1)
+}
+
+// completedWorkouts returns the (plan day, workout position) pairs a member has completed
+func (s *PlanService) completedWorkouts(userPlanID uint) map[[2]int]bool {
+	var completions []models.WorkoutCompletion
+	s.db.Where("user_plan_id = ?", userPlanID).Find(&completions)
+
+	completed := map[[2]int]bool{}
+	for _, completion := range completions {
+		completed[[2]int{completion.PlanDay, completion.WorkoutPosition}] = true
+	}
+	return completed
+}
+
+// userPlanDays returns the content days of the version a member follows, keyed by day number
+func (s *PlanService) userPlanDays(userPlan *models.UserPlan) map[int]PlanDayResponse {
+	var days []PlanDayResponse
+	if userPlan.PlanVersion != nil {
+		days = decodePlanContent(userPlan.PlanVersion.Content)
+	} else {
+		var plan models.Plan
+		if err := preloadPlanContent(s.db).First(&plan, userPlan.PlanID).Error; err == nil {
+			days = buildPlanDayResponses(plan.Days)
+		}
+	}
+
+	byNumber := map[int]PlanDayResponse{}
+	for _, day := range days {
+		byNumber[day.DayNumber] = day
+	}
+	return byNumber
+}
+
+func cycleLength(days map[int]PlanDayResponse) int {
+	cycle := 1
+	for number := range days {
+		if number > cycle {
+			cycle = number
+		}
+	}
+	return cycle
+}
+
+// projectCalendar assigns a date to each training day, in order, starting at from
+// With preferred weekdays each training day takes the next free workout day, so rest
+// days in the plan are skipped; without them plan days keep their spacing
+func projectCalendar(trainingDays []int, from time.Time, weekdays map[time.Weekday]bool) map[int]time.Time {
+	sort.Ints(trainingDays)
+	dates := map[int]time.Time{}
+	if len(trainingDays) == 0 {
+		return dates
+	}
+
+	start := utils.DateOnly(from)
+	if len(weekdays) == 0 {
+		for _, planDay := range trainingDays {
+			dates[planDay] = start.AddDate(0, 0, planDay-trainingDays[0])
+		}
+		return dates
+	}
+
+	date := start
+	for _, planDay := range trainingDays {
+		for !weekdays[date.Weekday()] {
+			date = date.AddDate(0, 0, 1)
+		}
+		dates[planDay] = date
+		date = date.AddDate(0, 0, 1)
+	}
+	return dates
+}
+
+// parseWorkoutDays reads UserProfile.WorkoutDays (a JSON list such as ["monday","wed"])
+func parseWorkoutDays(value string) map[time.Weekday]bool {
+	var names []string
+	if value == "" || json.Unmarshal([]byte(value), &names) != nil {
+		return nil
+	}
+
+	weekdays := map[time.Weekday]bool{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) < 3 {
+			continue
+		}
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.HasPrefix(strings.ToLower(day.String()), name[:3]) {
+				weekdays[day] = true
+			}
+		}
+	}
+	return weekdays
+}
@@ -0,0 +1,41 @@
+package plan
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/utils"
+)
+
+func TestParseWorkoutDays(t *testing.T) {
+	weekdays := parseWorkoutDays(`["Monday", "wed", "FRI", "x"]`)
+	if len(weekdays) != 3 || !weekdays[time.Monday] || !weekdays[time.Wednesday] || !weekdays[time.Friday] {
+		t.Errorf("unexpected weekdays %v", weekdays)
+	}
+	if parseWorkoutDays("") != nil || parseWorkoutDays("not json") != nil {
+		t.Error("expected no weekdays for empty or invalid input")
+	}
+}
+
+func TestProjectCalendar(t *testing.T) {
+	// Friday 1 March 2024
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	dates := projectCalendar([]int{1, 3, 4}, start, nil)
+	if !dates[1].Equal(utils.DateOnly(start)) || !dates[4].Equal(utils.DateOnly(start).AddDate(0, 0, 3)) {
+		t.Errorf("expected plan days to keep their spacing, got %v", dates)
+	}
+
+	weekdays := map[time.Weekday]bool{time.Monday: true, time.Friday: true}
+	dates = projectCalendar([]int{4, 1, 3}, start, weekdays)
+	expected := map[int]time.Time{
+		1: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		3: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		4: time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+	}
+	for planDay, date := range expected {
+		if !dates[planDay].Equal(date) {
+			t.Errorf("plan day %d: expected %s, got %s", planDay, date, dates[planDay])
+		}
+	}
+}
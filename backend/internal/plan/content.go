@@ -0,0 +1,429 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+
+	"gorm.io/gorm"
+)
+
+// PlanDayRequest describes one day of a plan's program
+type PlanDayRequest struct {
+	DayNumber int                  `json:"day_number" binding:"required,min=1"`
+	Name      string               `json:"name"`
+	IsRestDay bool                 `json:"is_rest_day"`
+	Notes     string               `json:"notes"`
+	Workouts  []PlanWorkoutRequest `json:"workouts"`
+	Meals     []PlanMealRequest    `json:"meals"` // diet plans only
+}
+
+// PlanWorkoutRequest describes a workout within a plan day
+type PlanWorkoutRequest struct {
+	Name      string                        `json:"name" binding:"required"`
+	Notes     string                        `json:"notes"`
+	Exercises []ExercisePrescriptionRequest `json:"exercises"`
+}
+
+// ExercisePrescriptionRequest describes how an exercise should be performed
+type ExercisePrescriptionRequest struct {
+	ExerciseID      *uint   `json:"exercise_id"`   // exercise library entry (preferred)
+	ExerciseName    string  `json:"exercise_name"` // free text, or filled from the library
+	Sets            int     `json:"sets"`
+	RepsMin         int     `json:"reps_min"`
+	RepsMax         int     `json:"reps_max"`
+	LoadType        string  `json:"load_type"` // kg, percent_1rm, bodyweight, rpe
+	LoadValue       float64 `json:"load_value"`
+	Tempo           string  `json:"tempo"` // e.g. "3-1-1-0" or "2-0-X-0"
+	RestSeconds     int     `json:"rest_seconds"`
+	RPE             float64 `json:"rpe"`
+	DurationSeconds int     `json:"duration_seconds"`
+	DistanceMeters  float64 `json:"distance_meters"`
+	Notes           string  `json:"notes"`
+}
+
+// PlanDayResponse represents a plan day with its workouts, or its meals for diet plans
+type PlanDayResponse struct {
+	ID        uint                  `json:"id,omitempty"`
+	DayNumber int                   `json:"day_number"`
+	Name      string                `json:"name"`
+	IsRestDay bool                  `json:"is_rest_day"`
+	Notes     string                `json:"notes,omitempty"`
+	Workouts  []PlanWorkoutResponse `json:"workouts"`
+	Meals     []PlanMealResponse    `json:"meals,omitempty"`
+	Totals    *Macros               `json:"totals,omitempty"` // macros of the day's meals
+}
+
+// PlanWorkoutResponse represents a workout with its exercise prescriptions
+type PlanWorkoutResponse struct {
+	ID        uint                           `json:"id,omitempty"`
+	Name      string                         `json:"name"`
+	Position  int                            `json:"position"`
+	Notes     string                         `json:"notes,omitempty"`
+	Exercises []ExercisePrescriptionResponse `json:"exercises"`
+}
+
+// ExercisePrescriptionResponse represents a single exercise prescription
+type ExercisePrescriptionResponse struct {
+	ID              uint    `json:"id,omitempty"`
+	Position        int     `json:"position"`
+	ExerciseID      *uint   `json:"exercise_id,omitempty"`
+	ExerciseName    string  `json:"exercise_name"`
+	Sets            int     `json:"sets"`
+	RepsMin         int     `json:"reps_min"`
+	RepsMax         int     `json:"reps_max"`
+	LoadType        string  `json:"load_type,omitempty"`
+	LoadValue       float64 `json:"load_value,omitempty"`
+	Tempo           string  `json:"tempo,omitempty"`
+	RestSeconds     int     `json:"rest_seconds"`
+	RPE             float64 `json:"rpe,omitempty"`
+	DurationSeconds int     `json:"duration_seconds,omitempty"`
+	DistanceMeters  float64 `json:"distance_meters,omitempty"`
+	Notes           string  `json:"notes,omitempty"`
+}
+
+// tempoPattern matches four tempo phases, each a number of seconds or X (explosive)
+var tempoPattern = regexp.MustCompile(`^([0-9]|X)-([0-9]|X)-([0-9]|X)-([0-9]|X)$`)
+
+// validatePlanDays checks the structured plan content against the plan duration
+// Diet plans are made of meals; every other plan type of workouts
+func validatePlanDays(days []PlanDayRequest, duration int, planType string) error {
+	seen := map[int]bool{}
+
+	for d, day := range days {
+		if day.DayNumber < 1 || day.DayNumber > duration {
+			return fmt.Errorf("day %d: day_number must be between 1 and the plan duration (%d)", day.DayNumber, duration)
+		}
+		if seen[day.DayNumber] {
+			return fmt.Errorf("day %d: day_number is used more than once", day.DayNumber)
+		}
+		seen[day.DayNumber] = true
+
+		if planType == "diet" {
+			if len(day.Workouts) > 0 {
+				return fmt.Errorf("day %d: diet plans cannot contain workouts", day.DayNumber)
+			}
+			if day.IsRestDay && len(day.Meals) > 0 {
+				return fmt.Errorf("day %d: rest days cannot contain meals", day.DayNumber)
+			}
+			if !day.IsRestDay && len(day.Meals) == 0 {
+				return fmt.Errorf("day %d: diet days need at least one meal (or set is_rest_day)", day.DayNumber)
+			}
+			// Validate in place so normalized meal types are kept
+			if err := validatePlanMeals(&days[d]); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(day.Meals) > 0 {
+			return fmt.Errorf("day %d: meals are only allowed in diet plans", day.DayNumber)
+		}
+
+		if day.IsRestDay && len(day.Workouts) > 0 {
+			return fmt.Errorf("day %d: rest days cannot contain workouts", day.DayNumber)
+		}
+		if !day.IsRestDay && len(day.Workouts) == 0 {
+			return fmt.Errorf("day %d: training days need at least one workout (or set is_rest_day)", day.DayNumber)
+		}
+
+		for w, workout := range day.Workouts {
+			if strings.TrimSpace(workout.Name) == "" {
+				return fmt.Errorf("day %d, workout %d: name is required", day.DayNumber, w+1)
+			}
+			if len(workout.Exercises) == 0 {
+				return fmt.Errorf("day %d, workout %q: at least one exercise is required", day.DayNumber, workout.Name)
+			}
+			for e := range workout.Exercises {
+				// Validate in place so normalized values (tempo case, rep range) are kept
+				if err := validatePrescription(&workout.Exercises[e]); err != nil {
+					return fmt.Errorf("day %d, workout %q, exercise %d: %v", day.DayNumber, workout.Name, e+1, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePrescription checks a single exercise prescription
+func validatePrescription(exercise *ExercisePrescriptionRequest) error {
+	if exercise.ExerciseID == nil && strings.TrimSpace(exercise.ExerciseName) == "" {
+		return fmt.Errorf("exercise_id or exercise_name is required")
+	}
+	if exercise.Sets < 1 || exercise.Sets > 20 {
+		return fmt.Errorf("sets must be between 1 and 20")
+	}
+
+	// An exercise is either rep based, timed or distance based
+	if exercise.RepsMin == 0 && exercise.RepsMax == 0 && exercise.DurationSeconds == 0 && exercise.DistanceMeters == 0 {
+		return fmt.Errorf("reps, duration_seconds or distance_meters is required")
+	}
+	if exercise.RepsMin < 0 || exercise.RepsMax < 0 || exercise.RepsMax > 100 {
+		return fmt.Errorf("reps must be between 0 and 100")
+	}
+	if exercise.RepsMax == 0 {
+		exercise.RepsMax = exercise.RepsMin
+	}
+	if exercise.RepsMin > exercise.RepsMax {
+		return fmt.Errorf("reps_min cannot be greater than reps_max")
+	}
+	if exercise.DurationSeconds < 0 || exercise.DistanceMeters < 0 {
+		return fmt.Errorf("duration_seconds and distance_meters cannot be negative")
+	}
+
+	switch exercise.LoadType {
+	case "", "bodyweight":
+		if exercise.LoadValue != 0 && exercise.LoadType == "" {
+			return fmt.Errorf("load_type is required when load_value is set")
+		}
+	case "kg":
+		if exercise.LoadValue <= 0 || exercise.LoadValue > 1000 {
+			return fmt.Errorf("load_value in kg must be between 0 and 1000")
+		}
+	case "percent_1rm":
+		if exercise.LoadValue <= 0 || exercise.LoadValue > 100 {
+			return fmt.Errorf("load_value as percent_1rm must be between 0 and 100")
+		}
+	case "rpe":
+		if exercise.LoadValue < 1 || exercise.LoadValue > 10 {
+			return fmt.Errorf("load_value as rpe must be between 1 and 10")
+		}
+	default:
+		return fmt.Errorf("load_type must be one of kg, percent_1rm, bodyweight, rpe")
+	}
+
+	if exercise.Tempo != "" {
+		exercise.Tempo = strings.ToUpper(exercise.Tempo)
+		if !tempoPattern.MatchString(exercise.Tempo) {
+			return fmt.Errorf("tempo must look like 3-1-1-0 (seconds or X for each phase)")
+		}
+	}
+	if exercise.RestSeconds < 0 || exercise.RestSeconds > 900 {
+		return fmt.Errorf("rest_seconds must be between 0 and 900")
+	}
+	if exercise.RPE != 0 && (exercise.RPE < 1 || exercise.RPE > 10) {
+		return fmt.Errorf("rpe must be between 1 and 10")
+	}
+
+	return nil
+}
+
+// buildPlanDays converts validated day requests into models
+func buildPlanDays(days []PlanDayRequest) []models.PlanDay {
+	planDays := []models.PlanDay{}
+
+	for _, day := range days {
+		planDay := models.PlanDay{
+			DayNumber: day.DayNumber,
+			Name:      day.Name,
+			IsRestDay: day.IsRestDay,
+			Notes:     day.Notes,
+		}
+
+		for w, workout := range day.Workouts {
+			planWorkout := models.PlanWorkout{
+				Name:     workout.Name,
+				Position: w + 1,
+				Notes:    workout.Notes,
+			}
+
+			for e, exercise := range workout.Exercises {
+				planWorkout.Exercises = append(planWorkout.Exercises, models.ExercisePrescription{
+					Position:        e + 1,
+					ExerciseID:      exercise.ExerciseID,
+					ExerciseName:    strings.TrimSpace(exercise.ExerciseName),
+					Sets:            exercise.Sets,
+					RepsMin:         exercise.RepsMin,
+					RepsMax:         exercise.RepsMax,
+					LoadType:        exercise.LoadType,
+					LoadValue:       exercise.LoadValue,
+					Tempo:           exercise.Tempo,
+					RestSeconds:     exercise.RestSeconds,
+					RPE:             exercise.RPE,
+					DurationSeconds: exercise.DurationSeconds,
+					DistanceMeters:  exercise.DistanceMeters,
+					Notes:           exercise.Notes,
+				})
+			}
+
+			planDay.Workouts = append(planDay.Workouts, planWorkout)
+		}
+		if len(day.Meals) > 0 {
+			planDay.Meals = buildPlanMeals(day.Meals)
+		}
+
+		planDays = append(planDays, planDay)
+	}
+
+	return planDays
+}
+
+// clonePlanDays copies loaded plan days into new, unsaved models
+func clonePlanDays(days []models.PlanDay) []models.PlanDay {
+	clones := []models.PlanDay{}
+
+	for _, day := range days {
+		dayClone := models.PlanDay{
+			DayNumber: day.DayNumber,
+			Name:      day.Name,
+			IsRestDay: day.IsRestDay,
+			Notes:     day.Notes,
+		}
+
+		for _, workout := range day.Workouts {
+			workoutClone := models.PlanWorkout{
+				Name:     workout.Name,
+				Position: workout.Position,
+				Notes:    workout.Notes,
+			}
+			for _, exercise := range workout.Exercises {
+				exercise.ID = 0
+				exercise.PlanWorkoutID = 0
+				exercise.Exercise = nil
+				exercise.CreatedAt = time.Time{}
+				exercise.UpdatedAt = time.Time{}
+				workoutClone.Exercises = append(workoutClone.Exercises, exercise)
+			}
+			dayClone.Workouts = append(dayClone.Workouts, workoutClone)
+		}
+		if len(day.Meals) > 0 {
+			dayClone.Meals = clonePlanMeals(day.Meals)
+		}
+
+		clones = append(clones, dayClone)
+	}
+
+	return clones
+}
+
+// replacePlanContent removes a plan's days, workouts, exercises and meals and stores new ones
+func replacePlanContent(tx *gorm.DB, planID uint, days []models.PlanDay) error {
+	dayIDs := tx.Model(&models.PlanDay{}).Select("id").Where("plan_id = ?", planID)
+	workoutIDs := tx.Model(&models.PlanWorkout{}).Select("id").Where("plan_day_id IN (?)", dayIDs)
+	mealIDs := tx.Model(&models.PlanMeal{}).Select("id").Where("plan_day_id IN (?)", dayIDs)
+	mealFoodIDs := tx.Model(&models.PlanMealFood{}).Select("id").Where("plan_meal_id IN (?)", mealIDs)
+
+	if err := tx.Unscoped().Where("plan_meal_food_id IN (?)", mealFoodIDs).Delete(&models.PlanFoodSubstitution{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("plan_meal_id IN (?)", mealIDs).Delete(&models.PlanMealFood{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("plan_day_id IN (?)", dayIDs).Delete(&models.PlanMeal{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where("plan_workout_id IN (?)", workoutIDs).Delete(&models.ExercisePrescription{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("plan_day_id IN (?)", dayIDs).Delete(&models.PlanWorkout{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("plan_id = ?", planID).Delete(&models.PlanDay{}).Error; err != nil {
+		return err
+	}
+
+	for i := range days {
+		days[i].PlanID = planID
+	}
+	if len(days) == 0 {
+		return nil
+	}
+	return tx.Create(&days).Error
+}
+
+// buildPlanDayResponses converts loaded plan days into their response format
+func buildPlanDayResponses(days []models.PlanDay) []PlanDayResponse {
+	responses := []PlanDayResponse{}
+
+	for _, day := range days {
+		dayResponse := PlanDayResponse{
+			ID:        day.ID,
+			DayNumber: day.DayNumber,
+			Name:      day.Name,
+			IsRestDay: day.IsRestDay,
+			Notes:     day.Notes,
+			Workouts:  []PlanWorkoutResponse{},
+		}
+
+		for _, workout := range day.Workouts {
+			workoutResponse := PlanWorkoutResponse{
+				ID:        workout.ID,
+				Name:      workout.Name,
+				Position:  workout.Position,
+				Notes:     workout.Notes,
+				Exercises: []ExercisePrescriptionResponse{},
+			}
+
+			for _, exercise := range workout.Exercises {
+				workoutResponse.Exercises = append(workoutResponse.Exercises, ExercisePrescriptionResponse{
+					ID:              exercise.ID,
+					Position:        exercise.Position,
+					ExerciseID:      exercise.ExerciseID,
+					ExerciseName:    exercise.ExerciseName,
+					Sets:            exercise.Sets,
+					RepsMin:         exercise.RepsMin,
+					RepsMax:         exercise.RepsMax,
+					LoadType:        exercise.LoadType,
+					LoadValue:       exercise.LoadValue,
+					Tempo:           exercise.Tempo,
+					RestSeconds:     exercise.RestSeconds,
+					RPE:             exercise.RPE,
+					DurationSeconds: exercise.DurationSeconds,
+					DistanceMeters:  exercise.DistanceMeters,
+					Notes:           exercise.Notes,
+				})
+			}
+
+			dayResponse.Workouts = append(dayResponse.Workouts, workoutResponse)
+		}
+		if len(day.Meals) > 0 {
+			meals, totals := buildPlanMealResponses(day.Meals)
+			dayResponse.Meals = meals
+			dayResponse.Totals = &totals
+		}
+
+		responses = append(responses, dayResponse)
+	}
+
+	return responses
+}
+
+// resolveCatalogExercises checks prescriptions that reference the exercise library
+// and copies the catalog name onto them so plans stay readable if an entry is removed
+func resolveCatalogExercises(db *gorm.DB, days []PlanDayRequest) error {
+	for d := range days {
+		for w := range days[d].Workouts {
+			for e := range days[d].Workouts[w].Exercises {
+				prescription := &days[d].Workouts[w].Exercises[e]
+				if prescription.ExerciseID == nil {
+					continue
+				}
+
+				var exercise models.Exercise
+				if err := db.First(&exercise, *prescription.ExerciseID).Error; err != nil {
+					return fmt.Errorf("day %d: exercise %d not found in the exercise library", days[d].DayNumber, *prescription.ExerciseID)
+				}
+				if exercise.Status != "approved" {
+					return fmt.Errorf("day %d: exercise %q is not approved yet", days[d].DayNumber, exercise.Name)
+				}
+				prescription.ExerciseName = exercise.Name
+			}
+		}
+	}
+	return nil
+}
+
+// preloadPlanContent loads a plan's days, workouts, exercises and meals in order
+func preloadPlanContent(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Days", func(db *gorm.DB) *gorm.DB { return db.Order("day_number") }).
+		Preload("Days.Workouts", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Days.Workouts.Exercises", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Days.Meals", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Days.Meals.Foods", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Days.Meals.Foods.Substitutions", func(db *gorm.DB) *gorm.DB { return db.Order("position") })
+}
@@ -0,0 +1,189 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestValidatePrescription(t *testing.T) {
+	tests := []struct {
+		name     string
+		exercise ExercisePrescriptionRequest
+		wantErr  string // substring of the error, empty when valid
+	}{
+		{"rep range", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 8, RepsMax: 12, LoadType: "kg", LoadValue: 60}, ""},
+		{"timed", ExercisePrescriptionRequest{ExerciseName: "Plank", Sets: 3, DurationSeconds: 45}, ""},
+		{"distance", ExercisePrescriptionRequest{ExerciseName: "Row", Sets: 1, DistanceMeters: 2000}, ""},
+		{"missing exercise", ExercisePrescriptionRequest{Sets: 3, RepsMin: 8}, "exercise_id or exercise_name"},
+		{"no sets", ExercisePrescriptionRequest{ExerciseName: "Squat", RepsMin: 8}, "sets must be"},
+		{"too many sets", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 21, RepsMin: 8}, "sets must be"},
+		{"no volume", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3}, "reps, duration_seconds or distance_meters"},
+		{"inverted range", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 12, RepsMax: 8}, "reps_min cannot be greater"},
+		{"too many reps", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 8, RepsMax: 101}, "reps must be"},
+		{"negative duration", ExercisePrescriptionRequest{ExerciseName: "Plank", Sets: 3, RepsMin: 1, DurationSeconds: -5}, "cannot be negative"},
+		{"load without type", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 8, LoadValue: 60}, "load_type is required"},
+		{"unknown load type", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 8, LoadType: "lbs", LoadValue: 60}, "load_type must be"},
+		{"kg out of range", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 8, LoadType: "kg"}, "load_value in kg"},
+		{"percent out of range", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 8, LoadType: "percent_1rm", LoadValue: 110}, "percent_1rm"},
+		{"rpe load out of range", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 8, LoadType: "rpe", LoadValue: 11}, "load_value as rpe"},
+		{"bodyweight", ExercisePrescriptionRequest{ExerciseName: "Push-up", Sets: 3, RepsMin: 10, LoadType: "bodyweight"}, ""},
+		{"explosive tempo", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 5, Tempo: "2-0-x-0"}, ""},
+		{"bad tempo", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 5, Tempo: "3-1-1"}, "tempo must look like"},
+		{"two digit tempo", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 5, Tempo: "10-1-1-0"}, "tempo must look like"},
+		{"rest too long", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 5, RestSeconds: 901}, "rest_seconds"},
+		{"rpe out of range", ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 5, RPE: 0.5}, "rpe must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePrescription(&tt.exercise)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidatePrescriptionNormalizes(t *testing.T) {
+	exercise := ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 5, Tempo: "3-1-x-0"}
+	if err := validatePrescription(&exercise); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A single rep count is a range of one, and X is upper case
+	if exercise.RepsMax != 5 || exercise.Tempo != "3-1-X-0" {
+		t.Errorf("expected reps_max 5 and tempo 3-1-X-0, got %d and %q", exercise.RepsMax, exercise.Tempo)
+	}
+}
+
+func TestValidatePlanDays(t *testing.T) {
+	squat := ExercisePrescriptionRequest{ExerciseName: "Squat", Sets: 3, RepsMin: 5}
+	workout := PlanWorkoutRequest{Name: "Lower", Exercises: []ExercisePrescriptionRequest{squat}}
+
+	tests := []struct {
+		name    string
+		days    []PlanDayRequest
+		wantErr string
+	}{
+		{"training and rest days", []PlanDayRequest{{DayNumber: 1, Workouts: []PlanWorkoutRequest{workout}}, {DayNumber: 2, IsRestDay: true}}, ""},
+		{"no content", nil, ""},
+		{"day past duration", []PlanDayRequest{{DayNumber: 8, Workouts: []PlanWorkoutRequest{workout}}}, "between 1 and the plan duration"},
+		{"day zero", []PlanDayRequest{{DayNumber: 0, IsRestDay: true}}, "between 1 and the plan duration"},
+		{"duplicate day", []PlanDayRequest{{DayNumber: 1, IsRestDay: true}, {DayNumber: 1, IsRestDay: true}}, "used more than once"},
+		{"rest day with workout", []PlanDayRequest{{DayNumber: 1, IsRestDay: true, Workouts: []PlanWorkoutRequest{workout}}}, "rest days cannot contain workouts"},
+		{"empty training day", []PlanDayRequest{{DayNumber: 1}}, "at least one workout"},
+		{"unnamed workout", []PlanDayRequest{{DayNumber: 1, Workouts: []PlanWorkoutRequest{{Name: " ", Exercises: workout.Exercises}}}}, "name is required"},
+		{"workout without exercises", []PlanDayRequest{{DayNumber: 1, Workouts: []PlanWorkoutRequest{{Name: "Lower"}}}}, "at least one exercise"},
+		{"invalid exercise", []PlanDayRequest{{DayNumber: 1, Workouts: []PlanWorkoutRequest{{Name: "Lower", Exercises: []ExercisePrescriptionRequest{{ExerciseName: "Squat"}}}}}}, `workout "Lower", exercise 1: sets must be`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePlanDays(tt.days, 7, "fitness")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildPlanDaysNumbersPositions(t *testing.T) {
+	squat := ExercisePrescriptionRequest{ExerciseName: " Squat ", Sets: 3, RepsMin: 5}
+	lunge := ExercisePrescriptionRequest{ExerciseName: "Lunge", Sets: 3, RepsMin: 10}
+	days := buildPlanDays([]PlanDayRequest{{DayNumber: 1, Workouts: []PlanWorkoutRequest{
+		{Name: "Strength", Exercises: []ExercisePrescriptionRequest{squat, lunge}},
+		{Name: "Conditioning", Exercises: []ExercisePrescriptionRequest{lunge}},
+	}}})
+
+	workouts := days[0].Workouts
+	if len(workouts) != 2 || workouts[0].Position != 1 || workouts[1].Position != 2 {
+		t.Fatalf("expected workouts at positions 1 and 2, got %+v", workouts)
+	}
+	exercises := workouts[0].Exercises
+	if exercises[0].Position != 1 || exercises[1].Position != 2 || exercises[0].ExerciseName != "Squat" {
+		t.Errorf("expected exercises numbered in order with trimmed names, got %+v", exercises)
+	}
+}
+
+func TestClonePlanDays(t *testing.T) {
+	stamp := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	exerciseID := uint(7)
+	foodID := uint(11)
+	days := []models.PlanDay{
+		{ID: 1, PlanID: 3, DayNumber: 1, Name: "Upper", CreatedAt: stamp, Workouts: []models.PlanWorkout{{
+			ID: 21, PlanDayID: 1, Name: "Push", Position: 1, CreatedAt: stamp,
+			Exercises: []models.ExercisePrescription{{
+				ID: 31, PlanWorkoutID: 21, Position: 1, ExerciseID: &exerciseID, ExerciseName: "Bench press",
+				Sets: 4, RepsMin: 6, RepsMax: 8, LoadType: "kg", LoadValue: 80, CreatedAt: stamp, UpdatedAt: stamp,
+				Exercise: &models.Exercise{ID: exerciseID, Name: "Bench press"},
+			}},
+		}}},
+		{ID: 2, PlanID: 3, DayNumber: 2, Name: "Eat", CreatedAt: stamp, Meals: []models.PlanMeal{{
+			ID: 41, PlanDayID: 2, Meal: "breakfast", Name: "Oats", Position: 1, CreatedAt: stamp,
+			Foods: []models.PlanMealFood{{
+				ID: 51, PlanMealID: 41, Position: 1, FoodID: &foodID, FoodName: "Oats", Grams: 80, Calories: 300, CreatedAt: stamp, UpdatedAt: stamp,
+				Substitutions: []models.PlanFoodSubstitution{{ID: 61, PlanMealFoodID: 51, FoodName: "Muesli", Grams: 85, CreatedAt: stamp, UpdatedAt: stamp}},
+			}},
+		}}},
+	}
+
+	clones := clonePlanDays(days)
+	if len(clones) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(clones))
+	}
+
+	day := clones[0]
+	if day.ID != 0 || day.PlanID != 0 || !day.CreatedAt.IsZero() || day.DayNumber != 1 || day.Name != "Upper" {
+		t.Errorf("day not copied as a new row: %+v", day)
+	}
+	if len(day.Workouts) != 1 || len(day.Workouts[0].Exercises) != 1 {
+		t.Fatalf("expected the workout and its exercise to be copied, got %+v", day.Workouts)
+	}
+	workout := day.Workouts[0]
+	if workout.ID != 0 || workout.PlanDayID != 0 || !workout.CreatedAt.IsZero() || workout.Name != "Push" || workout.Position != 1 {
+		t.Errorf("workout not copied as a new row: %+v", workout)
+	}
+	exercise := workout.Exercises[0]
+	if exercise.ID != 0 || exercise.PlanWorkoutID != 0 || exercise.Exercise != nil || !exercise.CreatedAt.IsZero() || !exercise.UpdatedAt.IsZero() {
+		t.Errorf("exercise not copied as a new row: %+v", exercise)
+	}
+	if exercise.ExerciseID == nil || *exercise.ExerciseID != exerciseID || exercise.ExerciseName != "Bench press" ||
+		exercise.Sets != 4 || exercise.RepsMin != 6 || exercise.RepsMax != 8 || exercise.LoadType != "kg" || exercise.LoadValue != 80 {
+		t.Errorf("exercise prescription not kept: %+v", exercise)
+	}
+
+	if len(clones[1].Meals) != 1 || len(clones[1].Meals[0].Foods) != 1 || len(clones[1].Meals[0].Foods[0].Substitutions) != 1 {
+		t.Fatalf("expected the meal, food and substitution to be copied, got %+v", clones[1].Meals)
+	}
+	meal := clones[1].Meals[0]
+	if meal.ID != 0 || meal.PlanDayID != 0 || !meal.CreatedAt.IsZero() || meal.Meal != "breakfast" || meal.Name != "Oats" {
+		t.Errorf("meal not copied as a new row: %+v", meal)
+	}
+	food := meal.Foods[0]
+	if food.ID != 0 || food.PlanMealID != 0 || !food.CreatedAt.IsZero() || food.FoodID == nil || *food.FoodID != foodID || food.Grams != 80 || food.Calories != 300 {
+		t.Errorf("food not copied as a new row: %+v", food)
+	}
+	substitution := food.Substitutions[0]
+	if substitution.ID != 0 || substitution.PlanMealFoodID != 0 || !substitution.CreatedAt.IsZero() || substitution.FoodName != "Muesli" || substitution.Grams != 85 {
+		t.Errorf("substitution not copied as a new row: %+v", substitution)
+	}
+
+	// The source plan is left untouched
+	if days[0].Workouts[0].Exercises[0].ID != 31 || days[1].Meals[0].Foods[0].Substitutions[0].ID != 61 {
+		t.Error("cloning changed the source plan")
+	}
+}
@@ -0,0 +1,873 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+	"fittrackplus/internal/notification"
+
+	"gorm.io/gorm"
+)
+
+// MealTypes are the meals a diet plan day can contain, in serving order
+var MealTypes = []string{"breakfast", "lunch", "dinner", "snack"}
+
+// Allergens are the tags foods can carry, following the common food labelling list
+var Allergens = []string{"milk", "eggs", "fish", "shellfish", "tree_nuts", "peanuts", "gluten", "soy", "sesame", "celery", "mustard", "sulphites"}
+
+// maxGroceryDays bounds the grocery list date range
+const maxGroceryDays = 31
+
+// PlanMealRequest describes a meal within a diet plan day
+type PlanMealRequest struct {
+	Meal  string                `json:"meal" binding:"required"` // breakfast, lunch, dinner, snack
+	Name  string                `json:"name"`
+	Notes string                `json:"notes"`
+	Foods []PlanMealFoodRequest `json:"foods"`
+}
+
+// PlanMealFoodRequest prescribes a quantity of a food or a recipe
+// Foods are given in grams; recipes in grams or servings
+type PlanMealFoodRequest struct {
+	FoodID        uint                      `json:"food_id"`
+	RecipeID      uint                      `json:"recipe_id"`
+	Grams         float64                   `json:"grams"`
+	Servings      float64                   `json:"servings"`
+	Notes         string                    `json:"notes"`
+	Substitutions []PlanSubstitutionRequest `json:"substitutions"`
+
+	food   *models.Food   // resolved from the food database, or the recipe as a food
+	recipe *models.Recipe // resolved recipe with its ingredients
+}
+
+// PlanSubstitutionRequest offers another food or recipe in place of a prescribed one
+type PlanSubstitutionRequest struct {
+	FoodID   uint    `json:"food_id"`
+	RecipeID uint    `json:"recipe_id"`
+	Grams    float64 `json:"grams"` // 0 matches the calories of the prescribed food
+
+	food   *models.Food
+	recipe *models.Recipe
+}
+
+// Macros are the energy and macronutrients of a food, meal or day
+type Macros struct {
+	Calories float64 `json:"calories"`
+	Protein  float64 `json:"protein"`
+	Carbs    float64 `json:"carbs"`
+	Fat      float64 `json:"fat"`
+	Fiber    float64 `json:"fiber"`
+}
+
+// PlanMealResponse represents a meal with its foods and totals
+type PlanMealResponse struct {
+	ID       uint                   `json:"id,omitempty"`
+	Meal     string                 `json:"meal"`
+	Name     string                 `json:"name,omitempty"`
+	Position int                    `json:"position"`
+	Notes    string                 `json:"notes,omitempty"`
+	Foods    []PlanMealFoodResponse `json:"foods"`
+	Totals   Macros                 `json:"totals"`
+}
+
+// PlanMealFoodResponse represents a prescribed food or recipe and its allowed substitutions
+type PlanMealFoodResponse struct {
+	ID       uint    `json:"id,omitempty"`
+	Position int     `json:"position"`
+	FoodID   *uint   `json:"food_id,omitempty"`
+	RecipeID *uint   `json:"recipe_id,omitempty"`
+	FoodName string  `json:"food_name"`
+	Category string  `json:"category,omitempty"`
+	Grams    float64 `json:"grams"`
+	Servings float64 `json:"servings,omitempty"` // recipe servings
+	Macros
+	Allergens     []string                   `json:"allergens,omitempty"`
+	Ingredients   []IngredientPortion        `json:"ingredients,omitempty"` // recipes only
+	Notes         string                     `json:"notes,omitempty"`
+	Substitutions []PlanSubstitutionResponse `json:"substitutions,omitempty"`
+}
+
+// IngredientPortion is the quantity of a recipe ingredient in a prescribed portion
+type IngredientPortion struct {
+	FoodID   *uint   `json:"food_id,omitempty"`
+	FoodName string  `json:"food_name"`
+	Category string  `json:"category"`
+	Grams    float64 `json:"grams"`
+}
+
+// PlanSubstitutionResponse represents a food allowed in place of a prescribed one
+type PlanSubstitutionResponse struct {
+	ID       uint    `json:"id,omitempty"`
+	Position int     `json:"position"`
+	FoodID   *uint   `json:"food_id,omitempty"`
+	RecipeID *uint   `json:"recipe_id,omitempty"`
+	FoodName string  `json:"food_name"`
+	Category string  `json:"category,omitempty"`
+	Grams    float64 `json:"grams"`
+	Macros
+	Allergens []string `json:"allergens,omitempty"`
+}
+
+// AllergyConflict flags a food in a diet plan that matches the member's allergies
+type AllergyConflict struct {
+	FoodName        string   `json:"food_name"`
+	Allergens       []string `json:"allergens,omitempty"` // allergen tags of the food that matched
+	Allergies       []string `json:"allergies"`           // entries from the member's profile
+	Days            []int    `json:"days"`                // plan days the food appears on
+	IsSubstitute    bool     `json:"is_substitute"`       // only offered as a substitution
+	SafeSubstitutes []string `json:"safe_substitutes,omitempty"`
+}
+
+// AllergyCheckResponse lists the conflicts between a diet plan and a member's allergies
+// The member's full allergy list is health data and is not returned
+type AllergyCheckResponse struct {
+	PlanID    uint              `json:"plan_id"`
+	UserID    uint              `json:"user_id"`
+	Conflicts []AllergyConflict `json:"conflicts"`
+}
+
+// GroceryItem is the total quantity of a food needed over the grocery list range
+type GroceryItem struct {
+	FoodID   *uint   `json:"food_id,omitempty"`
+	FoodName string  `json:"food_name"`
+	Category string  `json:"category"`
+	Grams    float64 `json:"grams"`
+	Days     []int   `json:"days"` // plan days the food is needed on
+}
+
+// GroceryCategory groups grocery items by food category
+type GroceryCategory struct {
+	Category string        `json:"category"`
+	Items    []GroceryItem `json:"items"`
+}
+
+// GroceryList is the shopping list for a diet plan over a date range
+type GroceryList struct {
+	UserPlanID uint              `json:"user_plan_id"`
+	PlanName   string            `json:"plan_name"`
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	PlanDays   []int             `json:"plan_days"` // plan days covered by the range
+	Categories []GroceryCategory `json:"categories"`
+	Totals     Macros            `json:"totals"`
+}
+
+// CheckAllergies compares a diet plan's working draft with a member's allergies,
+// so trainers can see conflicts before assigning it
+// The caller must be able to manage the plan; trainers may only check their clients
+// and members who asked them for this plan
+func (s *PlanService) CheckAllergies(planID, memberID, userID uint, userRole string) (*AllergyCheckResponse, error) {
+	var plan models.Plan
+	if err := preloadPlanContent(s.db).First(&plan, planID).Error; err != nil {
+		return nil, errors.New("plan not found")
+	}
+	if !canManagePlan(&plan, userID, userRole) {
+		return nil, errors.New("you can only manage your own plans or shared library plans")
+	}
+	if userRole != "admin" && !IsAssignedTrainer(s.db, userID, memberID) && !s.hasPendingRequest(memberID, planID, userID) {
+		return nil, errors.New("you can only check allergies of your clients")
+	}
+	if plan.PlanType != "diet" {
+		return nil, errors.New("allergy checks are only available for diet plans")
+	}
+
+	return &AllergyCheckResponse{
+		PlanID:    plan.ID,
+		UserID:    memberID,
+		Conflicts: findAllergyConflicts(buildPlanDayResponses(plan.Days), s.memberAllergies(memberID)),
+	}, nil
+}
+
+// hasPendingRequest reports whether the member asked for the plan and the request is routed to the trainer
+func (s *PlanService) hasPendingRequest(memberID, planID, trainerID uint) bool {
+	var count int64
+	s.db.Model(&models.PlanAssignmentRequest{}).
+		Where("user_id = ? AND plan_id = ? AND reviewer_id = ? AND status = ?", memberID, planID, trainerID, "pending").
+		Count(&count)
+	return count > 0
+}
+
+// GetGroceryList totals the foods of a member's diet plan between two dates
+// The range defaults to the next 7 days; to is exclusive
+func (s *PlanService) GetGroceryList(userPlanID, userID uint, from, to *time.Time) (*GroceryList, error) {
+	var userPlan models.UserPlan
+	err := s.db.Where("id = ? AND user_id = ?", userPlanID, userID).
+		Preload("Plan").Preload("PlanVersion").
+		First(&userPlan).Error
+	if err != nil {
+		return nil, errors.New("user plan not found")
+	}
+	if userPlanType(&userPlan) != "diet" {
+		return nil, errors.New("grocery lists are only available for diet plans")
+	}
+
+	start := utils.Today()
+	if from != nil {
+		start = utils.DateOnly(*from)
+	}
+	end := start.AddDate(0, 0, 7)
+	if to != nil {
+		end = utils.DateOnly(*to)
+	}
+	if !start.Before(end) {
+		return nil, errors.New("from must be before to")
+	}
+	if daysBetween(start, end) > maxGroceryDays {
+		return nil, fmt.Errorf("the date range cannot exceed %d days", maxGroceryDays)
+	}
+
+	list := buildGroceryList(s.userPlanDays(&userPlan), userPlan.AssignedAt, userPlanDuration(&userPlan), start, end)
+	list.UserPlanID = userPlan.ID
+	list.PlanName = userPlan.Plan.Name
+	if userPlan.PlanVersion != nil {
+		list.PlanName = userPlan.PlanVersion.Name
+	}
+	return list, nil
+}
+
+// memberAllergies reads the allergies from the member's profile
+func (s *PlanService) memberAllergies(memberID uint) []string {
+	var profile models.UserProfile
+	if err := s.db.Where("user_id = ?", memberID).First(&profile).Error; err != nil {
+		return []string{}
+	}
+	return ParseAllergies(profile.Allergies)
+}
+
+// flagAllergyConflicts checks a newly assigned diet plan against the member's allergies
+// Conflicts do not block the assignment; they are returned to the assigner and the
+// member is warned
+func (s *PlanService) flagAllergyConflicts(userPlan *models.UserPlan, response *UserPlanResponse) {
+	if userPlanType(userPlan) != "diet" || userPlan.PlanVersion == nil {
+		return
+	}
+	conflicts := findAllergyConflicts(decodePlanContent(userPlan.PlanVersion.Content), s.memberAllergies(userPlan.UserID))
+	if len(conflicts) == 0 {
+		return
+	}
+	response.AllergyConflicts = conflicts
+
+	names := []string{}
+	for _, conflict := range conflicts {
+		if !conflict.IsSubstitute {
+			names = append(names, conflict.FoodName)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	s.notificationService.Notify(userPlan.UserID, notification.Message{
+		Type:     "warning",
+		Category: "plan",
+		Title:    "Check your diet plan",
+		Message:  fmt.Sprintf("%s in your new diet plan may conflict with your allergies. Use a safe substitution or ask your trainer.", strings.Join(names, ", ")),
+		Link:     fmt.Sprintf("/plans/my-plans/%d", userPlan.ID),
+	})
+}
+
+// validatePlanMeals checks the meals of a diet plan day
+func validatePlanMeals(day *PlanDayRequest) error {
+	for m := range day.Meals {
+		meal := &day.Meals[m]
+		meal.Meal = strings.ToLower(strings.TrimSpace(meal.Meal))
+		if !containsString(MealTypes, meal.Meal) {
+			return fmt.Errorf("day %d, meal %d: meal must be one of %s", day.DayNumber, m+1, strings.Join(MealTypes, ", "))
+		}
+		if len(meal.Foods) == 0 {
+			return fmt.Errorf("day %d, meal %d: at least one food is required", day.DayNumber, m+1)
+		}
+
+		for f, food := range meal.Foods {
+			if (food.FoodID == 0) == (food.RecipeID == 0) {
+				return fmt.Errorf("day %d, meal %d, food %d: give either food_id or recipe_id", day.DayNumber, m+1, f+1)
+			}
+			if food.RecipeID != 0 && food.Servings != 0 {
+				if food.Grams != 0 {
+					return fmt.Errorf("day %d, meal %d, food %d: give the portion in grams or servings, not both", day.DayNumber, m+1, f+1)
+				}
+				if food.Servings < 0 || food.Servings > 20 {
+					return fmt.Errorf("day %d, meal %d, food %d: servings must be between 0 and 20", day.DayNumber, m+1, f+1)
+				}
+			} else if food.Grams <= 0 || food.Grams > 2000 {
+				return fmt.Errorf("day %d, meal %d, food %d: grams must be between 0 and 2000", day.DayNumber, m+1, f+1)
+			}
+			for _, substitution := range food.Substitutions {
+				if (substitution.FoodID == 0) == (substitution.RecipeID == 0) {
+					return fmt.Errorf("day %d, meal %d, food %d: substitutions need either a food_id or a recipe_id", day.DayNumber, m+1, f+1)
+				}
+				if substitution.FoodID == food.FoodID && substitution.RecipeID == food.RecipeID {
+					return fmt.Errorf("day %d, meal %d, food %d: a food cannot substitute itself", day.DayNumber, m+1, f+1)
+				}
+				if substitution.Grams < 0 || substitution.Grams > 2000 {
+					return fmt.Errorf("day %d, meal %d, food %d: substitution grams must be between 0 and 2000", day.DayNumber, m+1, f+1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolveDietFoods checks the foods and recipes referenced by diet plan days
+// Private entries belong to a single member, so plans can only use shared ones
+func resolveDietFoods(db *gorm.DB, days []PlanDayRequest) error {
+	foods := map[uint]*models.Food{}
+	lookupFood := func(day int, id uint) (*models.Food, error) {
+		if food, ok := foods[id]; ok {
+			return food, nil
+		}
+		var food models.Food
+		if err := db.First(&food, id).Error; err != nil {
+			return nil, fmt.Errorf("day %d: food %d not found in the food database", day, id)
+		}
+		if food.IsPrivate {
+			return nil, fmt.Errorf("day %d: %q is a private food and cannot be used in plans", day, food.Name)
+		}
+		foods[id] = &food
+		return &food, nil
+	}
+
+	recipes := map[uint]*models.Recipe{}
+	lookupRecipe := func(day int, id uint) (*models.Recipe, error) {
+		if recipe, ok := recipes[id]; ok {
+			return recipe, nil
+		}
+		var recipe models.Recipe
+		err := db.Preload("Ingredients", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).First(&recipe, id).Error
+		if err != nil {
+			return nil, fmt.Errorf("day %d: recipe %d not found", day, id)
+		}
+		if recipe.IsPrivate {
+			return nil, fmt.Errorf("day %d: %q is a private recipe and cannot be used in plans", day, recipe.Name)
+		}
+		recipes[id] = &recipe
+		return &recipe, nil
+	}
+
+	var err error
+	for d := range days {
+		for m := range days[d].Meals {
+			for f := range days[d].Meals[m].Foods {
+				item := &days[d].Meals[m].Foods[f]
+				if item.RecipeID != 0 {
+					if item.recipe, err = lookupRecipe(days[d].DayNumber, item.RecipeID); err != nil {
+						return err
+					}
+					item.food = RecipeFood(item.recipe)
+					if item.Servings != 0 {
+						item.Grams = utils.RoundTenth(item.Servings * item.recipe.ServingGrams)
+					}
+				} else if item.food, err = lookupFood(days[d].DayNumber, item.FoodID); err != nil {
+					return err
+				}
+
+				for i := range item.Substitutions {
+					substitution := &item.Substitutions[i]
+					if substitution.RecipeID != 0 {
+						if substitution.recipe, err = lookupRecipe(days[d].DayNumber, substitution.RecipeID); err != nil {
+							return err
+						}
+						substitution.food = RecipeFood(substitution.recipe)
+					} else if substitution.food, err = lookupFood(days[d].DayNumber, substitution.FoodID); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RecipeFood describes a recipe as a food with nutrients per 100 g of the finished dish,
+// so portions of it scale like any other food; one serving is the recipe's serving weight
+func RecipeFood(recipe *models.Recipe) *models.Food {
+	food := &models.Food{
+		Name:         recipe.Name,
+		Category:     "recipe",
+		Allergens:    recipe.Allergens,
+		ServingGrams: recipe.ServingGrams,
+		ServingLabel: "1 serving",
+		IsPrivate:    recipe.IsPrivate,
+		CreatedBy:    recipe.CreatedBy,
+	}
+	if recipe.ServingGrams > 0 {
+		factor := 100 / recipe.ServingGrams
+		food.Calories = recipe.Calories * factor
+		food.Protein = recipe.Protein * factor
+		food.Carbs = recipe.Carbs * factor
+		food.Fat = recipe.Fat * factor
+		food.Fiber = recipe.Fiber * factor
+	}
+	return food
+}
+
+// recipePortion scales a recipe's ingredients to a portion of the finished dish
+func recipePortion(recipe *models.Recipe, grams float64) []IngredientPortion {
+	portion := []IngredientPortion{}
+	if recipe.YieldGrams <= 0 {
+		return portion
+	}
+	factor := grams / recipe.YieldGrams
+	for _, ingredient := range recipe.Ingredients {
+		portion = append(portion, IngredientPortion{
+			FoodID:   ingredient.FoodID,
+			FoodName: ingredient.FoodName,
+			Category: ingredient.Category,
+			Grams:    utils.RoundTenth(ingredient.Grams * factor),
+		})
+	}
+	return portion
+}
+
+// buildPlanMeals converts resolved meal requests into models, copying the food details
+func buildPlanMeals(meals []PlanMealRequest) []models.PlanMeal {
+	planMeals := []models.PlanMeal{}
+
+	for m, meal := range meals {
+		planMeal := models.PlanMeal{
+			Meal:     meal.Meal,
+			Name:     strings.TrimSpace(meal.Name),
+			Position: m + 1,
+			Notes:    meal.Notes,
+		}
+
+		for f, item := range meal.Foods {
+			macros := foodMacros(item.food, item.Grams)
+			planFood := models.PlanMealFood{
+				Position:  f + 1,
+				FoodName:  item.food.Name,
+				Category:  item.food.Category,
+				Grams:     item.Grams,
+				Calories:  macros.Calories,
+				Protein:   macros.Protein,
+				Carbs:     macros.Carbs,
+				Fat:       macros.Fat,
+				Fiber:     macros.Fiber,
+				Allergens: item.food.Allergens,
+				Notes:     item.Notes,
+			}
+			if item.recipe != nil {
+				ingredients, _ := json.Marshal(recipePortion(item.recipe, item.Grams))
+				planFood.RecipeID = &item.recipe.ID
+				planFood.Servings = item.Servings
+				planFood.Ingredients = string(ingredients)
+			} else {
+				planFood.FoodID = &item.food.ID
+			}
+
+			for i, substitution := range item.Substitutions {
+				grams := substitution.Grams
+				if grams == 0 {
+					grams = equivalentGrams(macros.Calories, substitution.food, item.Grams)
+				}
+				subMacros := foodMacros(substitution.food, grams)
+				planSubstitution := models.PlanFoodSubstitution{
+					Position:  i + 1,
+					FoodName:  substitution.food.Name,
+					Category:  substitution.food.Category,
+					Grams:     grams,
+					Calories:  subMacros.Calories,
+					Protein:   subMacros.Protein,
+					Carbs:     subMacros.Carbs,
+					Fat:       subMacros.Fat,
+					Fiber:     subMacros.Fiber,
+					Allergens: substitution.food.Allergens,
+				}
+				if substitution.recipe != nil {
+					planSubstitution.RecipeID = &substitution.recipe.ID
+				} else {
+					planSubstitution.FoodID = &substitution.food.ID
+				}
+				planFood.Substitutions = append(planFood.Substitutions, planSubstitution)
+			}
+
+			planMeal.Foods = append(planMeal.Foods, planFood)
+		}
+
+		planMeals = append(planMeals, planMeal)
+	}
+
+	return planMeals
+}
+
+// clonePlanMeals copies loaded meals into new, unsaved models
+func clonePlanMeals(meals []models.PlanMeal) []models.PlanMeal {
+	clones := []models.PlanMeal{}
+
+	for _, meal := range meals {
+		mealClone := models.PlanMeal{
+			Meal:     meal.Meal,
+			Name:     meal.Name,
+			Position: meal.Position,
+			Notes:    meal.Notes,
+		}
+		for _, food := range meal.Foods {
+			foodClone := food
+			foodClone.ID = 0
+			foodClone.PlanMealID = 0
+			foodClone.CreatedAt = time.Time{}
+			foodClone.UpdatedAt = time.Time{}
+			foodClone.Substitutions = nil
+			for _, substitution := range food.Substitutions {
+				substitution.ID = 0
+				substitution.PlanMealFoodID = 0
+				substitution.CreatedAt = time.Time{}
+				substitution.UpdatedAt = time.Time{}
+				foodClone.Substitutions = append(foodClone.Substitutions, substitution)
+			}
+			mealClone.Foods = append(mealClone.Foods, foodClone)
+		}
+		clones = append(clones, mealClone)
+	}
+
+	return clones
+}
+
+// buildPlanMealResponses converts loaded meals into their response format and
+// returns them with the day's totals
+func buildPlanMealResponses(meals []models.PlanMeal) ([]PlanMealResponse, Macros) {
+	responses := []PlanMealResponse{}
+	var dayTotals Macros
+
+	for _, meal := range meals {
+		mealResponse := PlanMealResponse{
+			ID:       meal.ID,
+			Meal:     meal.Meal,
+			Name:     meal.Name,
+			Position: meal.Position,
+			Notes:    meal.Notes,
+			Foods:    []PlanMealFoodResponse{},
+		}
+
+		for _, food := range meal.Foods {
+			foodResponse := PlanMealFoodResponse{
+				ID:        food.ID,
+				Position:  food.Position,
+				FoodID:    food.FoodID,
+				RecipeID:  food.RecipeID,
+				FoodName:  food.FoodName,
+				Category:  food.Category,
+				Grams:     food.Grams,
+				Servings:  food.Servings,
+				Macros:    Macros{Calories: food.Calories, Protein: food.Protein, Carbs: food.Carbs, Fat: food.Fat, Fiber: food.Fiber},
+				Allergens: decodeAllergens(food.Allergens),
+				Notes:     food.Notes,
+			}
+			if food.Ingredients != "" {
+				json.Unmarshal([]byte(food.Ingredients), &foodResponse.Ingredients)
+			}
+			for _, substitution := range food.Substitutions {
+				foodResponse.Substitutions = append(foodResponse.Substitutions, PlanSubstitutionResponse{
+					ID:        substitution.ID,
+					Position:  substitution.Position,
+					FoodID:    substitution.FoodID,
+					RecipeID:  substitution.RecipeID,
+					FoodName:  substitution.FoodName,
+					Category:  substitution.Category,
+					Grams:     substitution.Grams,
+					Macros:    Macros{Calories: substitution.Calories, Protein: substitution.Protein, Carbs: substitution.Carbs, Fat: substitution.Fat, Fiber: substitution.Fiber},
+					Allergens: decodeAllergens(substitution.Allergens),
+				})
+			}
+
+			mealResponse.Totals = addMacros(mealResponse.Totals, foodResponse.Macros)
+			mealResponse.Foods = append(mealResponse.Foods, foodResponse)
+		}
+
+		dayTotals = addMacros(dayTotals, mealResponse.Totals)
+		responses = append(responses, mealResponse)
+	}
+
+	return responses, dayTotals
+}
+
+// foodMacros scales a food's nutrients per 100 g to a portion
+func foodMacros(food *models.Food, grams float64) Macros {
+	factor := grams / 100
+	return Macros{
+		Calories: math.Round(food.Calories * factor),
+		Protein:  utils.RoundTenth(food.Protein * factor),
+		Carbs:    utils.RoundTenth(food.Carbs * factor),
+		Fat:      utils.RoundTenth(food.Fat * factor),
+		Fiber:    utils.RoundTenth(food.Fiber * factor),
+	}
+}
+
+// equivalentGrams is the portion of a substitute with the same calories, rounded to 5 g
+// Foods without calories (e.g. black coffee) keep the prescribed quantity
+func equivalentGrams(calories float64, substitute *models.Food, fallback float64) float64 {
+	if substitute.Calories <= 0 || calories <= 0 {
+		return fallback
+	}
+	grams := math.Round(calories/substitute.Calories*100/5) * 5
+	return math.Max(5, math.Min(grams, 2000))
+}
+
+func addMacros(a, b Macros) Macros {
+	return Macros{
+		Calories: math.Round(a.Calories + b.Calories),
+		Protein:  utils.RoundTenth(a.Protein + b.Protein),
+		Carbs:    utils.RoundTenth(a.Carbs + b.Carbs),
+		Fat:      utils.RoundTenth(a.Fat + b.Fat),
+		Fiber:    utils.RoundTenth(a.Fiber + b.Fiber),
+	}
+}
+
+// allergySynonyms maps words found in free-text allergies to allergen tags
+// More specific words come first; the first match wins
+var allergySynonyms = []struct {
+	words     []string
+	allergens []string
+}{
+	{[]string{"peanut", "groundnut"}, []string{"peanuts"}},
+	{[]string{"tree nut", "almond", "cashew", "walnut", "hazelnut", "pecan", "pistachio", "macadamia", "brazil nut"}, []string{"tree_nuts"}},
+	{[]string{"nut"}, []string{"tree_nuts", "peanuts"}},
+	{[]string{"shellfish", "crustacean", "mollus", "shrimp", "prawn", "crab", "lobster", "mussel", "oyster", "scallop", "squid"}, []string{"shellfish"}},
+	{[]string{"seafood"}, []string{"fish", "shellfish"}},
+	{[]string{"fish", "salmon", "tuna", "cod"}, []string{"fish"}},
+	{[]string{"milk", "dairy", "lactose", "casein", "whey", "cheese", "yogurt", "yoghurt"}, []string{"milk"}},
+	{[]string{"egg"}, []string{"eggs"}},
+	{[]string{"gluten", "wheat", "coeliac", "celiac", "barley", "rye", "spelt"}, []string{"gluten"}},
+	{[]string{"soy", "tofu", "edamame"}, []string{"soy"}},
+	{[]string{"sesame", "tahini"}, []string{"sesame"}},
+	{[]string{"celery", "celeriac"}, []string{"celery"}},
+	{[]string{"mustard"}, []string{"mustard"}},
+	{[]string{"sulphite", "sulfite"}, []string{"sulphites"}},
+}
+
+// ParseAllergies splits the free-text allergies of a profile into separate entries,
+// e.g. "Peanuts, shellfish and lactose" becomes peanuts, shellfish, lactose
+func ParseAllergies(text string) []string {
+	text = strings.ToLower(text)
+	for _, separator := range []string{";", "/", "\n", " and ", "&"} {
+		text = strings.ReplaceAll(text, separator, ",")
+	}
+
+	allergies := []string{}
+	for _, part := range strings.Split(text, ",") {
+		part = strings.Trim(strings.TrimSpace(part), ".")
+		switch part {
+		case "", "none", "n/a", "na", "no", "nil", "-":
+			continue
+		}
+		if !containsString(allergies, part) {
+			allergies = append(allergies, part)
+		}
+	}
+	return allergies
+}
+
+// allergyAllergens returns the allergen tags an allergy entry refers to
+func allergyAllergens(allergy string) []string {
+	for _, synonym := range allergySynonyms {
+		for _, word := range synonym.words {
+			if strings.Contains(allergy, word) {
+				return synonym.allergens
+			}
+		}
+	}
+	return nil
+}
+
+// allergyStem reduces an allergy entry to a stem that also matches plural and singular
+// food names, e.g. "strawberries" and "strawberry" both become "strawberr"
+func allergyStem(allergy string) string {
+	switch {
+	case strings.HasSuffix(allergy, "ies"):
+		allergy = strings.TrimSuffix(allergy, "ies")
+	case strings.HasSuffix(allergy, "y"):
+		allergy = strings.TrimSuffix(allergy, "y")
+	case strings.HasSuffix(allergy, "es"):
+		allergy = strings.TrimSuffix(allergy, "es")
+	case strings.HasSuffix(allergy, "s"):
+		allergy = strings.TrimSuffix(allergy, "s")
+	}
+	return allergy
+}
+
+// matchAllergies returns the allergies a food matches, either through its allergen tags
+// or by name, and the allergen tags involved
+func matchAllergies(foodName string, foodAllergens, allergies []string) ([]string, []string) {
+	name := strings.ToLower(foodName)
+	matched, tags := []string{}, []string{}
+
+	for _, allergy := range allergies {
+		hit := false
+		for _, allergen := range allergyAllergens(allergy) {
+			if containsString(foodAllergens, allergen) {
+				hit = true
+				if !containsString(tags, allergen) {
+					tags = append(tags, allergen)
+				}
+			}
+		}
+		if stem := allergyStem(allergy); len(stem) >= 3 && strings.Contains(name, stem) {
+			hit = true
+		}
+		if hit {
+			matched = append(matched, allergy)
+		}
+	}
+	return matched, tags
+}
+
+// findAllergyConflicts lists the foods of a diet plan that match the allergies,
+// grouped by food, with the substitutions that are safe to use instead
+func findAllergyConflicts(days []PlanDayResponse, allergies []string) []AllergyConflict {
+	conflicts := []AllergyConflict{}
+	if len(allergies) == 0 {
+		return conflicts
+	}
+
+	index := map[string]int{}
+	record := func(name string, foodAllergens []string, day int, substitute bool) *AllergyConflict {
+		matched, tags := matchAllergies(name, foodAllergens, allergies)
+		if len(matched) == 0 {
+			return nil
+		}
+		key := fmt.Sprintf("%s|%t", name, substitute)
+		i, ok := index[key]
+		if !ok {
+			i = len(conflicts)
+			index[key] = i
+			conflicts = append(conflicts, AllergyConflict{FoodName: name, Allergens: tags, Allergies: matched, Days: []int{}, IsSubstitute: substitute})
+		}
+		conflict := &conflicts[i]
+		if n := len(conflict.Days); n == 0 || conflict.Days[n-1] != day {
+			conflict.Days = append(conflict.Days, day)
+		}
+		return conflict
+	}
+
+	for _, day := range days {
+		for _, meal := range day.Meals {
+			for _, food := range meal.Foods {
+				conflict := record(food.FoodName, food.Allergens, day.DayNumber, false)
+				if conflict == nil {
+					// Recipes also conflict through an ingredient's name
+					for _, ingredient := range food.Ingredients {
+						if matched, _ := matchAllergies(ingredient.FoodName, nil, allergies); len(matched) > 0 {
+							conflict = record(food.FoodName+" ("+ingredient.FoodName+")", nil, day.DayNumber, false)
+							break
+						}
+					}
+				}
+				for _, substitution := range food.Substitutions {
+					if record(substitution.FoodName, substitution.Allergens, day.DayNumber, true) != nil {
+						continue
+					}
+					if conflict != nil && !containsString(conflict.SafeSubstitutes, substitution.FoodName) {
+						conflict.SafeSubstitutes = append(conflict.SafeSubstitutes, substitution.FoodName)
+					}
+				}
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// buildGroceryList totals the foods of the plan days falling in [start, end)
+// Plan day 1 is the assignment date and the content repeats over the plan duration
+func buildGroceryList(days map[int]PlanDayResponse, assignedAt time.Time, duration int, start, end time.Time) *GroceryList {
+	list := &GroceryList{
+		From:       start.Format("2006-01-02"),
+		To:         end.AddDate(0, 0, -1).Format("2006-01-02"),
+		PlanDays:   []int{},
+		Categories: []GroceryCategory{},
+	}
+
+	cycle := cycleLength(days)
+	items := map[string]*GroceryItem{}
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		if date.Before(utils.DateOnly(assignedAt)) {
+			continue
+		}
+		planDay := daysBetween(assignedAt, date) + 1
+		if planDay > duration {
+			break
+		}
+		day, ok := days[(planDay-1)%cycle+1]
+		if !ok || day.IsRestDay {
+			continue
+		}
+		list.PlanDays = append(list.PlanDays, planDay)
+
+		for _, meal := range day.Meals {
+			list.Totals = addMacros(list.Totals, meal.Totals)
+			for _, food := range groceryFoods(meal.Foods) {
+				key := food.FoodName
+				if food.FoodID != nil {
+					key = fmt.Sprintf("#%d", *food.FoodID)
+				}
+				item, ok := items[key]
+				if !ok {
+					category := food.Category
+					if category == "" {
+						category = "other"
+					}
+					item = &GroceryItem{FoodID: food.FoodID, FoodName: food.FoodName, Category: category, Days: []int{}}
+					items[key] = item
+				}
+				item.Grams += food.Grams
+				if n := len(item.Days); n == 0 || item.Days[n-1] != planDay {
+					item.Days = append(item.Days, planDay)
+				}
+			}
+		}
+	}
+
+	byCategory := map[string][]GroceryItem{}
+	for _, item := range items {
+		item.Grams = utils.RoundTenth(item.Grams)
+		byCategory[item.Category] = append(byCategory[item.Category], *item)
+	}
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		categoryItems := byCategory[category]
+		sort.Slice(categoryItems, func(i, j int) bool { return categoryItems[i].FoodName < categoryItems[j].FoodName })
+		list.Categories = append(list.Categories, GroceryCategory{Category: category, Items: categoryItems})
+	}
+
+	return list
+}
+
+// groceryFoods lists the foods to buy for a meal, with recipes broken down into ingredients
+func groceryFoods(foods []PlanMealFoodResponse) []IngredientPortion {
+	items := []IngredientPortion{}
+	for _, food := range foods {
+		if food.RecipeID != nil {
+			items = append(items, food.Ingredients...)
+			continue
+		}
+		items = append(items, IngredientPortion{FoodID: food.FoodID, FoodName: food.FoodName, Category: food.Category, Grams: food.Grams})
+	}
+	return items
+}
+
+func decodeAllergens(value string) []string {
+	allergens := []string{}
+	if value != "" {
+		json.Unmarshal([]byte(value), &allergens)
+	}
+	return allergens
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
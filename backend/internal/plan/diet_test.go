@@ -0,0 +1,163 @@
+package plan
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestParseAllergies(t *testing.T) {
+	allergies := ParseAllergies("Peanuts, shellfish and Lactose; strawberries / none")
+	expected := []string{"peanuts", "shellfish", "lactose", "strawberries"}
+	if len(allergies) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, allergies)
+	}
+	for i := range expected {
+		if allergies[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, allergies)
+		}
+	}
+	if len(ParseAllergies("None")) != 0 {
+		t.Error("expected no allergies")
+	}
+}
+
+func TestValidateDietPlanDays(t *testing.T) {
+	meal := PlanMealRequest{Meal: "Breakfast", Foods: []PlanMealFoodRequest{{FoodID: 1, Grams: 80}}}
+	days := []PlanDayRequest{{DayNumber: 1, Meals: []PlanMealRequest{meal}}, {DayNumber: 2, IsRestDay: true}}
+	if err := validatePlanDays(days, 7, "diet"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if days[0].Meals[0].Meal != "breakfast" {
+		t.Errorf("expected the meal type to be normalized, got %q", days[0].Meals[0].Meal)
+	}
+
+	if err := validatePlanDays(days, 7, "fitness"); err == nil {
+		t.Error("expected meals in a fitness plan to be rejected")
+	}
+	if err := validatePlanDays([]PlanDayRequest{{DayNumber: 1}}, 7, "diet"); err == nil {
+		t.Error("expected a diet day without meals to be rejected")
+	}
+
+	meal.Foods = []PlanMealFoodRequest{{FoodID: 1, Grams: 80, Substitutions: []PlanSubstitutionRequest{{FoodID: 1}}}}
+	if err := validatePlanDays([]PlanDayRequest{{DayNumber: 1, Meals: []PlanMealRequest{meal}}}, 7, "diet"); err == nil {
+		t.Error("expected a food substituting itself to be rejected")
+	}
+}
+
+func TestBuildPlanMeals(t *testing.T) {
+	oats := &models.Food{ID: 1, Name: "Oats, rolled", Category: "grains", Calories: 379, Protein: 13.2, Carbs: 60, Fat: 6.5, Fiber: 10.1, Allergens: `["gluten"]`}
+	yogurt := &models.Food{ID: 2, Name: "Greek Yogurt", Category: "dairy", Calories: 59, Protein: 10.3, Carbs: 3.6, Fat: 0.4, Allergens: `["milk"]`}
+	quinoa := &models.Food{ID: 3, Name: "Quinoa, cooked", Category: "grains", Calories: 120, Protein: 4.4, Carbs: 19.4, Fat: 1.9, Fiber: 2.8}
+
+	meals := buildPlanMeals([]PlanMealRequest{{Meal: "breakfast", Foods: []PlanMealFoodRequest{
+		{Grams: 50, food: oats, Substitutions: []PlanSubstitutionRequest{{food: quinoa}}},
+		{Grams: 170, food: yogurt},
+	}}})
+	oatsFood := meals[0].Foods[0]
+	if oatsFood.Calories != 190 || oatsFood.Protein != 6.6 || oatsFood.Allergens != `["gluten"]` {
+		t.Errorf("unexpected oats portion %+v", oatsFood)
+	}
+	// 190 kcal of quinoa is ~158 g, rounded to 160
+	if oatsFood.Substitutions[0].Grams != 160 || oatsFood.Substitutions[0].Calories != 192 {
+		t.Errorf("expected a calorie-matched substitution, got %+v", oatsFood.Substitutions[0])
+	}
+
+	responses, totals := buildPlanMealResponses(meals)
+	if responses[0].Totals.Calories != 290 || responses[0].Totals.Protein != 24.1 || totals.Calories != 290 {
+		t.Errorf("unexpected totals %+v / %+v", responses[0].Totals, totals)
+	}
+}
+
+func TestFindAllergyConflicts(t *testing.T) {
+	days := []PlanDayResponse{
+		{DayNumber: 1, Meals: []PlanMealResponse{{Foods: []PlanMealFoodResponse{
+			{FoodName: "Greek Yogurt", Allergens: []string{"milk"}, Substitutions: []PlanSubstitutionResponse{
+				{FoodName: "Soy Yogurt", Allergens: []string{"soy"}},
+				{FoodName: "Cottage Cheese", Allergens: []string{"milk"}},
+			}},
+			{FoodName: "Strawberries"},
+			{FoodName: "Banana"},
+		}}}},
+		{DayNumber: 2, Meals: []PlanMealResponse{{Foods: []PlanMealFoodResponse{{FoodName: "Greek Yogurt", Allergens: []string{"milk"}}}}}},
+	}
+
+	conflicts := findAllergyConflicts(days, ParseAllergies("dairy, strawberry"))
+	if len(conflicts) != 3 {
+		t.Fatalf("expected yogurt, cottage cheese and strawberries, got %+v", conflicts)
+	}
+	yogurt := conflicts[0]
+	if yogurt.FoodName != "Greek Yogurt" || len(yogurt.Days) != 2 || yogurt.Allergens[0] != "milk" || yogurt.Allergies[0] != "dairy" {
+		t.Errorf("unexpected yogurt conflict %+v", yogurt)
+	}
+	if len(yogurt.SafeSubstitutes) != 1 || yogurt.SafeSubstitutes[0] != "Soy Yogurt" {
+		t.Errorf("expected soy yogurt as the only safe substitute, got %v", yogurt.SafeSubstitutes)
+	}
+	if !conflicts[1].IsSubstitute || conflicts[2].FoodName != "Strawberries" {
+		t.Errorf("unexpected conflicts %+v", conflicts[1:])
+	}
+}
+
+func TestBuildGroceryList(t *testing.T) {
+	oatsID, eggID := uint(1), uint(2)
+	breakfast := PlanMealResponse{Foods: []PlanMealFoodResponse{
+		{FoodID: &oatsID, FoodName: "Oats", Category: "grains", Grams: 50},
+		{FoodID: &eggID, FoodName: "Egg", Category: "protein", Grams: 100},
+	}}
+	days := map[int]PlanDayResponse{
+		1: {DayNumber: 1, Meals: []PlanMealResponse{breakfast}},
+		2: {DayNumber: 2, IsRestDay: true},
+	}
+
+	assigned := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	from := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	list := buildGroceryList(days, assigned, 5, from, from.AddDate(0, 0, 7))
+
+	// Plan days 2-5 fall in the range: 3 and 5 follow day 1 of the 2-day cycle
+	if len(list.PlanDays) != 2 || list.PlanDays[0] != 3 || list.PlanDays[1] != 5 {
+		t.Fatalf("unexpected plan days %v", list.PlanDays)
+	}
+	if len(list.Categories) != 2 || list.Categories[0].Category != "grains" {
+		t.Fatalf("expected items grouped by category, got %+v", list.Categories)
+	}
+	if oats := list.Categories[0].Items[0]; oats.Grams != 100 || len(oats.Days) != 2 {
+		t.Errorf("unexpected oats item %+v", oats)
+	}
+}
+
+func TestRecipeMealItems(t *testing.T) {
+	oatsID := uint(1)
+	recipe := &models.Recipe{ID: 7, Name: "Overnight Oats", Servings: 2, YieldGrams: 500, ServingGrams: 250, Calories: 300, Protein: 12,
+		Allergens: `["gluten","milk"]`,
+		Ingredients: []models.RecipeIngredient{
+			{FoodID: &oatsID, FoodName: "Oats", Category: "grains", Grams: 80},
+			{FoodName: "Milk", Category: "dairy", Grams: 420},
+		}}
+
+	food := RecipeFood(recipe)
+	if food.Calories != 120 || food.ServingGrams != 250 {
+		t.Errorf("expected nutrients per 100 g of the dish, got %+v", food)
+	}
+
+	meal := PlanMealRequest{Meal: "breakfast", Foods: []PlanMealFoodRequest{{FoodID: 1, RecipeID: 7, Grams: 100}}}
+	if err := validatePlanDays([]PlanDayRequest{{DayNumber: 1, Meals: []PlanMealRequest{meal}}}, 7, "diet"); err == nil {
+		t.Error("expected an item with both a food and a recipe to be rejected")
+	}
+
+	meals := buildPlanMeals([]PlanMealRequest{{Meal: "breakfast", Foods: []PlanMealFoodRequest{
+		{RecipeID: 7, Servings: 1, Grams: 250, food: food, recipe: recipe},
+	}}})
+	item := meals[0].Foods[0]
+	if item.FoodID != nil || *item.RecipeID != 7 || item.Calories != 300 {
+		t.Errorf("unexpected recipe item %+v", item)
+	}
+
+	responses, _ := buildPlanMealResponses(meals)
+	days := map[int]PlanDayResponse{1: {DayNumber: 1, Meals: responses}}
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	list := buildGroceryList(days, start, 7, start, start.AddDate(0, 0, 2))
+	if len(list.Categories) != 2 || list.Categories[1].Items[0].FoodName != "Oats" || list.Categories[1].Items[0].Grams != 80 {
+		t.Errorf("expected the recipe broken down into ingredients for two days, got %+v", list.Categories)
+	}
+}
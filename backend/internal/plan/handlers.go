@@ -1,8 +1,11 @@
 package plan
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"fittrackplus/internal/auth"
 	"fittrackplus/internal/common/config"
@@ -72,7 +75,7 @@ func (h *PlanHandler) CreatePlan(c *gin.Context) {
 	}
 
 	// Create plan
-	plan, err := h.planService.CreatePlan(&req, userID)
+	plan, err := h.planService.CreatePlan(&req, userID, userRole)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Failed to create plan",
@@ -162,14 +165,15 @@ func (h *PlanHandler) GetPlan(c *gin.Context) {
 
 // AssignPlan godoc
 // @Summary Assign a plan to a user
-// @Description Assign a plan template to a specific user (Trainer/Admin only)
+// @Description Assign a plan template to a specific user (Trainer/Admin only). Members follow a limited number of plans per plan type; conflicts return 409 listing the plans that would be paused, and force=true pauses them
 // @Tags Plans
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param assignment body map[string]interface{} true "Assignment details"
+// @Param assignment body map[string]interface{} true "Assignment details (user_id, plan_id, force)"
 // @Success 201 {object} UserPlanResponse
 // @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 409 {object} map[string]interface{} "Conflicting plans of the same type"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 403 {object} map[string]interface{} "Forbidden - Trainer/Admin only"
 // @Router /plans/assign [post]
@@ -203,6 +207,7 @@ func (h *PlanHandler) AssignPlan(c *gin.Context) {
 	var req struct {
 		UserID uint `json:"user_id" binding:"required"`
 		PlanID uint `json:"plan_id" binding:"required"`
+		Force  bool `json:"force"` // pause conflicting plans of the same type
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -214,8 +219,11 @@ func (h *PlanHandler) AssignPlan(c *gin.Context) {
 	}
 
 	// Assign plan
-	userPlan, err := h.planService.AssignPlan(req.UserID, req.PlanID, assignedBy)
+	userPlan, err := h.planService.AssignPlan(req.UserID, req.PlanID, assignedBy, req.Force)
 	if err != nil {
+		if respondPlanConflict(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Failed to assign plan",
 			"details": err.Error(),
@@ -261,12 +269,13 @@ func (h *PlanHandler) GetUserPlans(c *gin.Context) {
 
 // GetAssignedPlans godoc
 // @Summary Get plans assigned by trainer/admin
-// @Description Get all plans assigned by the current trainer/admin (Trainer/Admin only)
+// @Description Get the plans assigned by the current trainer; admins see all staff assignments (Trainer/Admin only)
 // @Tags Plans
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} UserPlanResponse
+// @Param status query string false "Filter by status (active, paused, completed)"
+// @Success 200 {object} map[string]interface{} "Assigned plans"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 403 {object} map[string]interface{} "Forbidden - Trainer/Admin only"
 // @Router /plans/assigned [get]
@@ -287,11 +296,28 @@ func (h *PlanHandler) GetAssignedPlans(c *gin.Context) {
 		return
 	}
 
-	// For now, return all assigned plans
-	// In a real app, you'd filter by the current trainer/admin
+	// Get current user ID (the assigning trainer)
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	// Trainers see their own assignments, admins see all staff assignments
+	plans, err := h.planService.GetAssignedPlans(userID, userRole, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get assigned plans",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Assigned plans feature coming soon",
-		"plans": []UserPlanResponse{},
+		"plans": plans,
+		"total": len(plans),
 	})
 }
 
@@ -334,16 +360,17 @@ func (h *PlanHandler) GetAvailablePlans(c *gin.Context) {
 
 // RequestPlanAssignment godoc
 // @Summary Request plan assignment (Member only)
-// @Description Request to be assigned a specific plan (Members can request, but admin/trainer must approve)
+// @Description Request to be assigned a specific plan. The request is routed to the member's trainer, or to admins when the member has none, and the plan is assigned only once approved
 // @Tags Plans
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body map[string]interface{} true "Plan request details"
-// @Success 201 {object} map[string]interface{} "Request submitted"
+// @Param request body map[string]interface{} true "Plan request details (plan_id, reason)"
+// @Success 201 {object} PlanAssignmentRequestResponse
 // @Failure 400 {object} map[string]interface{} "Bad request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 403 {object} map[string]interface{} "Forbidden - Members only"
+// @Failure 409 {object} map[string]interface{} "Request already pending"
 // @Router /plans/request [post]
 func (h *PlanHandler) RequestPlanAssignment(c *gin.Context) {
 	// Check if user is a member
@@ -385,28 +412,1006 @@ func (h *PlanHandler) RequestPlanAssignment(c *gin.Context) {
 		return
 	}
 
-	// Check if plan exists and is active
-	_, err := h.planService.GetPlan(req.PlanID)
+	// Create the request; the plan is assigned when a trainer or admin approves it
+	request, err := h.planService.RequestPlan(userID, req.PlanID, req.Reason)
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "you already have a pending request") {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"error": "Failed to request plan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, request)
+}
+
+// GetPlanRequests godoc
+// @Summary Get plan requests
+// @Description Members see their own requests, trainers the requests routed to them and admins all requests
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (pending, approved, rejected, withdrawn)"
+// @Success 200 {object} map[string]interface{} "Plan requests"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /plans/requests [get]
+func (h *PlanHandler) GetPlanRequests(c *gin.Context) {
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return
+	}
+
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	requests, err := h.planService.GetPlanRequests(userID, userRole, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get plan requests",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requests": requests,
+		"total": len(requests),
+	})
+}
+
+// ApprovePlanRequest godoc
+// @Summary Approve a plan request
+// @Description Approve a pending plan request and assign the plan to the member (routed trainer or Admin)
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request ID"
+// @Param review body map[string]interface{} false "Comment for the member (comment) and force to pause conflicting plans"
+// @Success 200 {object} PlanAssignmentRequestResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 409 {object} map[string]interface{} "Conflicting plans of the same type"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Request not found"
+// @Router /plans/requests/{id}/approve [post]
+func (h *PlanHandler) ApprovePlanRequest(c *gin.Context) {
+	h.reviewPlanRequest(c, true)
+}
+
+// RejectPlanRequest godoc
+// @Summary Reject a plan request
+// @Description Reject a pending plan request with a comment for the member (routed trainer or Admin)
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request ID"
+// @Param review body map[string]interface{} true "Comment for the member (comment)"
+// @Success 200 {object} PlanAssignmentRequestResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Request not found"
+// @Router /plans/requests/{id}/reject [post]
+func (h *PlanHandler) RejectPlanRequest(c *gin.Context) {
+	h.reviewPlanRequest(c, false)
+}
+
+func (h *PlanHandler) reviewPlanRequest(c *gin.Context, approve bool) {
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return
+	}
+
+	if userRole != "admin" && userRole != "trainer" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins and trainers can review plan requests",
+		})
+		return
+	}
+
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request ID",
+		})
+		return
+	}
+
+	var req struct {
+		Comment string `json:"comment"`
+		Force   bool   `json:"force"` // pause the member's conflicting plans of the same type
+	}
+	c.ShouldBindJSON(&req)
+
+	if !approve && strings.TrimSpace(req.Comment) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "A comment explaining the rejection is required",
+		})
+		return
+	}
+
+	request, err := h.planService.ReviewPlanRequest(uint(requestID), approve, req.Force, req.Comment, userID, userRole)
+	if err != nil {
+		if respondPlanConflict(c, err) {
+			return
+		}
+		c.JSON(planErrorStatus(err), gin.H{
+			"error": "Failed to review plan request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// WithdrawPlanRequest godoc
+// @Summary Withdraw a plan request
+// @Description Cancel one of the member's pending plan requests
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Request ID"
+// @Success 200 {object} PlanAssignmentRequestResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Request not found"
+// @Router /plans/requests/{id}/withdraw [post]
+func (h *PlanHandler) WithdrawPlanRequest(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Plan not found or not available",
+			"error": "Invalid request ID",
 		})
 		return
 	}
 
-	// For now, directly assign the plan (in a real app, you'd create a request system)
-	userPlan, err := h.planService.AssignPlan(userID, req.PlanID, userID)
+	request, err := h.planService.WithdrawPlanRequest(uint(requestID), userID)
 	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error": "Failed to withdraw plan request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// UpdatePlan godoc
+// @Summary Replace a plan
+// @Description Replace a plan's details and working draft content. Trainers can edit their own and shared library plans. Members keep their pinned version until a new one is published
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Param plan body PlanRequest true "Plan details"
+// @Success 200 {object} PlanResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin/Trainer only"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Failure 409 {object} map[string]interface{} "Plan has active assignments"
+// @Router /plans/{id} [put]
+func (h *PlanHandler) UpdatePlan(c *gin.Context) {
+	planID, userID, userRole, ok := h.planManagementID(c, "update")
+	if !ok {
+		return
+	}
+
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Failed to assign plan",
+			"error":   "Invalid request data",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Plan assigned successfully!",
-		"plan": userPlan,
-		"note": "In a real app, this would go through an approval process",
-	})
+	plan, err := h.planService.UpdatePlan(planID, &req, userID, userRole)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to update plan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// PatchPlan godoc
+// @Summary Update part of a plan
+// @Description Update only the provided fields of the plan's working draft. Trainers can edit their own and shared library plans
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Param plan body PlanPatchRequest true "Fields to change"
+// @Success 200 {object} PlanResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin/Trainer only"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Failure 409 {object} map[string]interface{} "Plan has active assignments"
+// @Router /plans/{id} [patch]
+func (h *PlanHandler) PatchPlan(c *gin.Context) {
+	planID, userID, userRole, ok := h.planManagementID(c, "update")
+	if !ok {
+		return
+	}
+
+	var req PlanPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	plan, err := h.planService.PatchPlan(planID, &req, userID, userRole)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to update plan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// ArchivePlan godoc
+// @Summary Archive a plan
+// @Description Retire a plan so it can no longer be assigned; members already following it keep it (Admin/Trainer only)
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Success 200 {object} PlanResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin/Trainer only"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Router /plans/{id}/archive [post]
+func (h *PlanHandler) ArchivePlan(c *gin.Context) {
+	h.setPlanArchived(c, true)
+}
+
+// UnarchivePlan godoc
+// @Summary Restore an archived plan
+// @Description Make an archived plan available for assignment again (Admin/Trainer only)
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Success 200 {object} PlanResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin/Trainer only"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Router /plans/{id}/unarchive [post]
+func (h *PlanHandler) UnarchivePlan(c *gin.Context) {
+	h.setPlanArchived(c, false)
+}
+
+func (h *PlanHandler) setPlanArchived(c *gin.Context, archived bool) {
+	planID, userID, userRole, ok := h.planManagementID(c, "archive")
+	if !ok {
+		return
+	}
+
+	plan, err := h.planService.SetPlanArchived(planID, archived, userID, userRole)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to update plan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// ClonePlan godoc
+// @Summary Clone a plan
+// @Description Create a new plan owned by the caller from an existing one, copying its days, exercises and diet (Admin/Trainer only)
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Param clone body map[string]interface{} false "Name for the copy (name)"
+// @Success 201 {object} PlanResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin/Trainer only"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Router /plans/{id}/clone [post]
+func (h *PlanHandler) ClonePlan(c *gin.Context) {
+	planID, userID, _, ok := h.planManagementID(c, "clone")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	c.ShouldBindJSON(&req)
+
+	plan, err := h.planService.ClonePlan(planID, req.Name, userID)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to clone plan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// DeletePlan godoc
+// @Summary Delete a plan
+// @Description Soft delete a plan that no member is following (Admin/Trainer only)
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Success 200 {object} map[string]interface{} "Plan deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin/Trainer only"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Failure 409 {object} map[string]interface{} "Plan has active assignments"
+// @Router /plans/{id} [delete]
+func (h *PlanHandler) DeletePlan(c *gin.Context) {
+	planID, userID, userRole, ok := h.planManagementID(c, "delete")
+	if !ok {
+		return
+	}
+
+	if err := h.planService.DeletePlan(planID, userID, userRole); err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to delete plan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Plan deleted successfully",
+	})
+}
+
+// planManagementID checks the caller may manage plans and parses the plan ID
+// Whether the caller may manage this particular plan is decided by the service
+func (h *PlanHandler) planManagementID(c *gin.Context, action string) (uint, uint, string, bool) {
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return 0, 0, "", false
+	}
+
+	if userRole != "admin" && userRole != "trainer" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins and trainers can " + action + " plans",
+		})
+		return 0, 0, "", false
+	}
+
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return 0, 0, "", false
+	}
+
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid plan ID",
+		})
+		return 0, 0, "", false
+	}
+
+	return uint(planID), userID, userRole, true
+}
+
+// respondPlanConflict writes a 409 describing the plans an assignment would pause
+func respondPlanConflict(c *gin.Context, err error) bool {
+	var conflict *PlanConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+
+	c.JSON(http.StatusConflict, gin.H{
+		"error":    "Plan assignment conflicts with an active plan",
+		"details":  conflict.Error(),
+		"conflict": conflict,
+	})
+	return true
+}
+
+// planErrorStatus maps plan service errors to HTTP status codes
+func planErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "plan has active assignments"), strings.HasPrefix(err.Error(), "request is already"):
+		return http.StatusConflict
+	case strings.HasPrefix(err.Error(), "you can only"), strings.HasPrefix(err.Error(), "only the plan author"):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// PublishPlanVersion godoc
+// @Summary Publish a new plan version
+// @Description Snapshot the plan's current content as a new immutable version. Members on older versions are notified and can choose to migrate (Admin/Trainer only)
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Param version body map[string]interface{} false "What changed (changelog)"
+// @Success 201 {object} PlanVersionResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin/Trainer only"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Router /plans/{id}/versions [post]
+func (h *PlanHandler) PublishPlanVersion(c *gin.Context) {
+	planID, userID, userRole, ok := h.planManagementID(c, "publish")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Changelog string `json:"changelog"`
+	}
+	c.ShouldBindJSON(&req)
+
+	version, err := h.planService.PublishVersion(planID, req.Changelog, userID, userRole)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to publish plan version",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, version)
+}
+
+// GetPlanVersions godoc
+// @Summary List plan versions
+// @Description Get the published versions of a plan, newest first
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Success 200 {array} PlanVersionResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Router /plans/{id}/versions [get]
+func (h *PlanHandler) GetPlanVersions(c *gin.Context) {
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid plan ID",
+		})
+		return
+	}
+
+	versions, err := h.planService.GetPlanVersions(uint(planID))
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to get plan versions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetPlanVersion godoc
+// @Summary Get a plan version
+// @Description Get a published plan version with its full content
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} PlanVersionResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Version not found"
+// @Router /plans/{id}/versions/{version} [get]
+func (h *PlanHandler) GetPlanVersion(c *gin.Context) {
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid plan ID",
+		})
+		return
+	}
+
+	versionNumber, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid version number",
+		})
+		return
+	}
+
+	version, err := h.planService.GetPlanVersion(uint(planID), versionNumber)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to get plan version",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// DiffPlanVersions godoc
+// @Summary Compare plan versions
+// @Description List the field level changes between two published versions of a plan
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Param from query int true "Older version number"
+// @Param to query int true "Newer version number"
+// @Success 200 {object} PlanDiffResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Version not found"
+// @Router /plans/{id}/diff [get]
+func (h *PlanHandler) DiffPlanVersions(c *gin.Context) {
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid plan ID",
+		})
+		return
+	}
+
+	from, fromErr := strconv.Atoi(c.Query("from"))
+	to, toErr := strconv.Atoi(c.Query("to"))
+	if fromErr != nil || toErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from and to version numbers are required",
+		})
+		return
+	}
+
+	diff, err := h.planService.DiffPlanVersions(uint(planID), from, to)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to compare plan versions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// GetUserPlan godoc
+// @Summary Get one of my plans
+// @Description Get an assigned plan with the content of the version the member is following
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User plan ID"
+// @Success 200 {object} UserPlanResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "User plan not found"
+// @Router /plans/my-plans/{id} [get]
+func (h *PlanHandler) GetUserPlan(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	userPlanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user plan ID",
+		})
+		return
+	}
+
+	userPlan, err := h.planService.GetUserPlan(uint(userPlanID), userID)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to get user plan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userPlan)
+}
+
+// MigrateUserPlan godoc
+// @Summary Switch to the latest plan version
+// @Description Move one of the member's plans onto the latest published version
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User plan ID"
+// @Success 200 {object} UserPlanResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "User plan not found"
+// @Router /plans/my-plans/{id}/migrate [post]
+func (h *PlanHandler) MigrateUserPlan(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	userPlanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user plan ID",
+		})
+		return
+	}
+
+	userPlan, err := h.planService.MigrateUserPlan(uint(userPlanID), userID)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to migrate plan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userPlan)
+}
+
+// LogWorkoutCompletion godoc
+// @Summary Log a completed workout
+// @Description Mark a scheduled workout of one of the member's plans as done. Plan days count from 1 over the whole plan; logging the same workout twice has no effect
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User plan ID"
+// @Param completion body map[string]interface{} true "Completed workout (plan_day, workout_position, completed_at)"
+// @Success 200 {object} UserPlanResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "User plan not found"
+// @Router /plans/my-plans/{id}/completions [post]
+func (h *PlanHandler) LogWorkoutCompletion(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	userPlanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user plan ID",
+		})
+		return
+	}
+
+	var req struct {
+		PlanDay         int       `json:"plan_day" binding:"required,min=1"`
+		WorkoutPosition int       `json:"workout_position" binding:"required,min=1"`
+		CompletedAt     time.Time `json:"completed_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userPlan, err := h.planService.LogWorkoutCompletion(uint(userPlanID), userID, req.PlanDay, req.WorkoutPosition, req.CompletedAt)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to log workout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userPlan)
+}
+
+// GetCalendar godoc
+// @Summary Get the workout calendar
+// @Description Get the member's workouts for a day and the Monday-to-Sunday week around it, across all active plans. Training days follow the workout days in the member's profile
+// @Tags Plans
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Day to show (YYYY-MM-DD, defaults to today)"
+// @Success 200 {object} CalendarResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /plans/calendar [get]
+func (h *PlanHandler) GetCalendar(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	date := time.Now()
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		date = parsed
+	}
+
+	calendar, err := h.planService.GetCalendar(userID, date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get calendar",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// RescheduleWorkout godoc
+// @Summary Reschedule a training day
+// @Description Move a training day that has not been done yet to today or a later date. With shift_remaining, the later training days move along with it
+// @Tags Plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User plan ID"
+// @Param reschedule body map[string]interface{} true "Plan day and new date (plan_day, date YYYY-MM-DD, shift_remaining)"
+// @Success 200 {object} CalendarResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "User plan not found"
+// @Router /plans/my-plans/{id}/reschedule [post]
+func (h *PlanHandler) RescheduleWorkout(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	userPlanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user plan ID",
+		})
+		return
+	}
+
+	var req struct {
+		PlanDay        int    `json:"plan_day" binding:"required,min=1"`
+		Date           string `json:"date" binding:"required"`
+		ShiftRemaining bool   `json:"shift_remaining"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid date, expected YYYY-MM-DD",
+		})
+		return
+	}
+
+	calendar, err := h.planService.RescheduleWorkout(userID, uint(userPlanID), req.PlanDay, date, req.ShiftRemaining)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to reschedule workout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// CheckPlanAllergies godoc
+// @Summary Check a diet plan against a member's allergies
+// @Description List the foods and substitutions of a diet plan that conflict with the allergies in a member's profile, before assigning it (Admin/Trainer only). Trainers need to manage the plan and can only check their clients or members who requested the plan from them
+// @Tags Plans
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Plan ID"
+// @Param user_id query int true "Member to check"
+// @Success 200 {object} AllergyCheckResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your plan or not your client"
+// @Failure 404 {object} map[string]interface{} "Plan not found"
+// @Router /plans/{id}/allergy-check [get]
+func (h *PlanHandler) CheckPlanAllergies(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	userRole, exists := auth.GetCurrentUserRole(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User role not found",
+		})
+		return
+	}
+
+	if userRole != "admin" && userRole != "trainer" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only admins and trainers can check plans against member allergies",
+		})
+		return
+	}
+
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid plan ID",
+		})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Query("user_id"), 10, 32)
+	if err != nil || memberID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "user_id is required",
+		})
+		return
+	}
+
+	check, err := h.planService.CheckAllergies(uint(planID), uint(memberID), userID, userRole)
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to check allergies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+// GetGroceryList godoc
+// @Summary Get a grocery list for a diet plan
+// @Description Total the foods of one of the member's diet plans over a date range, grouped by food category. Recipes are broken down into their ingredients. The range defaults to the next 7 days
+// @Tags Plans
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User plan ID"
+// @Param from query string false "First day (YYYY-MM-DD, defaults to today)"
+// @Param to query string false "Day after the last one (YYYY-MM-DD, defaults to 7 days after from)"
+// @Success 200 {object} GroceryList
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "User plan not found"
+// @Router /plans/my-plans/{id}/grocery-list [get]
+func (h *PlanHandler) GetGroceryList(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	userPlanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user plan ID",
+		})
+		return
+	}
+
+	var dates [2]*time.Time
+	for i, name := range []string{"from", "to"} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid " + name + " date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		dates[i] = &parsed
+	}
+
+	list, err := h.planService.GetGroceryList(uint(userPlanID), userID, dates[0], dates[1])
+	if err != nil {
+		c.JSON(planErrorStatus(err), gin.H{
+			"error":   "Failed to get grocery list",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
 }
@@ -2,26 +2,31 @@ package plan
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"fittrackplus/internal/common/config"
 	"fittrackplus/internal/common/database"
 	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/notification"
 
 	"gorm.io/gorm"
 )
 
 // PlanService handles plan management business logic
 type PlanService struct {
-	db  *gorm.DB
-	cfg *config.Config
+	db                  *gorm.DB
+	cfg                 *config.Config
+	notificationService *notification.NotificationService
 }
 
 // NewPlanService creates a new plan service
 func NewPlanService(cfg *config.Config) *PlanService {
 	return &PlanService{
-		db:  database.GetDB(),
-		cfg: cfg,
+		db:                  database.GetDB(),
+		cfg:                 cfg,
+		notificationService: notification.NewNotificationService(cfg),
 	}
 }
 
@@ -32,6 +37,8 @@ type PlanRequest struct {
 	GoalType    string `json:"goal_type" binding:"required"` // lose_weight, gain_muscle, flexibility, rehab
 	PlanType    string `json:"plan_type" binding:"required"` // fitness, diet, physio
 	Duration    int    `json:"duration" binding:"min=1"`     // in days, minimum 1 day
+	Days        []PlanDayRequest `json:"days"`                // structured program content
+	IsShared    *bool  `json:"is_shared"`                    // shared library plan; defaults to true for admins
 }
 
 // PlanResponse represents a plan response
@@ -43,8 +50,23 @@ type PlanResponse struct {
 	PlanType    string    `json:"plan_type"`
 	Duration    int       `json:"duration"`
 	IsActive    bool      `json:"is_active"`
+	LatestVersion int     `json:"latest_version"`
+	CreatedBy   *uint     `json:"created_by,omitempty"`
+	IsShared    bool      `json:"is_shared"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Days        []PlanDayResponse `json:"days,omitempty"` // only included when fetching a single plan
+}
+
+// PlanPatchRequest represents a partial plan update; only provided fields change
+type PlanPatchRequest struct {
+	Name        *string           `json:"name"`
+	Description *string           `json:"description"`
+	GoalType    *string           `json:"goal_type"`
+	PlanType    *string           `json:"plan_type"`
+	Duration    *int              `json:"duration"`
+	Days        *[]PlanDayRequest `json:"days"`
+	IsShared    *bool             `json:"is_shared"`
 }
 
 // UserPlanResponse represents a user's assigned plan
@@ -52,15 +74,23 @@ type UserPlanResponse struct {
 	ID           uint      `json:"id"`
 	UserID       uint      `json:"user_id"`
 	PlanID       uint      `json:"plan_id"`
+	AssignedBy   *uint     `json:"assigned_by,omitempty"`
+	MemberName   string    `json:"member_name,omitempty"` // included in trainer views
 	Status       string    `json:"status"` // active, completed, paused
+	PlanVersion  int       `json:"plan_version"`     // version the member is following
+	LatestVersion int      `json:"latest_version"`   // newest published version
+	UpdateAvailable bool   `json:"update_available"` // member can migrate to a newer version
 	AssignedAt   time.Time `json:"assigned_at"`
 	CompletedAt  *time.Time `json:"completed_at,omitempty"`
 	Progress     float64   `json:"progress"` // 0-100
+	ProgressDetails PlanProgress `json:"progress_details"`
+	EndsAt       time.Time `json:"ends_at"` // assignment date plus the plan duration
 	Plan         PlanResponse `json:"plan"`
+	AllergyConflicts []AllergyConflict `json:"allergy_conflicts,omitempty"` // diet plans only
 }
 
 // CreatePlan creates a new plan template
-func (s *PlanService) CreatePlan(req *PlanRequest, createdBy uint) (*PlanResponse, error) {
+func (s *PlanService) CreatePlan(req *PlanRequest, createdBy uint, creatorRole string) (*PlanResponse, error) {
 	// Validate goal type
 	if !isValidGoalType(req.GoalType) {
 		return nil, errors.New("invalid goal type: must be one of lose_weight, gain_muscle, flexibility, rehab")
@@ -76,7 +106,18 @@ func (s *PlanService) CreatePlan(req *PlanRequest, createdBy uint) (*PlanRespons
 		return nil, errors.New("duration must be at least 1 day")
 	}
 
-	// Create plan
+	// Validate structured content
+	if err := validatePlanDays(req.Days, req.Duration, req.PlanType); err != nil {
+		return nil, err
+	}
+	if err := resolveCatalogExercises(s.db, req.Days); err != nil {
+		return nil, err
+	}
+	if err := resolveDietFoods(s.db, req.Days); err != nil {
+		return nil, err
+	}
+
+	// Create plan (days, workouts and exercises are created with it)
 	plan := models.Plan{
 		Name:        req.Name,
 		Description: req.Description,
@@ -84,13 +125,27 @@ func (s *PlanService) CreatePlan(req *PlanRequest, createdBy uint) (*PlanRespons
 		PlanType:    req.PlanType,
 		Duration:    req.Duration,
 		IsActive:    true,
+		CreatedBy:   &createdBy,
+		IsShared:    creatorRole == "admin",
+		Days:        buildPlanDays(req.Days),
+	}
+	if req.IsShared != nil {
+		plan.IsShared = *req.IsShared
 	}
 
-	if err := s.db.Create(&plan).Error; err != nil {
+	// The first version is published together with the plan
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&plan).Error; err != nil {
+			return err
+		}
+		_, err := publishPlanVersion(tx, &plan, "Initial version", &createdBy)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return s.buildPlanResponse(&plan), nil
+	return s.GetPlan(plan.ID)
 }
 
 // GetPlans retrieves all plans with optional filtering
@@ -125,65 +180,438 @@ func (s *PlanService) GetPlans(goalType, planType string, isActive *bool) ([]Pla
 // GetPlan retrieves a specific plan by ID
 func (s *PlanService) GetPlan(planID uint) (*PlanResponse, error) {
 	var plan models.Plan
-	if err := s.db.First(&plan, planID).Error; err != nil {
+	if err := preloadPlanContent(s.db).First(&plan, planID).Error; err != nil {
 		return nil, err
 	}
 
-	return s.buildPlanResponse(&plan), nil
+	response := s.buildPlanResponse(&plan)
+	response.Days = buildPlanDayResponses(plan.Days)
+	return response, nil
+}
+
+// UpdatePlan replaces a plan's details and content (working draft)
+func (s *PlanService) UpdatePlan(planID uint, req *PlanRequest, userID uint, userRole string) (*PlanResponse, error) {
+	days := req.Days
+	if days == nil {
+		days = []PlanDayRequest{}
+	}
+
+	return s.PatchPlan(planID, &PlanPatchRequest{
+		Name:        &req.Name,
+		Description: &req.Description,
+		GoalType:    &req.GoalType,
+		PlanType:    &req.PlanType,
+		Duration:    &req.Duration,
+		Days:        &days,
+		IsShared:    req.IsShared,
+	}, userID, userRole)
+}
+
+// PatchPlan updates the provided fields of a plan
+// Edits change the working draft only; members keep their pinned version until a new one is published
+func (s *PlanService) PatchPlan(planID uint, req *PlanPatchRequest, userID uint, userRole string) (*PlanResponse, error) {
+	plan, err := s.findManageablePlan(planID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		plan.Name = *req.Name
+	}
+	if req.Description != nil {
+		plan.Description = *req.Description
+	}
+	if req.GoalType != nil {
+		if !isValidGoalType(*req.GoalType) {
+			return nil, errors.New("invalid goal type: must be one of lose_weight, gain_muscle, flexibility, rehab")
+		}
+		plan.GoalType = *req.GoalType
+	}
+	if req.PlanType != nil {
+		if !isValidPlanType(*req.PlanType) {
+			return nil, errors.New("invalid plan type: must be one of fitness, diet, physio")
+		}
+		// Workouts and meals do not carry over between diet and other plan types
+		if (plan.PlanType == "diet") != (*req.PlanType == "diet") && req.Days == nil {
+			var days int64
+			s.db.Model(&models.PlanDay{}).Where("plan_id = ?", plan.ID).Count(&days)
+			if days > 0 {
+				return nil, errors.New("switching to or from a diet plan replaces the content: update days as well")
+			}
+		}
+		plan.PlanType = *req.PlanType
+	}
+	if req.Duration != nil {
+		if *req.Duration < 1 {
+			return nil, errors.New("duration must be at least 1 day")
+		}
+		plan.Duration = *req.Duration
+	}
+	if req.IsShared != nil {
+		// Only the author or an admin decides whether other trainers may edit the plan
+		if userRole != "admin" && (plan.CreatedBy == nil || *plan.CreatedBy != userID) {
+			return nil, errors.New("only the plan author can change sharing")
+		}
+		plan.IsShared = *req.IsShared
+	}
+
+	// Existing content must still fit a shorter duration
+	if req.Days != nil {
+		if err := validatePlanDays(*req.Days, plan.Duration, plan.PlanType); err != nil {
+			return nil, err
+		}
+		if err := resolveCatalogExercises(s.db, *req.Days); err != nil {
+			return nil, err
+		}
+		if err := resolveDietFoods(s.db, *req.Days); err != nil {
+			return nil, err
+		}
+	} else if req.Duration != nil {
+		var outside int64
+		s.db.Model(&models.PlanDay{}).Where("plan_id = ? AND day_number > ?", plan.ID, plan.Duration).Count(&outside)
+		if outside > 0 {
+			return nil, errors.New("plan has days beyond the new duration: update days as well")
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(plan).Error; err != nil {
+			return err
+		}
+		if req.Days != nil {
+			return replacePlanContent(tx, plan.ID, buildPlanDays(*req.Days))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPlan(plan.ID)
+}
+
+// SetPlanArchived archives or restores a plan
+// Archived plans cannot be assigned, but members already following them keep them
+func (s *PlanService) SetPlanArchived(planID uint, archived bool, userID uint, userRole string) (*PlanResponse, error) {
+	plan, err := s.findManageablePlan(planID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(plan).Update("is_active", !archived).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetPlan(plan.ID)
+}
+
+// ClonePlan makes a deep copy of a plan, including its days, exercises and diet
+// The copy belongs to the caller, so trainers can start from any template
+func (s *PlanService) ClonePlan(planID uint, name string, userID uint) (*PlanResponse, error) {
+	var source models.Plan
+	if err := preloadPlanContent(s.db).First(&source, planID).Error; err != nil {
+		return nil, errors.New("plan not found")
+	}
+
+	if strings.TrimSpace(name) == "" {
+		name = "Copy of " + source.Name
+	}
+
+	clone := models.Plan{
+		Name:            name,
+		Description:     source.Description,
+		GoalType:        source.GoalType,
+		PlanType:        source.PlanType,
+		Exercises:       source.Exercises,
+		Diet:            source.Diet,
+		PhysioExercises: source.PhysioExercises,
+		Duration:        source.Duration,
+		IsActive:        true,
+		CreatedBy:       &userID,
+		Days:            clonePlanDays(source.Days),
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&clone).Error; err != nil {
+			return err
+		}
+		_, err := publishPlanVersion(tx, &clone, fmt.Sprintf("Cloned from plan %d", source.ID), &userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPlan(clone.ID)
+}
+
+// DeletePlan soft deletes a plan that nobody is following
+func (s *PlanService) DeletePlan(planID uint, userID uint, userRole string) error {
+	plan, err := s.findManageablePlan(planID, userID, userRole)
+	if err != nil {
+		return err
+	}
+
+	active, err := s.countActiveAssignments(planID)
+	if err != nil {
+		return err
+	}
+	if active > 0 {
+		return errors.New("plan has active assignments: archive it instead")
+	}
+
+	return s.db.Delete(plan).Error
+}
+
+// PlanConflictError explains which of the member's plans an assignment would pause
+type PlanConflictError struct {
+	PlanType   string             `json:"plan_type"`
+	Limit      int                `json:"limit"`       // active plans allowed for this type
+	WouldPause []UserPlanResponse `json:"would_pause"` // plans paused when the assignment is forced
+}
+
+func (e *PlanConflictError) Error() string {
+	names := []string{}
+	for _, userPlan := range e.WouldPause {
+		names = append(names, fmt.Sprintf("%q", userPlan.Plan.Name))
+	}
+	return fmt.Sprintf("member already has %d active %s plan(s); assigning would pause %s (use force to continue)",
+		e.Limit, e.PlanType, strings.Join(names, ", "))
 }
 
 // AssignPlan assigns a plan to a user
-func (s *PlanService) AssignPlan(userID, planID uint, assignedBy uint) (*UserPlanResponse, error) {
+// assignedBy is recorded as the member's trainer unless members assign themselves.
+// Members may follow a limited number of plans per plan type; when the limit is reached
+// a *PlanConflictError is returned unless force is set, which pauses the oldest plans of that type
+func (s *PlanService) AssignPlan(userID, planID uint, assignedBy uint, force bool) (*UserPlanResponse, error) {
+	var assignment *planAssignment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		assignment, err = s.assignPlan(tx, userID, planID, assignedBy, force)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.announceAssignment(assignment), nil
+}
+
+// planAssignment is a plan assignment written by assignPlan, announced once it is committed
+type planAssignment struct {
+	userPlan models.UserPlan
+	paused   []models.UserPlan
+}
+
+// assignPlan pauses the conflicting plans and creates the assignment within tx,
+// so callers can commit it together with their own changes
+func (s *PlanService) assignPlan(tx *gorm.DB, userID, planID uint, assignedBy uint, force bool) (*planAssignment, error) {
 	// Check if plan exists and is active
 	var plan models.Plan
-	if err := s.db.First(&plan, planID).Error; err != nil {
+	if err := tx.First(&plan, planID).Error; err != nil {
 		return nil, errors.New("plan not found")
 	}
 	if !plan.IsActive {
 		return nil, errors.New("plan is not active")
 	}
 
-	// Check if user already has an active plan
-	var existingUserPlan models.UserPlan
-	err := s.db.Where("user_id = ? AND status = ?", userID, "active").First(&existingUserPlan).Error
-	if err == nil {
-		// User has an active plan, pause it
-		existingUserPlan.Status = "paused"
-		s.db.Save(&existingUserPlan)
+	// Check the member's active plans of the same type
+	toPause, err := s.conflictingPlans(userID, plan.PlanType)
+	if err != nil {
+		return nil, err
+	}
+	if len(toPause) > 0 && !force {
+		return nil, &PlanConflictError{
+			PlanType:   plan.PlanType,
+			Limit:      s.cfg.MaxActivePlans(plan.PlanType),
+			WouldPause: s.buildUserPlanResponses(toPause),
+		}
+	}
+
+	// Pin the member to the latest published version
+	version, err := s.latestPlanVersion(planID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create new user plan assignment
 	userPlan := models.UserPlan{
-		UserID:     userID,
-		PlanID:     planID,
-		Status:     "active",
-		AssignedAt: time.Now(),
+		UserID:        userID,
+		PlanID:        planID,
+		PlanVersionID: &version.ID,
+		Status:        "active",
+		AssignedAt:    time.Now(),
+		Plan:          plan,
+		PlanVersion:   version,
 	}
 
-	if err := s.db.Create(&userPlan).Error; err != nil {
+	if assignedBy != userID {
+		userPlan.AssignedBy = &assignedBy
+	}
+
+	// Pausing the conflicting plans and assigning the new one succeed or fail together
+	for i := range toPause {
+		if err := tx.Model(&toPause[i]).Update("status", "paused").Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Omit("Plan", "PlanVersion").Create(&userPlan).Error; err != nil {
 		return nil, err
 	}
 
-	return s.buildUserPlanResponse(&userPlan), nil
+	// Lay the training days out on the member's calendar
+	if err := s.scheduleCalendar(tx, &userPlan, userPlan.AssignedAt, 1); err != nil {
+		return nil, err
+	}
+
+	return &planAssignment{userPlan: userPlan, paused: toPause}, nil
+}
+
+// announceAssignment notifies the member of a committed assignment and builds its response
+func (s *PlanService) announceAssignment(assignment *planAssignment) *UserPlanResponse {
+	userPlan := &assignment.userPlan
+	for i := range assignment.paused {
+		s.notificationService.Notify(userPlan.UserID, notification.Message{
+			Type:     "info",
+			Category: "plan",
+			Title:    "Plan paused",
+			Message:  fmt.Sprintf("%q was paused because %q was assigned to you.", assignment.paused[i].Plan.Name, userPlan.Plan.Name),
+			Link:     fmt.Sprintf("/plans/my-plans/%d", assignment.paused[i].ID),
+		})
+	}
+
+	response := s.buildUserPlanResponse(userPlan)
+	s.flagAllergyConflicts(userPlan, response)
+	return response
 }
 
 // GetUserPlans retrieves plans assigned to a specific user
 func (s *PlanService) GetUserPlans(userID uint) ([]UserPlanResponse, error) {
 	var userPlans []models.UserPlan
-	if err := s.db.Where("user_id = ?", userID).Preload("Plan").Find(&userPlans).Error; err != nil {
+	if err := s.db.Where("user_id = ?", userID).Preload("Plan").Preload("PlanVersion").Find(&userPlans).Error; err != nil {
+		return nil, err
+	}
+
+	return s.buildUserPlanResponses(userPlans), nil
+}
+
+// GetAssignedPlans retrieves the plan assignments made by a trainer
+// Admins see every assignment made by staff
+func (s *PlanService) GetAssignedPlans(assignedBy uint, userRole string, status string) ([]UserPlanResponse, error) {
+	var userPlans []models.UserPlan
+	query := s.db.Preload("Plan").Preload("PlanVersion").Preload("User")
+
+	if userRole == "admin" {
+		query = query.Where("assigned_by IS NOT NULL")
+	} else {
+		query = query.Where("assigned_by = ?", assignedBy)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Order("assigned_at DESC").Find(&userPlans).Error; err != nil {
 		return nil, err
 	}
 
-	var responses []UserPlanResponse
-	for _, userPlan := range userPlans {
-		response := s.buildUserPlanResponse(&userPlan)
-		responses = append(responses, *response)
+	responses := s.buildUserPlanResponses(userPlans)
+	for i, userPlan := range userPlans {
+		if userPlan.User.ID != 0 {
+			responses[i].MemberName = userPlan.User.FirstName + " " + userPlan.User.LastName
+		}
 	}
 
 	return responses, nil
 }
 
 // Helper methods
+
+// conflictingPlans returns the member's active plans that must be paused to make room
+// for one more plan of the given type, oldest first
+func (s *PlanService) conflictingPlans(userID uint, planType string) ([]models.UserPlan, error) {
+	limit := s.cfg.MaxActivePlans(planType)
+	if limit == 0 {
+		return nil, nil
+	}
+
+	var active []models.UserPlan
+	err := s.db.Where("user_id = ? AND status = ?", userID, "active").
+		Preload("Plan").Preload("PlanVersion").
+		Order("assigned_at").
+		Find(&active).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return plansToPause(active, planType, limit), nil
+}
+
+// plansToPause picks the oldest active plans of a type that must be paused so one more
+// fits within the limit (active plans oldest first; a limit of 0 means unlimited)
+func plansToPause(active []models.UserPlan, planType string, limit int) []models.UserPlan {
+	if limit == 0 {
+		return nil
+	}
+
+	sameType := []models.UserPlan{}
+	for _, userPlan := range active {
+		if userPlanType(&userPlan) == planType {
+			sameType = append(sameType, userPlan)
+		}
+	}
+
+	if len(sameType) < limit {
+		return nil
+	}
+	return sameType[:len(sameType)-limit+1]
+}
+
+// userPlanType returns the plan type of the version a member follows
+func userPlanType(userPlan *models.UserPlan) string {
+	if userPlan.PlanVersion != nil {
+		return userPlan.PlanVersion.PlanType
+	}
+	return userPlan.Plan.PlanType
+}
+
+// findManageablePlan loads a plan the caller may edit
+// Admins may edit any plan, trainers their own plans and shared library plans
+func (s *PlanService) findManageablePlan(planID, userID uint, userRole string) (*models.Plan, error) {
+	var plan models.Plan
+	if err := s.db.First(&plan, planID).Error; err != nil {
+		return nil, errors.New("plan not found")
+	}
+
+	if !canManagePlan(&plan, userID, userRole) {
+		return nil, errors.New("you can only manage your own plans or shared library plans")
+	}
+
+	return &plan, nil
+}
+
+func canManagePlan(plan *models.Plan, userID uint, userRole string) bool {
+	if userRole == "admin" {
+		return true
+	}
+	if userRole != "trainer" {
+		return false
+	}
+	// Plans without an author predate ownership and belong to the shared library
+	return plan.IsShared || plan.CreatedBy == nil || *plan.CreatedBy == userID
+}
+
+func (s *PlanService) countActiveAssignments(planID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.UserPlan{}).
+		Where("plan_id = ? AND status IN ?", planID, []string{"active", "paused"}).
+		Count(&count).Error
+	return count, err
+}
+
 func (s *PlanService) buildPlanResponse(plan *models.Plan) *PlanResponse {
 	return &PlanResponse{
 		ID:          plan.ID,
@@ -193,39 +621,64 @@ func (s *PlanService) buildPlanResponse(plan *models.Plan) *PlanResponse {
 		PlanType:    plan.PlanType,
 		Duration:    plan.Duration,
 		IsActive:    plan.IsActive,
+		LatestVersion: plan.LatestVersion,
+		CreatedBy:   plan.CreatedBy,
+		IsShared:    plan.IsShared,
 		CreatedAt:   plan.CreatedAt,
 		UpdatedAt:   plan.UpdatedAt,
 	}
 }
 
 func (s *PlanService) buildUserPlanResponse(userPlan *models.UserPlan) *UserPlanResponse {
+	return s.userPlanResponse(userPlan, s.planProgress(userPlan))
+}
+
+// buildUserPlanResponses builds the responses for a list of user plans, computing their progress in one batch
+func (s *PlanService) buildUserPlanResponses(userPlans []models.UserPlan) []UserPlanResponse {
+	progress := s.planProgressBatch(userPlans)
+
+	responses := make([]UserPlanResponse, 0, len(userPlans))
+	for i := range userPlans {
+		responses = append(responses, *s.userPlanResponse(&userPlans[i], progress[userPlans[i].ID]))
+	}
+	return responses
+}
+
+// userPlanResponse builds the response for a user plan from its computed progress
+func (s *PlanService) userPlanResponse(userPlan *models.UserPlan, progress PlanProgress) *UserPlanResponse {
 	response := &UserPlanResponse{
 		ID:          userPlan.ID,
 		UserID:      userPlan.UserID,
 		PlanID:      userPlan.PlanID,
+		AssignedBy:  userPlan.AssignedBy,
 		Status:      userPlan.Status,
 		AssignedAt:  userPlan.AssignedAt,
-		CompletedAt: userPlan.CompletedAt,
-		Progress:    s.calculatePlanProgress(userPlan),
 	}
 
+	response.CompletedAt = userPlan.CompletedAt
+	response.Progress = progress.overallProgress()
+	response.ProgressDetails = progress
+	response.EndsAt = userPlan.AssignedAt.AddDate(0, 0, progress.Duration)
+
 	// Load plan details
 	if userPlan.Plan.ID != 0 {
 		response.Plan = *s.buildPlanResponse(&userPlan.Plan)
+		response.LatestVersion = userPlan.Plan.LatestVersion
 	}
 
-	return response
-}
-
-func (s *PlanService) calculatePlanProgress(userPlan *models.UserPlan) float64 {
-	// For now, return a simple calculation
-	// In a real app, you'd calculate based on completed workouts/sessions
-	if userPlan.Status == "completed" {
-		return 100.0
-	} else if userPlan.Status == "paused" {
-		return 50.0 // Example: paused plans show 50% progress
+	// Members see the details of the version they follow, not the working draft
+	if userPlan.PlanVersion != nil {
+		response.PlanVersion = userPlan.PlanVersion.Version
+		response.Plan.Name = userPlan.PlanVersion.Name
+		response.Plan.Description = userPlan.PlanVersion.Description
+		response.Plan.GoalType = userPlan.PlanVersion.GoalType
+		response.Plan.PlanType = userPlan.PlanVersion.PlanType
+		response.Plan.Duration = userPlan.PlanVersion.Duration
+		response.UpdateAvailable = response.PlanVersion < response.LatestVersion &&
+			(userPlan.Status == "active" || userPlan.Status == "paused")
 	}
-	return 0.0 // Active plans start at 0%
+
+	return response
 }
 
 // Validation helpers
@@ -0,0 +1,128 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/testdb"
+)
+
+func TestPlansToPause(t *testing.T) {
+	userPlan := func(id uint, planType string) models.UserPlan {
+		return models.UserPlan{ID: id, Plan: models.Plan{PlanType: planType}}
+	}
+	// Oldest first, as loaded by conflictingPlans
+	active := []models.UserPlan{userPlan(1, "fitness"), userPlan(2, "diet"), userPlan(3, "fitness"), userPlan(4, "fitness")}
+
+	tests := []struct {
+		name     string
+		planType string
+		limit    int
+		want     []uint
+	}{
+		{"unlimited", "fitness", 0, nil},
+		{"below the limit", "diet", 2, nil},
+		{"at the limit", "diet", 1, []uint{2}},
+		{"oldest plans make room", "fitness", 2, []uint{1, 3}},
+		{"room for everything", "fitness", 4, nil},
+		{"other types ignored", "physio", 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paused := plansToPause(active, tt.planType, tt.limit)
+			if len(paused) != len(tt.want) {
+				t.Fatalf("expected plans %v to be paused, got %+v", tt.want, paused)
+			}
+			for i := range tt.want {
+				if paused[i].ID != tt.want[i] {
+					t.Errorf("expected plans %v to be paused, got %+v", tt.want, paused)
+				}
+			}
+		})
+	}
+}
+
+func TestPlansToPauseUsesFollowedVersion(t *testing.T) {
+	// The plan was switched to diet after the member was assigned its fitness version
+	active := []models.UserPlan{{ID: 1, Plan: models.Plan{PlanType: "diet"}, PlanVersion: &models.PlanVersion{PlanType: "fitness"}}}
+	if paused := plansToPause(active, "fitness", 1); len(paused) != 1 {
+		t.Errorf("expected the fitness version to conflict, got %+v", paused)
+	}
+	if paused := plansToPause(active, "diet", 1); len(paused) != 0 {
+		t.Errorf("expected no diet conflict, got %+v", paused)
+	}
+}
+
+func TestPlanConflictErrorMessage(t *testing.T) {
+	conflict := &PlanConflictError{PlanType: "fitness", Limit: 1, WouldPause: []UserPlanResponse{{Plan: PlanResponse{Name: "Strength Base"}}}}
+	want := `member already has 1 active fitness plan(s); assigning would pause "Strength Base" (use force to continue)`
+	if conflict.Error() != want {
+		t.Errorf("expected %q, got %q", want, conflict.Error())
+	}
+}
+
+func TestEditCloneAndArchivePlan(t *testing.T) {
+	cfg := testdb.Connect(t)
+	service := NewPlanService(cfg)
+	author := testdb.CreateUser(t, "trainer")
+	otherTrainer := testdb.CreateUser(t, "trainer")
+	member := testdb.CreateUser(t, "member")
+
+	source := createTestPlan(t, service, author.ID, "Source plan")
+
+	// Editing replaces the content; other trainers cannot edit a private plan
+	newName := "Edited plan"
+	if _, err := service.PatchPlan(source.ID, &PlanPatchRequest{Name: &newName}, otherTrainer.ID, "trainer"); err == nil || !strings.HasPrefix(err.Error(), "you can only") {
+		t.Errorf("expected another trainer's edit to be refused, got %v", err)
+	}
+	days := []PlanDayRequest{{DayNumber: 2, Workouts: []PlanWorkoutRequest{{
+		Name:      "Legs",
+		Exercises: []ExercisePrescriptionRequest{{ExerciseName: "Squat", Sets: 5, RepsMin: 5, RepsMax: 5, LoadType: "kg", LoadValue: 100}},
+	}}}}
+	edited, err := service.PatchPlan(source.ID, &PlanPatchRequest{Name: &newName, Days: &days}, author.ID, "trainer")
+	if err != nil {
+		t.Fatalf("failed to edit plan: %v", err)
+	}
+	if edited.Name != newName || len(edited.Days) != 1 || edited.Days[0].DayNumber != 2 || edited.Days[0].Workouts[0].Exercises[0].ExerciseName != "Squat" {
+		t.Fatalf("expected the edited content, got %+v", edited)
+	}
+
+	// Cloning copies the content into new rows owned by the caller
+	clone, err := service.ClonePlan(source.ID, "", otherTrainer.ID)
+	if err != nil {
+		t.Fatalf("failed to clone plan: %v", err)
+	}
+	if clone.ID == source.ID || clone.Name != "Copy of "+newName || clone.CreatedBy == nil || *clone.CreatedBy != otherTrainer.ID {
+		t.Errorf("expected a new plan owned by the caller, got %+v", clone)
+	}
+	if len(clone.Days) != 1 || clone.Days[0].ID == edited.Days[0].ID ||
+		clone.Days[0].Workouts[0].Exercises[0].ID == edited.Days[0].Workouts[0].Exercises[0].ID ||
+		clone.Days[0].Workouts[0].Exercises[0].ExerciseName != "Squat" {
+		t.Errorf("expected the content copied into new rows, got %+v", clone.Days)
+	}
+
+	// Archived plans cannot be assigned; restoring them makes them assignable again
+	archived, err := service.SetPlanArchived(source.ID, true, author.ID, "trainer")
+	if err != nil || archived.IsActive {
+		t.Fatalf("expected the plan to be archived, got %+v, %v", archived, err)
+	}
+	if _, err := service.AssignPlan(member.ID, source.ID, author.ID, false); err == nil || err.Error() != "plan is not active" {
+		t.Errorf("expected an archived plan not to be assignable, got %v", err)
+	}
+	if _, err := service.SetPlanArchived(source.ID, false, author.ID, "trainer"); err != nil {
+		t.Fatalf("failed to restore plan: %v", err)
+	}
+	if _, err := service.AssignPlan(member.ID, source.ID, author.ID, false); err != nil {
+		t.Fatalf("failed to assign restored plan: %v", err)
+	}
+
+	// A plan members are following must be archived instead of deleted
+	if err := service.DeletePlan(source.ID, author.ID, "trainer"); err == nil || !strings.HasPrefix(err.Error(), "plan has active assignments") {
+		t.Errorf("expected deleting an assigned plan to be refused, got %v", err)
+	}
+	if err := service.DeletePlan(clone.ID, otherTrainer.ID, "trainer"); err != nil {
+		t.Errorf("failed to delete unassigned clone: %v", err)
+	}
+}
@@ -0,0 +1,439 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+	"fittrackplus/internal/notification"
+)
+
+// ScheduledWorkout is one workout a plan asks the member to do
+type ScheduledWorkout struct {
+	PlanDay         int       `json:"plan_day"`  // 1..duration
+	CycleDay        int       `json:"cycle_day"` // day of the plan content it comes from
+	WorkoutPosition int       `json:"workout_position"`
+	Name            string    `json:"name"`
+	Date            time.Time `json:"date"` // calendar date the workout falls on
+}
+
+// PlanProgress breaks down how far a member is through a plan
+type PlanProgress struct {
+	ScheduledWorkouts int     `json:"scheduled_workouts"` // workouts over the whole plan
+	DueWorkouts       int     `json:"due_workouts"`       // workouts scheduled up to today
+	CompletedWorkouts int     `json:"completed_workouts"`
+	ElapsedDays       int     `json:"elapsed_days"`
+	Duration          int     `json:"duration"`         // calendar days the plan spans (at least the plan duration)
+	WorkoutProgress   float64 `json:"workout_progress"` // completed vs scheduled, 0-100
+	TimeProgress      float64 `json:"time_progress"`    // elapsed days vs duration, 0-100
+	Adherence         float64 `json:"adherence"`        // completed vs due, 0-100
+}
+
+// LogWorkoutCompletion marks a scheduled workout of a member's plan as done
+// Logging the same workout twice is a no-op
+func (s *PlanService) LogWorkoutCompletion(userPlanID, userID uint, planDay, workoutPosition int, completedAt time.Time) (*UserPlanResponse, error) {
+	var userPlan models.UserPlan
+	err := s.db.Where("id = ? AND user_id = ?", userPlanID, userID).
+		Preload("Plan").Preload("PlanVersion").
+		First(&userPlan).Error
+	if err != nil {
+		return nil, errors.New("user plan not found")
+	}
+	if userPlan.Status != "active" {
+		return nil, fmt.Errorf("plan is %s: only active plans can be logged", userPlan.Status)
+	}
+
+	if !isScheduled(s.userPlanSchedule(&userPlan), planDay, workoutPosition) {
+		return nil, fmt.Errorf("plan day %d has no workout %d", planDay, workoutPosition)
+	}
+
+	if completedAt.IsZero() {
+		completedAt = time.Now()
+	}
+	completion := models.WorkoutCompletion{
+		UserPlanID:      userPlan.ID,
+		UserID:          userID,
+		PlanDay:         planDay,
+		WorkoutPosition: workoutPosition,
+		CompletedAt:     completedAt,
+	}
+	err = s.db.Where(models.WorkoutCompletion{UserPlanID: userPlan.ID, PlanDay: planDay, WorkoutPosition: workoutPosition}).
+		FirstOrCreate(&completion).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Finishing the last scheduled workout completes the plan
+	progress := s.planProgress(&userPlan)
+	if planFinished(progress) {
+		s.completeUserPlan(&userPlan)
+	}
+
+	return s.userPlanResponse(&userPlan, progress), nil
+}
+
+// GetPlannedWorkout returns what a scheduled workout of a member's active plan prescribes
+func (s *PlanService) GetPlannedWorkout(userPlanID, userID uint, planDay, workoutPosition int) (*PlanWorkoutResponse, error) {
+	var userPlan models.UserPlan
+	err := s.db.Where("id = ? AND user_id = ?", userPlanID, userID).
+		Preload("Plan").Preload("PlanVersion").
+		First(&userPlan).Error
+	if err != nil {
+		return nil, errors.New("user plan not found")
+	}
+	if userPlan.Status != "active" {
+		return nil, fmt.Errorf("plan is %s: only active plans can be followed", userPlan.Status)
+	}
+
+	return s.scheduledWorkout(&userPlan, planDay, workoutPosition)
+}
+
+// GetScheduledWorkout returns what a scheduled workout of a member's plan prescribes, whatever the plan status
+// Workouts already performed (e.g. recorded offline) keep following a plan that has since been paused or completed
+func (s *PlanService) GetScheduledWorkout(userPlanID, userID uint, planDay, workoutPosition int) (*PlanWorkoutResponse, error) {
+	var userPlan models.UserPlan
+	err := s.db.Where("id = ? AND user_id = ?", userPlanID, userID).
+		Preload("Plan").Preload("PlanVersion").
+		First(&userPlan).Error
+	if err != nil {
+		return nil, errors.New("user plan not found")
+	}
+
+	return s.scheduledWorkout(&userPlan, planDay, workoutPosition)
+}
+
+// scheduledWorkout finds a scheduled workout in the content of the version a member follows
+func (s *PlanService) scheduledWorkout(userPlan *models.UserPlan, planDay, workoutPosition int) (*PlanWorkoutResponse, error) {
+	for _, workout := range s.userPlanSchedule(userPlan) {
+		if workout.PlanDay != planDay || workout.WorkoutPosition != workoutPosition {
+			continue
+		}
+		day := s.userPlanDays(userPlan)[workout.CycleDay]
+		for w, content := range day.Workouts {
+			if content.Position == workoutPosition || (content.Position == 0 && w+1 == workoutPosition) {
+				return &content, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("plan day %d has no workout %d", planDay, workoutPosition)
+}
+
+// GetCurrentPlan returns the member's most recently assigned active plan,
+// preferring training plans over diet plans, or nil when they have none
+func (s *PlanService) GetCurrentPlan(userID uint) (*UserPlanResponse, error) {
+	var userPlans []models.UserPlan
+	err := s.db.Where("user_id = ? AND status = ?", userID, "active").
+		Preload("Plan").Preload("PlanVersion").
+		Order("assigned_at DESC").
+		Find(&userPlans).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var current *UserPlanResponse
+	responses := s.buildUserPlanResponses(userPlans)
+	for i := range responses {
+		response := &responses[i]
+		if current == nil || (current.Plan.PlanType == "diet" && response.Plan.PlanType != "diet") {
+			current = response
+		}
+	}
+	return current, nil
+}
+
+// planProgress computes a user plan's progress
+func (s *PlanService) planProgress(userPlan *models.UserPlan) PlanProgress {
+	return s.planProgressBatch([]models.UserPlan{*userPlan})[userPlan.ID]
+}
+
+// planProgressBatch computes the progress of several user plans, loading calendar dates,
+// completions and pre-versioning plan content with one query each
+func (s *PlanService) planProgressBatch(userPlans []models.UserPlan) map[uint]PlanProgress {
+	progress := map[uint]PlanProgress{}
+	if len(userPlans) == 0 {
+		return progress
+	}
+
+	ids := []uint{}
+	legacyPlanIDs := []uint{}
+	for _, userPlan := range userPlans {
+		ids = append(ids, userPlan.ID)
+		if userPlan.PlanVersion == nil {
+			legacyPlanIDs = append(legacyPlanIDs, userPlan.PlanID)
+		}
+	}
+
+	calendars := map[uint]map[int]time.Time{}
+	var entries []models.PlanCalendarEntry
+	s.db.Where("user_plan_id IN ?", ids).Find(&entries)
+	for _, entry := range entries {
+		if calendars[entry.UserPlanID] == nil {
+			calendars[entry.UserPlanID] = map[int]time.Time{}
+		}
+		calendars[entry.UserPlanID][entry.PlanDay] = entry.Date
+	}
+
+	completed := map[uint]map[[2]int]bool{}
+	var completions []models.WorkoutCompletion
+	s.db.Where("user_plan_id IN ?", ids).Find(&completions)
+	for _, completion := range completions {
+		if completed[completion.UserPlanID] == nil {
+			completed[completion.UserPlanID] = map[[2]int]bool{}
+		}
+		completed[completion.UserPlanID][[2]int{completion.PlanDay, completion.WorkoutPosition}] = true
+	}
+
+	// Assignments made before versioning follow the plan itself
+	legacyDays := map[uint][]PlanDayResponse{}
+	if len(legacyPlanIDs) > 0 {
+		var plans []models.Plan
+		preloadPlanContent(s.db).Where("id IN ?", legacyPlanIDs).Find(&plans)
+		for i := range plans {
+			legacyDays[plans[i].ID] = buildPlanDayResponses(plans[i].Days)
+		}
+	}
+
+	now := time.Now()
+	for i := range userPlans {
+		userPlan := &userPlans[i]
+
+		days := legacyDays[userPlan.PlanID]
+		if userPlan.PlanVersion != nil {
+			days = decodePlanContent(userPlan.PlanVersion.Content)
+		}
+		duration := userPlanDuration(userPlan)
+		schedule := dateSchedule(expandSchedule(days, duration), userPlan.AssignedAt, calendars[userPlan.ID])
+
+		until := now
+		if userPlan.CompletedAt != nil {
+			until = *userPlan.CompletedAt
+		}
+
+		progress[userPlan.ID] = computePlanProgress(schedule, completed[userPlan.ID], userPlan.AssignedAt, until, duration)
+	}
+
+	return progress
+}
+
+// CompleteFinishedPlans completes active plans whose duration has passed,
+// so plans finish even for members who stop logging workouts
+func (s *PlanService) CompleteFinishedPlans() error {
+	var userPlans []models.UserPlan
+	err := s.db.Where("status = ?", "active").
+		Preload("Plan").Preload("PlanVersion").
+		Find(&userPlans).Error
+	if err != nil {
+		return err
+	}
+
+	progress := s.planProgressBatch(userPlans)
+	for i := range userPlans {
+		if planFinished(progress[userPlans[i].ID]) {
+			s.completeUserPlan(&userPlans[i])
+		}
+	}
+	return nil
+}
+
+// StartCompletionScheduler completes finished plans in the background
+func (s *PlanService) StartCompletionScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.CompleteFinishedPlans(); err != nil {
+				log.Printf("❌ Plan completion check failed: %v", err)
+			}
+		}
+	}()
+}
+
+// completeUserPlan marks a finished plan as completed and congratulates the member
+func (s *PlanService) completeUserPlan(userPlan *models.UserPlan) {
+	now := time.Now()
+	result := s.db.Model(&models.UserPlan{}).
+		Where("id = ? AND status = ?", userPlan.ID, "active").
+		Updates(map[string]interface{}{"status": "completed", "completed_at": now})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return
+	}
+
+	userPlan.Status = "completed"
+	userPlan.CompletedAt = &now
+
+	s.notificationService.Notify(userPlan.UserID, notification.Message{
+		Type:     "success",
+		Category: "plan",
+		Title:    "Plan completed",
+		Message:  fmt.Sprintf("You finished %q. Great work!", userPlan.Plan.Name),
+		Link:     fmt.Sprintf("/plans/my-plans/%d", userPlan.ID),
+	})
+}
+
+// userPlanSchedule expands the content of the version a member follows into scheduled workouts
+func (s *PlanService) userPlanSchedule(userPlan *models.UserPlan) []ScheduledWorkout {
+	var days []PlanDayResponse
+	if userPlan.PlanVersion != nil {
+		days = decodePlanContent(userPlan.PlanVersion.Content)
+	} else {
+		// Assignments made before versioning follow the plan itself
+		var plan models.Plan
+		if err := preloadPlanContent(s.db).First(&plan, userPlan.PlanID).Error; err == nil {
+			days = buildPlanDayResponses(plan.Days)
+		}
+	}
+
+	return expandSchedule(days, userPlanDuration(userPlan))
+}
+
+func userPlanDuration(userPlan *models.UserPlan) int {
+	if userPlan.PlanVersion != nil {
+		return userPlan.PlanVersion.Duration
+	}
+	return userPlan.Plan.Duration
+}
+
+// expandSchedule repeats the plan days over the plan duration
+// The cycle length is the highest day number in the content (e.g. 7 for a weekly program)
+func expandSchedule(days []PlanDayResponse, duration int) []ScheduledWorkout {
+	cycle := 0
+	byNumber := map[int]PlanDayResponse{}
+	for _, day := range days {
+		byNumber[day.DayNumber] = day
+		if day.DayNumber > cycle {
+			cycle = day.DayNumber
+		}
+	}
+
+	schedule := []ScheduledWorkout{}
+	if cycle == 0 {
+		return schedule
+	}
+
+	for planDay := 1; planDay <= duration; planDay++ {
+		cycleDay := (planDay-1)%cycle + 1
+		day, ok := byNumber[cycleDay]
+		if !ok || day.IsRestDay {
+			continue
+		}
+		for w, workout := range day.Workouts {
+			position := workout.Position
+			if position == 0 {
+				position = w + 1
+			}
+			schedule = append(schedule, ScheduledWorkout{
+				PlanDay:         planDay,
+				CycleDay:        cycleDay,
+				WorkoutPosition: position,
+				Name:            workout.Name,
+			})
+		}
+	}
+
+	return schedule
+}
+
+// dateSchedule places scheduled workouts on the member's calendar
+// Plan days without a calendar entry fall on consecutive days from the start date
+func dateSchedule(schedule []ScheduledWorkout, start time.Time, calendar map[int]time.Time) []ScheduledWorkout {
+	for i := range schedule {
+		if date, ok := calendar[schedule[i].PlanDay]; ok {
+			schedule[i].Date = utils.DateOnly(date)
+		} else {
+			schedule[i].Date = utils.DateOnly(start).AddDate(0, 0, schedule[i].PlanDay-1)
+		}
+	}
+	return schedule
+}
+
+// computePlanProgress combines workout completions and elapsed time
+// The plan spans at least its duration, and longer when workouts are spread over
+// fewer training days per week or have been rescheduled
+func computePlanProgress(schedule []ScheduledWorkout, completed map[[2]int]bool, start, until time.Time, duration int) PlanProgress {
+	startDay, today := utils.DateOnly(start), utils.DateOnly(until)
+	end := startDay.AddDate(0, 0, duration-1)
+	for _, workout := range schedule {
+		if workout.Date.After(end) {
+			end = workout.Date
+		}
+	}
+
+	span := daysBetween(startDay, end) + 1
+	elapsedDays := daysBetween(startDay, today)
+	if elapsedDays > span {
+		elapsedDays = span
+	}
+
+	progress := PlanProgress{
+		ScheduledWorkouts: len(schedule),
+		ElapsedDays:       elapsedDays,
+		Duration:          span,
+	}
+
+	for _, workout := range schedule {
+		// Today's workouts count as due once the member has done them
+		done := completed[[2]int{workout.PlanDay, workout.WorkoutPosition}]
+		if workout.Date.Before(today) || done {
+			progress.DueWorkouts++
+		}
+		if done {
+			progress.CompletedWorkouts++
+		}
+	}
+
+	if span > 0 {
+		progress.TimeProgress = roundPercent(float64(elapsedDays) / float64(span))
+	}
+	if progress.ScheduledWorkouts > 0 {
+		progress.WorkoutProgress = roundPercent(float64(progress.CompletedWorkouts) / float64(progress.ScheduledWorkouts))
+	}
+	if progress.DueWorkouts > 0 {
+		progress.Adherence = roundPercent(float64(progress.CompletedWorkouts) / float64(progress.DueWorkouts))
+	}
+
+	return progress
+}
+
+// overallProgress is the single figure shown to members: workouts done when the plan
+// schedules workouts, otherwise (e.g. diet plans) the share of the duration elapsed
+func (p PlanProgress) overallProgress() float64 {
+	if p.ScheduledWorkouts > 0 {
+		return p.WorkoutProgress
+	}
+	return p.TimeProgress
+}
+
+// planFinished reports whether every scheduled workout is done or the duration has passed
+func planFinished(progress PlanProgress) bool {
+	if progress.ScheduledWorkouts > 0 && progress.CompletedWorkouts >= progress.ScheduledWorkouts {
+		return true
+	}
+	return progress.Duration > 0 && progress.ElapsedDays >= progress.Duration
+}
+
+// daysBetween counts the calendar days from one date to another (0 when to is earlier)
+func daysBetween(from, to time.Time) int {
+	days := int(math.Round(utils.DateOnly(to).Sub(utils.DateOnly(from)).Hours() / 24))
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+func isScheduled(schedule []ScheduledWorkout, planDay, workoutPosition int) bool {
+	for _, workout := range schedule {
+		if workout.PlanDay == planDay && workout.WorkoutPosition == workoutPosition {
+			return true
+		}
+	}
+	return false
+}
+
+func roundPercent(ratio float64) float64 {
+	return math.Round(math.Min(ratio, 1)*1000) / 10
+}
@@ -0,0 +1,67 @@
+package plan
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/utils"
+)
+
+func weeklyProgram() []PlanDayResponse {
+	return []PlanDayResponse{
+		{DayNumber: 1, Workouts: []PlanWorkoutResponse{{Name: "Upper", Position: 1}}},
+		{DayNumber: 2, IsRestDay: true},
+		{DayNumber: 3, Workouts: []PlanWorkoutResponse{{Name: "Lower", Position: 1}, {Name: "Core", Position: 2}}},
+	}
+}
+
+func TestExpandScheduleRepeatsCycle(t *testing.T) {
+	schedule := expandSchedule(weeklyProgram(), 7)
+
+	// Days 1, 4 and 7 repeat day 1; days 3 and 6 repeat day 3 with two workouts
+	if len(schedule) != 7 {
+		t.Fatalf("expected 7 scheduled workouts, got %d: %+v", len(schedule), schedule)
+	}
+	last := schedule[len(schedule)-1]
+	if last.PlanDay != 7 || last.CycleDay != 1 || last.Name != "Upper" {
+		t.Errorf("unexpected last workout %+v", last)
+	}
+}
+
+func TestComputePlanProgress(t *testing.T) {
+	start := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	schedule := dateSchedule(expandSchedule(weeklyProgram(), 7), start, nil)
+	completed := map[[2]int]bool{{1, 1}: true, {3, 1}: true}
+
+	progress := computePlanProgress(schedule, completed, start, start.AddDate(0, 0, 3), 7)
+
+	if progress.DueWorkouts != 3 || progress.CompletedWorkouts != 2 {
+		t.Fatalf("expected 2 of 3 due workouts done, got %+v", progress)
+	}
+	if progress.Adherence != 66.7 || progress.WorkoutProgress != 28.6 || progress.TimeProgress != 42.9 {
+		t.Errorf("unexpected percentages %+v", progress)
+	}
+	if planFinished(progress) {
+		t.Error("plan should not be finished")
+	}
+
+	if !planFinished(computePlanProgress(schedule, completed, start, start.AddDate(0, 0, 10), 7)) {
+		t.Error("plan should be finished once the duration has passed")
+	}
+
+	// Workouts moved past the duration extend the plan
+	schedule[len(schedule)-1].Date = utils.DateOnly(start).AddDate(0, 0, 9)
+	if planFinished(computePlanProgress(schedule, completed, start, start.AddDate(0, 0, 8), 7)) {
+		t.Error("plan should run until its last scheduled workout")
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	assigned := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	if days := daysBetween(assigned, assigned.Add(5*time.Hour)); days != 0 {
+		t.Errorf("expected 0 elapsed days on the assignment day, got %d", days)
+	}
+	if days := daysBetween(assigned, time.Date(2024, 3, 4, 1, 0, 0, 0, time.UTC)); days != 3 {
+		t.Errorf("expected 3 elapsed days, got %d", days)
+	}
+}
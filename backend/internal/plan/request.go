@@ -0,0 +1,242 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/notification"
+
+	"gorm.io/gorm"
+)
+
+// PlanAssignmentRequestResponse represents a member's plan request
+type PlanAssignmentRequestResponse struct {
+	ID            uint       `json:"id"`
+	UserID        uint       `json:"user_id"`
+	MemberName    string     `json:"member_name,omitempty"`
+	PlanID        uint       `json:"plan_id"`
+	PlanName      string     `json:"plan_name,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	Status        string     `json:"status"` // pending, approved, rejected, withdrawn
+	ReviewerID    *uint      `json:"reviewer_id,omitempty"`
+	RoutedTo      string     `json:"routed_to"` // trainer or admins
+	ReviewedBy    *uint      `json:"reviewed_by,omitempty"`
+	ReviewComment string     `json:"review_comment,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	UserPlanID    *uint      `json:"user_plan_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// RequestPlan records a member's request for a plan and routes it for review
+func (s *PlanService) RequestPlan(userID, planID uint, reason string) (*PlanAssignmentRequestResponse, error) {
+	var plan models.Plan
+	if err := s.db.First(&plan, planID).Error; err != nil || !plan.IsActive {
+		return nil, errors.New("plan not found or not available")
+	}
+
+	var pending int64
+	s.db.Model(&models.PlanAssignmentRequest{}).
+		Where("user_id = ? AND plan_id = ? AND status = ?", userID, planID, "pending").
+		Count(&pending)
+	if pending > 0 {
+		return nil, errors.New("you already have a pending request for this plan")
+	}
+
+	request := models.PlanAssignmentRequest{
+		UserID:     userID,
+		PlanID:     planID,
+		Reason:     strings.TrimSpace(reason),
+		Status:     "pending",
+		ReviewerID: MemberTrainerID(s.db, userID),
+	}
+	if err := s.db.Create(&request).Error; err != nil {
+		return nil, err
+	}
+
+	var member models.User
+	s.db.First(&member, userID)
+
+	msg := notification.Message{
+		Type:     "info",
+		Category: "plan_request",
+		Title:    "New plan request",
+		Message:  fmt.Sprintf("%s %s asked to follow %q.", member.FirstName, member.LastName, plan.Name),
+		Link:     fmt.Sprintf("/plans/requests/%d", request.ID),
+	}
+	if request.ReviewerID != nil {
+		s.notificationService.Notify(*request.ReviewerID, msg)
+	} else {
+		var adminIDs []uint
+		s.db.Model(&models.User{}).Where("role = ? AND is_active = ?", "admin", true).Pluck("id", &adminIDs)
+		s.notificationService.NotifyMany(adminIDs, msg)
+	}
+
+	return s.GetPlanRequest(request.ID)
+}
+
+// GetPlanRequests lists plan requests visible to the caller
+// Members see their own requests, trainers the requests routed to them and admins all requests
+func (s *PlanService) GetPlanRequests(userID uint, userRole, status string) ([]PlanAssignmentRequestResponse, error) {
+	var requests []models.PlanAssignmentRequest
+	query := s.db.Preload("User").Preload("Plan")
+
+	switch userRole {
+	case "admin":
+	case "trainer":
+		query = query.Where("reviewer_id = ?", userID)
+	default:
+		query = query.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Order("created_at DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []PlanAssignmentRequestResponse{}
+	for i := range requests {
+		responses = append(responses, *buildPlanRequestResponse(&requests[i]))
+	}
+	return responses, nil
+}
+
+// GetPlanRequest retrieves a single plan request
+func (s *PlanService) GetPlanRequest(requestID uint) (*PlanAssignmentRequestResponse, error) {
+	var request models.PlanAssignmentRequest
+	if err := s.db.Preload("User").Preload("Plan").First(&request, requestID).Error; err != nil {
+		return nil, errors.New("plan request not found")
+	}
+	return buildPlanRequestResponse(&request), nil
+}
+
+// ReviewPlanRequest approves or rejects a pending request
+// Approval assigns the plan to the member; both outcomes notify the member
+// force pauses conflicting plans, as in AssignPlan, when the member already follows a plan of the same type
+func (s *PlanService) ReviewPlanRequest(requestID uint, approve, force bool, comment string, reviewerID uint, reviewerRole string) (*PlanAssignmentRequestResponse, error) {
+	var request models.PlanAssignmentRequest
+	if err := s.db.Preload("Plan").First(&request, requestID).Error; err != nil {
+		return nil, errors.New("plan request not found")
+	}
+
+	if reviewerRole != "admin" && (request.ReviewerID == nil || *request.ReviewerID != reviewerID) {
+		return nil, errors.New("you can only review requests routed to you")
+	}
+	if request.Status != "pending" {
+		return nil, fmt.Errorf("request is already %s", request.Status)
+	}
+
+	now := time.Now()
+	request.ReviewedBy = &reviewerID
+	request.ReviewedAt = &now
+	request.ReviewComment = strings.TrimSpace(comment)
+	request.Status = "rejected"
+	if approve {
+		request.Status = "approved"
+	}
+
+	// The review and the assignment are committed together; the status guard makes
+	// sure concurrent reviews of the same request cannot assign the plan twice
+	var assignment *planAssignment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PlanAssignmentRequest{}).
+			Where("id = ? AND status = ?", request.ID, "pending").
+			Updates(map[string]interface{}{
+				"status":         request.Status,
+				"reviewed_by":    reviewerID,
+				"reviewed_at":    now,
+				"review_comment": request.ReviewComment,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("request is already reviewed")
+		}
+		if !approve {
+			return nil
+		}
+
+		var err error
+		assignment, err = s.assignPlan(tx, request.UserID, request.PlanID, reviewerID, force)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.PlanAssignmentRequest{}).Where("id = ?", request.ID).
+			Update("user_plan_id", assignment.userPlan.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if assignment != nil {
+		s.announceAssignment(assignment)
+	}
+
+	msg := notification.Message{
+		Type:     "success",
+		Category: "plan_request",
+		Title:    "Plan request approved",
+		Message:  fmt.Sprintf("Your request for %q was approved.", request.Plan.Name),
+		Link:     "/plans/my-plans",
+	}
+	if !approve {
+		msg.Type = "warning"
+		msg.Title = "Plan request declined"
+		msg.Message = fmt.Sprintf("Your request for %q was declined.", request.Plan.Name)
+		msg.Link = fmt.Sprintf("/plans/requests/%d", request.ID)
+	}
+	if request.ReviewComment != "" {
+		msg.Message += " Comment: " + request.ReviewComment
+	}
+	s.notificationService.Notify(request.UserID, msg)
+
+	return s.GetPlanRequest(request.ID)
+}
+
+// WithdrawPlanRequest lets a member cancel their pending request
+func (s *PlanService) WithdrawPlanRequest(requestID, userID uint) (*PlanAssignmentRequestResponse, error) {
+	var request models.PlanAssignmentRequest
+	if err := s.db.Where("id = ? AND user_id = ?", requestID, userID).First(&request).Error; err != nil {
+		return nil, errors.New("plan request not found")
+	}
+	if request.Status != "pending" {
+		return nil, fmt.Errorf("request is already %s", request.Status)
+	}
+
+	if err := s.db.Model(&request).Update("status", "withdrawn").Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetPlanRequest(request.ID)
+}
+
+func buildPlanRequestResponse(request *models.PlanAssignmentRequest) *PlanAssignmentRequestResponse {
+	response := &PlanAssignmentRequestResponse{
+		ID:            request.ID,
+		UserID:        request.UserID,
+		PlanID:        request.PlanID,
+		Reason:        request.Reason,
+		Status:        request.Status,
+		ReviewerID:    request.ReviewerID,
+		RoutedTo:      "admins",
+		ReviewedBy:    request.ReviewedBy,
+		ReviewComment: request.ReviewComment,
+		ReviewedAt:    request.ReviewedAt,
+		UserPlanID:    request.UserPlanID,
+		CreatedAt:     request.CreatedAt,
+	}
+	if request.ReviewerID != nil {
+		response.RoutedTo = "trainer"
+	}
+	if request.User.ID != 0 {
+		response.MemberName = request.User.FirstName + " " + request.User.LastName
+	}
+	if request.Plan.ID != 0 {
+		response.PlanName = request.Plan.Name
+	}
+	return response
+}
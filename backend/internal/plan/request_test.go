@@ -0,0 +1,79 @@
+package plan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/testdb"
+)
+
+// createTestPlan creates a one-day fitness plan authored by the trainer
+func createTestPlan(t *testing.T, service *PlanService, trainerID uint, name string) *PlanResponse {
+	t.Helper()
+	plan, err := service.CreatePlan(&PlanRequest{
+		Name:     name,
+		GoalType: "gain_muscle",
+		PlanType: "fitness",
+		Duration: 7,
+		Days: []PlanDayRequest{{DayNumber: 1, Workouts: []PlanWorkoutRequest{{
+			Name:      "Full body",
+			Exercises: []ExercisePrescriptionRequest{{ExerciseName: "Push-up", Sets: 3, RepsMin: 10, RepsMax: 15, LoadType: "bodyweight"}},
+		}}}},
+	}, trainerID, "trainer")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	return plan
+}
+
+func TestReviewPlanRequestAssignsOnce(t *testing.T) {
+	cfg := testdb.Connect(t)
+	service := NewPlanService(cfg)
+	trainer := testdb.CreateUser(t, "trainer")
+	member := testdb.CreateUser(t, "member")
+
+	current := createTestPlan(t, service, trainer.ID, "Current plan")
+	requested := createTestPlan(t, service, trainer.ID, "Requested plan")
+	if _, err := service.AssignPlan(member.ID, current.ID, trainer.ID, false); err != nil {
+		t.Fatalf("failed to assign plan: %v", err)
+	}
+
+	request, err := service.RequestPlan(member.ID, requested.ID, "Ready for more")
+	if err != nil {
+		t.Fatalf("failed to request plan: %v", err)
+	}
+
+	countPlans := func() int64 {
+		var count int64
+		database.GetDB().Model(&models.UserPlan{}).Where("user_id = ?", member.ID).Count(&count)
+		return count
+	}
+
+	// A failed assignment leaves the request pending
+	var conflict *PlanConflictError
+	if _, err := service.ReviewPlanRequest(request.ID, true, false, "", trainer.ID, "trainer"); !errors.As(err, &conflict) {
+		t.Fatalf("expected a plan conflict, got %v", err)
+	}
+	if pending, _ := service.GetPlanRequest(request.ID); pending.Status != "pending" || countPlans() != 1 {
+		t.Fatalf("expected the request to stay pending with one plan, got %s with %d plans", pending.Status, countPlans())
+	}
+
+	approved, err := service.ReviewPlanRequest(request.ID, true, true, "Go for it", trainer.ID, "trainer")
+	if err != nil {
+		t.Fatalf("failed to approve request: %v", err)
+	}
+	if approved.Status != "approved" || approved.UserPlanID == nil {
+		t.Fatalf("expected an approved request with an assignment, got %+v", approved)
+	}
+
+	// Approving again is refused and assigns nothing
+	if _, err := service.ReviewPlanRequest(request.ID, true, true, "", trainer.ID, "trainer"); err == nil || !strings.HasPrefix(err.Error(), "request is already") {
+		t.Fatalf("expected the second review to be refused, got %v", err)
+	}
+	if count := countPlans(); count != 2 {
+		t.Fatalf("expected 2 assignments, got %d", count)
+	}
+}
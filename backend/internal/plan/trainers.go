@@ -0,0 +1,33 @@
+package plan
+
+import (
+	"fittrackplus/internal/common/models"
+
+	"gorm.io/gorm"
+)
+
+// currentAssignmentStatuses are the user plan statuses that keep a trainer attached to a member
+var currentAssignmentStatuses = []string{"active", "paused"}
+
+// MemberTrainerID returns the trainer who most recently assigned the member a plan
+// they are still following, or nil when the member has no trainer
+func MemberTrainerID(db *gorm.DB, memberID uint) *uint {
+	var userPlan models.UserPlan
+	err := db.Joins("JOIN users ON users.id = user_plans.assigned_by").
+		Where("user_plans.user_id = ? AND user_plans.status IN ? AND users.role = ?", memberID, currentAssignmentStatuses, "trainer").
+		Order("user_plans.assigned_at DESC").
+		First(&userPlan).Error
+	if err != nil {
+		return nil
+	}
+	return userPlan.AssignedBy
+}
+
+// IsAssignedTrainer reports whether the trainer assigned the member a plan they are still following
+func IsAssignedTrainer(db *gorm.DB, trainerID, memberID uint) bool {
+	var count int64
+	db.Model(&models.UserPlan{}).
+		Where("user_id = ? AND assigned_by = ? AND status IN ?", memberID, trainerID, currentAssignmentStatuses).
+		Count(&count)
+	return count > 0
+}
@@ -0,0 +1,440 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/notification"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlanVersionResponse represents a published plan version
+type PlanVersionResponse struct {
+	ID          uint              `json:"id"`
+	PlanID      uint              `json:"plan_id"`
+	Version     int               `json:"version"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	GoalType    string            `json:"goal_type"`
+	PlanType    string            `json:"plan_type"`
+	Duration    int               `json:"duration"`
+	Changelog   string            `json:"changelog,omitempty"`
+	PublishedBy *uint             `json:"published_by,omitempty"`
+	PublishedAt time.Time         `json:"published_at"`
+	Days        []PlanDayResponse `json:"days,omitempty"` // only included when fetching a single version
+}
+
+// PlanChange describes one difference between two plan versions
+type PlanChange struct {
+	Path   string      `json:"path"`   // e.g. "days[2].workouts[1].exercises[3].sets"
+	Change string      `json:"change"` // added, removed, changed
+	From   interface{} `json:"from,omitempty"`
+	To     interface{} `json:"to,omitempty"`
+}
+
+// PlanDiffResponse lists the changes between two versions of a plan
+type PlanDiffResponse struct {
+	PlanID      uint         `json:"plan_id"`
+	FromVersion int          `json:"from_version"`
+	ToVersion   int          `json:"to_version"`
+	Changes     []PlanChange `json:"changes"`
+}
+
+// PublishVersion snapshots the plan's current content as a new immutable version
+// and lets members on older versions know an update is available
+func (s *PlanService) PublishVersion(planID uint, changelog string, publishedBy uint, userRole string) (*PlanVersionResponse, error) {
+	var plan models.Plan
+	if err := preloadPlanContent(s.db).First(&plan, planID).Error; err != nil {
+		return nil, errors.New("plan not found")
+	}
+	if !canManagePlan(&plan, publishedBy, userRole) {
+		return nil, errors.New("you can only manage your own plans or shared library plans")
+	}
+
+	var version *models.PlanVersion
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		version, err = publishPlanVersion(tx, &plan, changelog, &publishedBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Members keep their pinned version until they choose to migrate
+	var memberIDs []uint
+	s.db.Model(&models.UserPlan{}).
+		Where("plan_id = ? AND status IN ? AND (plan_version_id IS NULL OR plan_version_id <> ?)", plan.ID, []string{"active", "paused"}, version.ID).
+		Distinct().Pluck("user_id", &memberIDs)
+	if len(memberIDs) > 0 {
+		s.notificationService.NotifyMany(memberIDs, notification.Message{
+			Type:     "info",
+			Category: "plan",
+			Title:    "Plan update available",
+			Message:  fmt.Sprintf("Version %d of %q has been published. Review the changes and switch when you are ready.", version.Version, plan.Name),
+			Link:     fmt.Sprintf("/plans/%d/diff", plan.ID),
+		})
+	}
+
+	return buildPlanVersionResponse(version, true), nil
+}
+
+// GetPlanVersions lists the published versions of a plan, newest first
+func (s *PlanService) GetPlanVersions(planID uint) ([]PlanVersionResponse, error) {
+	var plan models.Plan
+	if err := s.db.First(&plan, planID).Error; err != nil {
+		return nil, errors.New("plan not found")
+	}
+
+	var versions []models.PlanVersion
+	if err := s.db.Where("plan_id = ?", planID).Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []PlanVersionResponse{}
+	for i := range versions {
+		responses = append(responses, *buildPlanVersionResponse(&versions[i], false))
+	}
+	return responses, nil
+}
+
+// GetPlanVersion retrieves a single published version with its content
+func (s *PlanService) GetPlanVersion(planID uint, version int) (*PlanVersionResponse, error) {
+	planVersion, err := s.findPlanVersion(planID, version)
+	if err != nil {
+		return nil, err
+	}
+	return buildPlanVersionResponse(planVersion, true), nil
+}
+
+// DiffPlanVersions compares two published versions of a plan
+func (s *PlanService) DiffPlanVersions(planID uint, fromVersion, toVersion int) (*PlanDiffResponse, error) {
+	from, err := s.findPlanVersion(planID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.findPlanVersion(planID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PlanDiffResponse{
+		PlanID:      planID,
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Changes:     diffPlanVersions(buildPlanVersionResponse(from, true), buildPlanVersionResponse(to, true)),
+	}, nil
+}
+
+// GetUserPlan retrieves one of the member's plans with the content of the pinned version
+// Diet plans also list the foods that conflict with the member's allergies
+func (s *PlanService) GetUserPlan(userPlanID, userID uint) (*UserPlanResponse, error) {
+	var userPlan models.UserPlan
+	err := s.db.Where("id = ? AND user_id = ?", userPlanID, userID).
+		Preload("Plan").Preload("PlanVersion").
+		First(&userPlan).Error
+	if err != nil {
+		return nil, errors.New("user plan not found")
+	}
+
+	response := s.buildUserPlanResponse(&userPlan)
+	if userPlan.PlanVersion != nil {
+		response.Plan.Days = decodePlanContent(userPlan.PlanVersion.Content)
+		if response.Plan.PlanType == "diet" {
+			response.AllergyConflicts = findAllergyConflicts(response.Plan.Days, s.memberAllergies(userID))
+		}
+	}
+	return response, nil
+}
+
+// MigrateUserPlan moves a member's plan onto the latest published version
+func (s *PlanService) MigrateUserPlan(userPlanID, userID uint) (*UserPlanResponse, error) {
+	var userPlan models.UserPlan
+	if err := s.db.Where("id = ? AND user_id = ?", userPlanID, userID).First(&userPlan).Error; err != nil {
+		return nil, errors.New("user plan not found")
+	}
+	if userPlan.Status != "active" && userPlan.Status != "paused" {
+		return nil, errors.New("only active or paused plans can be migrated")
+	}
+
+	latest, err := s.latestPlanVersion(userPlan.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if userPlan.PlanVersionID != nil && *userPlan.PlanVersionID == latest.ID {
+		return nil, errors.New("plan is already on the latest version")
+	}
+
+	if err := s.db.Model(&userPlan).Update("plan_version_id", latest.ID).Error; err != nil {
+		return nil, err
+	}
+
+	// Days still ahead follow the new version's content from today
+	userPlan.PlanVersionID = &latest.ID
+	userPlan.PlanVersion = latest
+	if err := s.rescheduleUpcoming(&userPlan); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserPlan(userPlan.ID, userID)
+}
+
+// latestPlanVersion returns the newest published version of a plan,
+// publishing one first for plans created before versioning existed
+func (s *PlanService) latestPlanVersion(planID uint) (*models.PlanVersion, error) {
+	var plan models.Plan
+	if err := preloadPlanContent(s.db).First(&plan, planID).Error; err != nil {
+		return nil, errors.New("plan not found")
+	}
+
+	if plan.LatestVersion == 0 {
+		var version *models.PlanVersion
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			version, err = publishPlanVersion(tx, &plan, "Initial version", nil)
+			return err
+		})
+		return version, err
+	}
+
+	return s.findPlanVersion(planID, plan.LatestVersion)
+}
+
+func (s *PlanService) findPlanVersion(planID uint, version int) (*models.PlanVersion, error) {
+	var planVersion models.PlanVersion
+	if err := s.db.Where("plan_id = ? AND version = ?", planID, version).First(&planVersion).Error; err != nil {
+		return nil, fmt.Errorf("version %d of plan not found", version)
+	}
+	return &planVersion, nil
+}
+
+// publishPlanVersion stores a snapshot of a plan loaded with its content
+func publishPlanVersion(tx *gorm.DB, plan *models.Plan, changelog string, publishedBy *uint) (*models.PlanVersion, error) {
+	// Lock the plan row so concurrent publishes get distinct numbers
+	var current models.Plan
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "latest_version").First(&current, plan.ID).Error; err != nil {
+		return nil, err
+	}
+
+	content, err := json.Marshal(snapshotPlanDays(plan.Days))
+	if err != nil {
+		return nil, err
+	}
+
+	version := models.PlanVersion{
+		PlanID:      plan.ID,
+		Version:     current.LatestVersion + 1,
+		Name:        plan.Name,
+		Description: plan.Description,
+		GoalType:    plan.GoalType,
+		PlanType:    plan.PlanType,
+		Duration:    plan.Duration,
+		Content:     string(content),
+		Diet:        plan.Diet,
+		Changelog:   changelog,
+		PublishedBy: publishedBy,
+		PublishedAt: time.Now(),
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.Plan{}).Where("id = ?", plan.ID).Update("latest_version", version.Version).Error; err != nil {
+		return nil, err
+	}
+	plan.LatestVersion = version.Version
+
+	return &version, nil
+}
+
+// snapshotPlanDays converts plan days to their response format without row IDs,
+// since the draft rows can be replaced after the version is published
+func snapshotPlanDays(days []models.PlanDay) []PlanDayResponse {
+	snapshot := buildPlanDayResponses(days)
+	for d := range snapshot {
+		snapshot[d].ID = 0
+		for w := range snapshot[d].Workouts {
+			snapshot[d].Workouts[w].ID = 0
+			for e := range snapshot[d].Workouts[w].Exercises {
+				snapshot[d].Workouts[w].Exercises[e].ID = 0
+			}
+		}
+		for m := range snapshot[d].Meals {
+			snapshot[d].Meals[m].ID = 0
+			for f := range snapshot[d].Meals[m].Foods {
+				snapshot[d].Meals[m].Foods[f].ID = 0
+				for i := range snapshot[d].Meals[m].Foods[f].Substitutions {
+					snapshot[d].Meals[m].Foods[f].Substitutions[i].ID = 0
+				}
+			}
+		}
+	}
+	return snapshot
+}
+
+func decodePlanContent(content string) []PlanDayResponse {
+	days := []PlanDayResponse{}
+	if content != "" {
+		json.Unmarshal([]byte(content), &days)
+	}
+	return days
+}
+
+func buildPlanVersionResponse(version *models.PlanVersion, withContent bool) *PlanVersionResponse {
+	response := &PlanVersionResponse{
+		ID:          version.ID,
+		PlanID:      version.PlanID,
+		Version:     version.Version,
+		Name:        version.Name,
+		Description: version.Description,
+		GoalType:    version.GoalType,
+		PlanType:    version.PlanType,
+		Duration:    version.Duration,
+		Changelog:   version.Changelog,
+		PublishedBy: version.PublishedBy,
+		PublishedAt: version.PublishedAt,
+	}
+	if withContent {
+		response.Days = decodePlanContent(version.Content)
+	}
+	return response
+}
+
+// diffPlanVersions lists field level changes between two versions
+// Days are matched by day number; workouts, exercises, meals and foods by position
+func diffPlanVersions(from, to *PlanVersionResponse) []PlanChange {
+	changes := []PlanChange{}
+	compare := func(path string, a, b interface{}) {
+		if a != b {
+			changes = append(changes, PlanChange{Path: path, Change: "changed", From: a, To: b})
+		}
+	}
+
+	compare("name", from.Name, to.Name)
+	compare("description", from.Description, to.Description)
+	compare("goal_type", from.GoalType, to.GoalType)
+	compare("plan_type", from.PlanType, to.PlanType)
+	compare("duration", from.Duration, to.Duration)
+
+	fromDays := map[int]PlanDayResponse{}
+	for _, day := range from.Days {
+		fromDays[day.DayNumber] = day
+	}
+	toDays := map[int]PlanDayResponse{}
+	for _, day := range to.Days {
+		toDays[day.DayNumber] = day
+	}
+
+	for _, day := range from.Days {
+		if _, ok := toDays[day.DayNumber]; !ok {
+			changes = append(changes, PlanChange{Path: fmt.Sprintf("days[%d]", day.DayNumber), Change: "removed", From: day.Name})
+		}
+	}
+
+	for _, toDay := range to.Days {
+		dayPath := fmt.Sprintf("days[%d]", toDay.DayNumber)
+		fromDay, ok := fromDays[toDay.DayNumber]
+		if !ok {
+			changes = append(changes, PlanChange{Path: dayPath, Change: "added", To: toDay.Name})
+			continue
+		}
+
+		compare(dayPath+".name", fromDay.Name, toDay.Name)
+		compare(dayPath+".is_rest_day", fromDay.IsRestDay, toDay.IsRestDay)
+		compare(dayPath+".notes", fromDay.Notes, toDay.Notes)
+
+		for w := 0; w < len(fromDay.Workouts) || w < len(toDay.Workouts); w++ {
+			workoutPath := fmt.Sprintf("%s.workouts[%d]", dayPath, w+1)
+			if w >= len(toDay.Workouts) {
+				changes = append(changes, PlanChange{Path: workoutPath, Change: "removed", From: fromDay.Workouts[w].Name})
+				continue
+			}
+			if w >= len(fromDay.Workouts) {
+				changes = append(changes, PlanChange{Path: workoutPath, Change: "added", To: toDay.Workouts[w].Name})
+				continue
+			}
+
+			fromWorkout, toWorkout := fromDay.Workouts[w], toDay.Workouts[w]
+			compare(workoutPath+".name", fromWorkout.Name, toWorkout.Name)
+			compare(workoutPath+".notes", fromWorkout.Notes, toWorkout.Notes)
+
+			for e := 0; e < len(fromWorkout.Exercises) || e < len(toWorkout.Exercises); e++ {
+				exercisePath := fmt.Sprintf("%s.exercises[%d]", workoutPath, e+1)
+				if e >= len(toWorkout.Exercises) {
+					changes = append(changes, PlanChange{Path: exercisePath, Change: "removed", From: fromWorkout.Exercises[e].ExerciseName})
+					continue
+				}
+				if e >= len(fromWorkout.Exercises) {
+					changes = append(changes, PlanChange{Path: exercisePath, Change: "added", To: toWorkout.Exercises[e].ExerciseName})
+					continue
+				}
+
+				a, b := fromWorkout.Exercises[e], toWorkout.Exercises[e]
+				compare(exercisePath+".exercise_name", a.ExerciseName, b.ExerciseName)
+				compare(exercisePath+".sets", a.Sets, b.Sets)
+				compare(exercisePath+".reps_min", a.RepsMin, b.RepsMin)
+				compare(exercisePath+".reps_max", a.RepsMax, b.RepsMax)
+				compare(exercisePath+".load_type", a.LoadType, b.LoadType)
+				compare(exercisePath+".load_value", a.LoadValue, b.LoadValue)
+				compare(exercisePath+".tempo", a.Tempo, b.Tempo)
+				compare(exercisePath+".rest_seconds", a.RestSeconds, b.RestSeconds)
+				compare(exercisePath+".rpe", a.RPE, b.RPE)
+				compare(exercisePath+".duration_seconds", a.DurationSeconds, b.DurationSeconds)
+				compare(exercisePath+".distance_meters", a.DistanceMeters, b.DistanceMeters)
+				compare(exercisePath+".notes", a.Notes, b.Notes)
+			}
+		}
+
+		for m := 0; m < len(fromDay.Meals) || m < len(toDay.Meals); m++ {
+			mealPath := fmt.Sprintf("%s.meals[%d]", dayPath, m+1)
+			if m >= len(toDay.Meals) {
+				changes = append(changes, PlanChange{Path: mealPath, Change: "removed", From: fromDay.Meals[m].Meal})
+				continue
+			}
+			if m >= len(fromDay.Meals) {
+				changes = append(changes, PlanChange{Path: mealPath, Change: "added", To: toDay.Meals[m].Meal})
+				continue
+			}
+
+			fromMeal, toMeal := fromDay.Meals[m], toDay.Meals[m]
+			compare(mealPath+".meal", fromMeal.Meal, toMeal.Meal)
+			compare(mealPath+".name", fromMeal.Name, toMeal.Name)
+			compare(mealPath+".notes", fromMeal.Notes, toMeal.Notes)
+
+			for f := 0; f < len(fromMeal.Foods) || f < len(toMeal.Foods); f++ {
+				foodPath := fmt.Sprintf("%s.foods[%d]", mealPath, f+1)
+				if f >= len(toMeal.Foods) {
+					changes = append(changes, PlanChange{Path: foodPath, Change: "removed", From: fromMeal.Foods[f].FoodName})
+					continue
+				}
+				if f >= len(fromMeal.Foods) {
+					changes = append(changes, PlanChange{Path: foodPath, Change: "added", To: toMeal.Foods[f].FoodName})
+					continue
+				}
+
+				a, b := fromMeal.Foods[f], toMeal.Foods[f]
+				compare(foodPath+".food_name", a.FoodName, b.FoodName)
+				compare(foodPath+".grams", a.Grams, b.Grams)
+				compare(foodPath+".notes", a.Notes, b.Notes)
+				compare(foodPath+".substitutions", substitutionNames(a.Substitutions), substitutionNames(b.Substitutions))
+			}
+		}
+	}
+
+	return changes
+}
+
+// substitutionNames lists substitutions as text so changes compare as a single value
+func substitutionNames(substitutions []PlanSubstitutionResponse) string {
+	names := []string{}
+	for _, substitution := range substitutions {
+		names = append(names, fmt.Sprintf("%s %gg", substitution.FoodName, substitution.Grams))
+	}
+	return strings.Join(names, ", ")
+}
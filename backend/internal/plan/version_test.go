@@ -0,0 +1,52 @@
+package plan
+
+import "testing"
+
+func TestDiffPlanVersions(t *testing.T) {
+	from := &PlanVersionResponse{
+		Name:     "Strength Basics",
+		Duration: 28,
+		Days: []PlanDayResponse{
+			{DayNumber: 1, Name: "Lower", Workouts: []PlanWorkoutResponse{{Name: "A", Exercises: []ExercisePrescriptionResponse{
+				{ExerciseName: "Squat", Sets: 3, RepsMin: 5, RepsMax: 5},
+				{ExerciseName: "Lunge", Sets: 2, RepsMin: 10, RepsMax: 10},
+			}}}},
+			{DayNumber: 2, Name: "Rest", IsRestDay: true},
+		},
+	}
+	to := &PlanVersionResponse{
+		Name:     "Strength Basics",
+		Duration: 42,
+		Days: []PlanDayResponse{
+			{DayNumber: 1, Name: "Lower", Workouts: []PlanWorkoutResponse{{Name: "A", Exercises: []ExercisePrescriptionResponse{
+				{ExerciseName: "Squat", Sets: 5, RepsMin: 5, RepsMax: 5},
+			}}}},
+			{DayNumber: 3, Name: "Upper", Workouts: []PlanWorkoutResponse{{Name: "B"}}},
+		},
+	}
+
+	changes := diffPlanVersions(from, to)
+
+	want := map[string]string{
+		"duration":                              "changed",
+		"days[2]":                               "removed",
+		"days[3]":                               "added",
+		"days[1].workouts[1].exercises[1].sets": "changed",
+		"days[1].workouts[1].exercises[2]":      "removed",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(want), len(changes), changes)
+	}
+	for _, change := range changes {
+		if want[change.Path] != change.Change {
+			t.Errorf("unexpected change %s %s", change.Change, change.Path)
+		}
+	}
+}
+
+func TestDiffPlanVersionsIdentical(t *testing.T) {
+	version := &PlanVersionResponse{Name: "Mobility", Duration: 7, Days: []PlanDayResponse{{DayNumber: 1, Name: "Flow"}}}
+	if changes := diffPlanVersions(version, version); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
@@ -33,10 +33,11 @@ func NewProfileService(cfg *config.Config) *ProfileService {
 // ProfileSetupRequest represents the complete profile setup data
 type ProfileSetupRequest struct {
 	// Basic Information
-	Height   float64 `json:"height" binding:"required,min=50,max=300"`
-	Weight   float64 `json:"weight" binding:"required,min=20,max=500"`
-	Age      int     `json:"age" binding:"required,min=13,max=120"`
-	Gender   string  `json:"gender" binding:"required,oneof=male female other"`
+	Height        float64 `json:"height" binding:"required,min=50,max=300"`
+	Weight        float64 `json:"weight" binding:"required,min=20,max=500"`
+	Age           int     `json:"age" binding:"required,min=13,max=120"`
+	Gender        string  `json:"gender" binding:"required,oneof=male female other"`
+	ActivityLevel string  `json:"activity_level" binding:"omitempty,oneof=sedentary light moderate active very_active"` // used for TDEE
 
 	// Fitness Goals
 	Goals        []string `json:"goals" binding:"required,min=1"`
@@ -58,6 +59,7 @@ type ProfileSetupRequest struct {
 	PreferredWorkoutTime    string   `json:"preferred_workout_time" binding:"required"`
 	WorkoutDays             []string `json:"workout_days" binding:"required,min=1"`
 	CommunicationPreference string   `json:"communication_preference" binding:"required,oneof=email phone sms"`
+	UnitPreference          string   `json:"unit_preference" binding:"omitempty,oneof=metric imperial"`
 }
 
 // ProfileResponse represents the profile response
@@ -103,6 +105,7 @@ func (s *ProfileService) SetupProfile(userID uint, req *ProfileSetupRequest) (*P
 			Weight:                  req.Weight,
 			Age:                     req.Age,
 			Gender:                  req.Gender,
+			ActivityLevel:           req.ActivityLevel,
 			Goals:                   string(goalsJSON),
 			TargetWeight:            req.TargetWeight,
 			Timeline:                req.Timeline,
@@ -116,6 +119,7 @@ func (s *ProfileService) SetupProfile(userID uint, req *ProfileSetupRequest) (*P
 			PreferredWorkoutTime:    req.PreferredWorkoutTime,
 			WorkoutDays:             string(workoutDaysJSON),
 			CommunicationPreference: req.CommunicationPreference,
+			UnitPreference:          req.UnitPreference,
 			IsProfileComplete:       true,
 		}
 
@@ -128,6 +132,7 @@ func (s *ProfileService) SetupProfile(userID uint, req *ProfileSetupRequest) (*P
 		userProfile.Weight = req.Weight
 		userProfile.Age = req.Age
 		userProfile.Gender = req.Gender
+		userProfile.ActivityLevel = req.ActivityLevel
 		userProfile.Goals = string(goalsJSON)
 		userProfile.TargetWeight = req.TargetWeight
 		userProfile.Timeline = req.Timeline
@@ -141,6 +146,7 @@ func (s *ProfileService) SetupProfile(userID uint, req *ProfileSetupRequest) (*P
 		userProfile.PreferredWorkoutTime = req.PreferredWorkoutTime
 		userProfile.WorkoutDays = string(workoutDaysJSON)
 		userProfile.CommunicationPreference = req.CommunicationPreference
+		userProfile.UnitPreference = req.UnitPreference
 		userProfile.IsProfileComplete = true
 
 		if err := s.db.Save(&userProfile).Error; err != nil {
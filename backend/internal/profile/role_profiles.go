@@ -34,6 +34,7 @@ type RoleProfileSetupRequest struct {
 	Weight                float64 `json:"weight,omitempty"`
 	Age                   int     `json:"age,omitempty"`
 	Gender                string  `json:"gender,omitempty"`
+	ActivityLevel         string  `json:"activity_level,omitempty" binding:"omitempty,oneof=sedentary light moderate active very_active"`
 	Goals                 string  `json:"goals,omitempty"`
 	TargetWeight          float64 `json:"target_weight,omitempty"`
 	Timeline              int     `json:"timeline,omitempty"`
@@ -47,6 +48,7 @@ type RoleProfileSetupRequest struct {
 	PreferredWorkoutTime  string  `json:"preferred_workout_time,omitempty"`
 	WorkoutDays           string  `json:"workout_days,omitempty"`
 	CommunicationPreference string `json:"communication_preference,omitempty"`
+	UnitPreference        string  `json:"unit_preference,omitempty" binding:"omitempty,oneof=metric imperial"`
 
 	// Trainer-specific fields
 	Certifications        string `json:"certifications,omitempty"`
@@ -178,6 +180,7 @@ func (s *RoleProfileService) setupMemberProfile(userID uint, req *RoleProfileSet
 	profile.Weight = req.Weight
 	profile.Age = req.Age
 	profile.Gender = req.Gender
+	profile.ActivityLevel = req.ActivityLevel
 	profile.Goals = req.Goals
 	profile.TargetWeight = req.TargetWeight
 	profile.Timeline = req.Timeline
@@ -191,6 +194,7 @@ func (s *RoleProfileService) setupMemberProfile(userID uint, req *RoleProfileSet
 	profile.PreferredWorkoutTime = req.PreferredWorkoutTime
 	profile.WorkoutDays = req.WorkoutDays
 	profile.CommunicationPreference = req.CommunicationPreference
+	profile.UnitPreference = req.UnitPreference
 
 	// Calculate completion
 	completion := s.calculateMemberProfileCompletion(&profile)
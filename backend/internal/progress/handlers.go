@@ -0,0 +1,771 @@
+package progress
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/auth"
+	"fittrackplus/internal/common/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProgressHandler handles progress log HTTP requests
+type ProgressHandler struct {
+	progressService *ProgressService
+}
+
+// NewProgressHandler creates a new progress handler
+func NewProgressHandler(cfg *config.Config) *ProgressHandler {
+	return &ProgressHandler{
+		progressService: NewProgressService(cfg),
+	}
+}
+
+// CreateProgressLog godoc
+// @Summary Log progress
+// @Description Record the current user's weight, body measurements and notes. Set logged_at to backfill an earlier date
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param progress body ProgressLogRequest true "Progress entry"
+// @Success 201 {object} ProgressLogResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /progress [post]
+func (h *ProgressHandler) CreateProgressLog(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	var req ProgressLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log, err := h.progressService.CreateProgressLog(userID, &req)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to log progress",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, log)
+}
+
+// GetProgressHistory godoc
+// @Summary Get progress history
+// @Description Get a page of progress entries, most recent first. Members see their own; trainers can pass user_id for the members they assigned plans to
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param from query string false "Entries logged on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Entries logged on or before this date (YYYY-MM-DD)"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Entries per page (default 20, max 100)"
+// @Success 200 {object} ProgressHistoryResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /progress [get]
+func (h *ProgressHandler) GetProgressHistory(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+
+	filter := ProgressFilter{}
+	if filter.From, filter.To, ok = dateRangeParams(c); !ok {
+		return
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page"))
+	filter.PageSize, _ = strconv.Atoi(c.Query("page_size"))
+
+	history, err := h.progressService.GetProgressHistory(memberID, userID, userRole, filter)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to get progress history",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetProgressSummary godoc
+// @Summary Get progress summary
+// @Description Compare the first and latest logged weight, body fat and muscle mass against the profile goals. Empty (has_data false) until a weight is logged. Trainers can pass user_id for the members they assigned plans to
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Success 200 {object} Summary
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /progress/summary [get]
+func (h *ProgressHandler) GetProgressSummary(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+
+	summary, err := h.progressService.GetSummary(memberID, userID, userRole)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to get progress summary",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetWeightTrend godoc
+// @Summary Get weight trend
+// @Description Smooth the logged weights with an exponential moving average, fit the weekly rate of change and forecast when the target weight is reached. The forecast is compared with the profile timeline (status on_track, ahead, behind, reached, no_goal or insufficient_data). Trainers can pass user_id for the members they assigned plans to
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param days query int false "Days of trend points to return (default 90, max 730)"
+// @Success 200 {object} Trend
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /progress/trend [get]
+func (h *ProgressHandler) GetWeightTrend(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+	days, _ := strconv.Atoi(c.Query("days"))
+
+	trend, err := h.progressService.GetTrend(memberID, userID, userRole, days)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to get weight trend",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, trend)
+}
+
+// GetMeasurementSites godoc
+// @Summary List body measurement sites
+// @Description List the body measurement vocabulary. Bilateral sites are measured per side (left, right)
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} MeasurementSite
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /progress/measurements/sites [get]
+func (h *ProgressHandler) GetMeasurementSites(c *gin.Context) {
+	c.JSON(http.StatusOK, MeasurementSites)
+}
+
+// GetMeasurementSeries godoc
+// @Summary Get a body measurement series
+// @Description Get the history of one measurement site and side, oldest first, including the profile baseline. Values are in cm or inches following the reader's unit preference unless unit is given. Trainers can pass user_id for the members they assigned plans to
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param site query string true "Measurement site, e.g. waist or arm"
+// @Param side query string false "left or right, for bilateral sites"
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param unit query string false "cm or in"
+// @Param from query string false "Measurements taken on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Measurements taken on or before this date (YYYY-MM-DD)"
+// @Success 200 {object} MeasurementSeries
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /progress/measurements [get]
+func (h *ProgressHandler) GetMeasurementSeries(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+	from, to, ok := dateRangeParams(c)
+	if !ok {
+		return
+	}
+	if c.Query("site") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "site is required",
+		})
+		return
+	}
+
+	series, err := h.progressService.GetMeasurementSeries(memberID, userID, userRole, c.Query("site"), c.Query("side"), c.Query("unit"), from, to)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to get measurement series",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// GetMeasurementReport godoc
+// @Summary Get body measurement changes
+// @Description Compare the first and latest value of every measured site and side in a period, with the change since the previous measurement. Values follow the reader's unit preference unless unit is given. Trainers can pass user_id for the members they assigned plans to
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param unit query string false "cm or in"
+// @Param from query string false "Measurements taken on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Measurements taken on or before this date (YYYY-MM-DD)"
+// @Success 200 {object} MeasurementReport
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /progress/measurements/changes [get]
+func (h *ProgressHandler) GetMeasurementReport(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+	from, to, ok := dateRangeParams(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.progressService.GetMeasurementReport(memberID, userID, userRole, c.Query("unit"), from, to)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to get measurement changes",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetProgressLog godoc
+// @Summary Get a progress entry
+// @Description Get a single progress entry of the current user or one of their clients
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Progress entry ID"
+// @Success 200 {object} ProgressLogResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Failure 404 {object} map[string]interface{} "Progress entry not found"
+// @Router /progress/{id} [get]
+func (h *ProgressHandler) GetProgressLog(c *gin.Context) {
+	logID, userID, ok := progressLogParams(c)
+	if !ok {
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	log, err := h.progressService.GetProgressLog(logID, userID, userRole)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to get progress entry",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, log)
+}
+
+// UpdateProgressLog godoc
+// @Summary Edit a progress entry
+// @Description Replace the contents of one of the current user's progress entries. The entry keeps its date unless logged_at is given
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Progress entry ID"
+// @Param progress body ProgressLogRequest true "Progress entry"
+// @Success 200 {object} ProgressLogResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Progress entry not found"
+// @Router /progress/{id} [put]
+func (h *ProgressHandler) UpdateProgressLog(c *gin.Context) {
+	logID, userID, ok := progressLogParams(c)
+	if !ok {
+		return
+	}
+
+	var req ProgressLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	log, err := h.progressService.UpdateProgressLog(logID, userID, &req)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to update progress entry",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, log)
+}
+
+// DeleteProgressLog godoc
+// @Summary Delete a progress entry
+// @Description Delete one of the current user's progress entries
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Progress entry ID"
+// @Success 200 {object} map[string]interface{} "Progress entry deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Progress entry not found"
+// @Router /progress/{id} [delete]
+func (h *ProgressHandler) DeleteProgressLog(c *gin.Context) {
+	logID, userID, ok := progressLogParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.progressService.DeleteProgressLog(logID, userID); err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to delete progress entry",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Progress entry deleted successfully",
+	})
+}
+
+// UploadPhoto godoc
+// @Summary Upload a progress photo
+// @Description Upload a front, side or back progress photo of the current user. Photos are private unless share_with_trainer is set
+// @Tags Progress
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Photo (JPEG, PNG or WebP)"
+// @Param pose formData string true "front, side or back"
+// @Param taken_on formData string false "Date the photo was taken (YYYY-MM-DD, defaults to today)"
+// @Param notes formData string false "Notes"
+// @Param share_with_trainer formData bool false "Share the photo with the assigned trainer"
+// @Success 201 {object} PhotoResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /progress/photos [post]
+func (h *ProgressHandler) UploadPhoto(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	var req PhotoUploadRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "No file uploaded",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	photo, err := h.progressService.UploadPhoto(userID, file, &req)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to upload photo",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, photo)
+}
+
+// GetPhotoTimeline godoc
+// @Summary Get the progress photo timeline
+// @Description Get progress photos grouped by date, most recent first. Trainers can pass user_id to see the photos their clients shared with them
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param pose query string false "front, side or back"
+// @Param from query string false "Photos taken on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Photos taken on or before this date (YYYY-MM-DD)"
+// @Success 200 {object} PhotoTimeline
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /progress/photos [get]
+func (h *ProgressHandler) GetPhotoTimeline(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+	from, to, ok := dateRangeParams(c)
+	if !ok {
+		return
+	}
+
+	timeline, err := h.progressService.GetPhotoTimeline(memberID, userID, userRole, c.Query("pose"), from, to)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to get photos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// ComparePhotos godoc
+// @Summary Compare progress photos
+// @Description Pair front, side and back photos side by side, picking per pose the photos taken closest to the two dates. Trainers can pass user_id to compare the photos their clients shared with them
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string true "First date (YYYY-MM-DD)"
+// @Param to query string true "Second date (YYYY-MM-DD)"
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Success 200 {object} PhotoComparison
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /progress/photos/compare [get]
+func (h *ProgressHandler) ComparePhotos(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID, ok := memberParam(c, userID)
+	if !ok {
+		return
+	}
+	from, errFrom := time.Parse("2006-01-02", c.Query("from"))
+	to, errTo := time.Parse("2006-01-02", c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from and to are required, expected YYYY-MM-DD",
+		})
+		return
+	}
+
+	comparison, err := h.progressService.ComparePhotos(memberID, userID, userRole, from, to)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to compare photos",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// GetPhotoImage godoc
+// @Summary Get a progress photo image
+// @Description Serve the image of a photo to its owner, or to the assigned trainer when it was shared with them
+// @Tags Progress
+// @Produce image/jpeg,image/png,image/webp
+// @Security BearerAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {file} binary
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Photo not found"
+// @Router /progress/photos/{id}/image [get]
+func (h *ProgressHandler) GetPhotoImage(c *gin.Context) {
+	photoID, userID, ok := photoParams(c)
+	if !ok {
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	path, contentType, err := h.progressService.GetPhotoFile(photoID, userID, userRole)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to get photo",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Type", contentType)
+	c.File(path)
+}
+
+// SetPhotoSharing godoc
+// @Summary Share a progress photo with the trainer
+// @Description Grant or withdraw the assigned trainer's access to one of the current user's photos
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Photo ID"
+// @Param sharing body PhotoSharingRequest true "Sharing consent"
+// @Success 200 {object} PhotoResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Photo not found"
+// @Router /progress/photos/{id}/sharing [put]
+func (h *ProgressHandler) SetPhotoSharing(c *gin.Context) {
+	photoID, userID, ok := photoParams(c)
+	if !ok {
+		return
+	}
+
+	var req PhotoSharingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	photo, err := h.progressService.SetPhotoSharing(photoID, userID, req.SharedWithTrainer)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to update photo sharing",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, photo)
+}
+
+// DeletePhoto godoc
+// @Summary Delete a progress photo
+// @Description Delete one of the current user's photos and its image
+// @Tags Progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Photo ID"
+// @Success 200 {object} map[string]interface{} "Photo deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Photo not found"
+// @Router /progress/photos/{id} [delete]
+func (h *ProgressHandler) DeletePhoto(c *gin.Context) {
+	photoID, userID, ok := photoParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.progressService.DeletePhoto(photoID, userID); err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{
+			"error":   "Failed to delete photo",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Photo deleted successfully",
+	})
+}
+
+// photoParams reads the caller and the photo ID, writing an error response when either is missing
+func photoParams(c *gin.Context) (photoID, userID uint, ok bool) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid photo ID",
+		})
+		return 0, 0, false
+	}
+
+	return uint(id), userID, true
+}
+
+// progressLogParams reads the caller and the entry ID, writing an error response when either is missing
+func progressLogParams(c *gin.Context) (logID, userID uint, ok bool) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid progress entry ID",
+		})
+		return 0, 0, false
+	}
+
+	return uint(id), userID, true
+}
+
+// memberParam reads the optional user_id query parameter, defaulting to the caller
+func memberParam(c *gin.Context, userID uint) (uint, bool) {
+	value := c.Query("user_id")
+	if value == "" {
+		return userID, true
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// dateRangeParams reads the optional from and to query dates; to includes the whole day
+func dateRangeParams(c *gin.Context) (from, to *time.Time, ok bool) {
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from date, expected YYYY-MM-DD",
+			})
+			return nil, nil, false
+		}
+		from = &date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid to date, expected YYYY-MM-DD",
+			})
+			return nil, nil, false
+		}
+		date = date.AddDate(0, 0, 1)
+		to = &date
+	}
+	return from, to, true
+}
+
+func progressErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "you can only"):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
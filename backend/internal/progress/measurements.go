@@ -0,0 +1,483 @@
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+)
+
+// MeasurementSite is an entry of the body measurement vocabulary
+type MeasurementSite struct {
+	Name      string `json:"name"`
+	Label     string `json:"label"`
+	Bilateral bool   `json:"bilateral"` // measured separately on the left and right side
+}
+
+// MeasurementSites is the fixed body measurement vocabulary, from head to toe
+var MeasurementSites = []MeasurementSite{
+	{Name: "neck", Label: "Neck"},
+	{Name: "shoulders", Label: "Shoulders"},
+	{Name: "chest", Label: "Chest"},
+	{Name: "waist", Label: "Waist"},
+	{Name: "abdomen", Label: "Abdomen"},
+	{Name: "hips", Label: "Hips"},
+	{Name: "arm", Label: "Upper arm", Bilateral: true},
+	{Name: "forearm", Label: "Forearm", Bilateral: true},
+	{Name: "thigh", Label: "Thigh", Bilateral: true},
+	{Name: "calf", Label: "Calf", Bilateral: true},
+}
+
+// Measurement units
+const (
+	UnitCm   = "cm"
+	UnitInch = "in"
+)
+
+const (
+	cmPerInch        = 2.54
+	minMeasurementCm = 5
+	maxMeasurementCm = 300
+)
+
+// BodyMeasurementInput is a typed measurement in a progress entry request
+type BodyMeasurementInput struct {
+	Site  string  `json:"site" binding:"required"` // see MeasurementSites
+	Side  string  `json:"side"`                    // left or right, for limbs only
+	Value float64 `json:"value" binding:"required,gt=0"`
+	Unit  string  `json:"unit"` // cm or in, defaults to the user's unit preference
+}
+
+// BodyMeasurementValue is a typed measurement in the reader's unit
+type BodyMeasurementValue struct {
+	Site  string  `json:"site"`
+	Side  string  `json:"side,omitempty"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// MeasurementPoint is one value of a measurement time series
+type MeasurementPoint struct {
+	Date          time.Time `json:"date"`
+	Value         float64   `json:"value"`
+	ProgressLogID uint      `json:"progress_log_id,omitempty"` // empty for the profile baseline
+}
+
+// MeasurementSeries is the history of one site and side
+type MeasurementSeries struct {
+	UserID uint               `json:"user_id"`
+	Site   string             `json:"site"`
+	Side   string             `json:"side,omitempty"`
+	Unit   string             `json:"unit"`
+	Points []MeasurementPoint `json:"points"`
+}
+
+// MeasurementChange reports how one site and side changed over a period
+type MeasurementChange struct {
+	Site            string    `json:"site"`
+	Side            string    `json:"side,omitempty"`
+	Label           string    `json:"label"`
+	First           float64   `json:"first"`
+	Latest          float64   `json:"latest"`
+	Change          float64   `json:"change"` // latest minus first
+	ChangePercent   float64   `json:"change_percent"`
+	SinceLast       float64   `json:"since_last"` // latest minus the measurement before it
+	Count           int       `json:"count"`
+	FirstMeasuredAt time.Time `json:"first_measured_at"`
+	LastMeasuredAt  time.Time `json:"last_measured_at"`
+}
+
+// MeasurementReport lists the change of every measured site over a period
+type MeasurementReport struct {
+	UserID uint                `json:"user_id"`
+	Unit   string              `json:"unit"`
+	Sites  []MeasurementChange `json:"sites"`
+}
+
+// ParseMeasurementKey maps a free-form key such as "waist", "left_arm" or "thigh right"
+// onto the vocabulary
+func ParseMeasurementKey(key string) (site, side string, ok bool) {
+	key = strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(key)))
+	for _, prefix := range []string{"left", "right"} {
+		if name, found := strings.CutPrefix(key, prefix+"_"); found {
+			key, side = name, prefix
+		} else if name, found := strings.CutSuffix(key, "_"+prefix); found {
+			key, side = name, prefix
+		}
+	}
+
+	definition, found := findSite(key)
+	if !found || (side != "" && !definition.Bilateral) {
+		return "", "", false
+	}
+	return definition.Name, side, true
+}
+
+// ConvertMeasurement converts a value in cm to the given unit, rounded to a tenth
+func ConvertMeasurement(valueCm float64, unit string) float64 {
+	if unit == UnitInch {
+		valueCm /= cmPerInch
+	}
+	return utils.RoundTenth(valueCm)
+}
+
+// GetMeasurementSeries returns the history of one site and side, oldest first
+func (s *ProgressService) GetMeasurementSeries(memberID, viewerID uint, viewerRole, site, side, unit string, from, to *time.Time) (*MeasurementSeries, error) {
+	if err := s.canViewProgress(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	definition, ok := findSite(site)
+	if !ok {
+		return nil, fmt.Errorf("unknown measurement site %q", site)
+	}
+	if side != "" && (!definition.Bilateral || (side != "left" && side != "right")) {
+		return nil, fmt.Errorf("invalid side %q for %s", side, site)
+	}
+	unit, err := s.resolveUnit(viewerID, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.loadMeasurements(memberID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &MeasurementSeries{UserID: memberID, Site: site, Side: side, Unit: unit, Points: []MeasurementPoint{}}
+	for _, measurement := range measurements {
+		if measurement.Site != site || measurement.Side != side {
+			continue
+		}
+		series.Points = append(series.Points, MeasurementPoint{
+			Date:          measurement.MeasuredAt,
+			Value:         ConvertMeasurement(measurement.ValueCm, unit),
+			ProgressLogID: measurement.ProgressLogID,
+		})
+	}
+	return series, nil
+}
+
+// GetMeasurementReport compares the first and latest value of every measured site in a period
+func (s *ProgressService) GetMeasurementReport(memberID, viewerID uint, viewerRole, unit string, from, to *time.Time) (*MeasurementReport, error) {
+	if err := s.canViewProgress(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	unit, err := s.resolveUnit(viewerID, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.loadMeasurements(memberID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := buildMeasurementReport(measurements, unit)
+	report.UserID = memberID
+	return report, nil
+}
+
+// LatestMeasurement returns a member's most recent value (cm) for a site and side
+func (s *ProgressService) LatestMeasurement(memberID uint, site, side string) (float64, bool, error) {
+	measurements, err := s.loadMeasurements(memberID, nil, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	for i := len(measurements) - 1; i >= 0; i-- {
+		if measurements[i].Site == site && measurements[i].Side == side {
+			return measurements[i].ValueCm, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// buildMeasurementReport groups chronological measurements per site and side
+func buildMeasurementReport(measurements []models.BodyMeasurement, unit string) *MeasurementReport {
+	report := &MeasurementReport{Unit: unit, Sites: []MeasurementChange{}}
+
+	index := map[string]int{}
+	previous := map[string]float64{}
+	for _, measurement := range measurements {
+		key := measurement.Site + "/" + measurement.Side
+		value := ConvertMeasurement(measurement.ValueCm, unit)
+
+		i, seen := index[key]
+		if !seen {
+			definition, _ := findSite(measurement.Site)
+			index[key] = len(report.Sites)
+			report.Sites = append(report.Sites, MeasurementChange{
+				Site:            measurement.Site,
+				Side:            measurement.Side,
+				Label:           definition.Label,
+				First:           value,
+				FirstMeasuredAt: measurement.MeasuredAt,
+			})
+			i = index[key]
+		} else {
+			previous[key] = report.Sites[i].Latest
+		}
+
+		change := &report.Sites[i]
+		change.Latest = value
+		change.LastMeasuredAt = measurement.MeasuredAt
+		change.Count++
+	}
+
+	for i := range report.Sites {
+		change := &report.Sites[i]
+		change.Change = utils.RoundTenth(change.Latest - change.First)
+		if change.First > 0 {
+			change.ChangePercent = utils.RoundTenth(change.Change / change.First * 100)
+		}
+		if last, ok := previous[change.Site+"/"+change.Side]; ok {
+			change.SinceLast = utils.RoundTenth(change.Latest - last)
+		}
+	}
+	sortMeasurements(report.Sites, func(i int) (string, string) { return report.Sites[i].Site, report.Sites[i].Side })
+	return report
+}
+
+// loadMeasurements returns a member's measurements in a period, oldest first
+// The profile baseline is included, as are untyped circumferences of older entries
+func (s *ProgressService) loadMeasurements(memberID uint, from, to *time.Time) ([]models.BodyMeasurement, error) {
+	query := s.db.Where("user_id = ?", memberID)
+	if from != nil {
+		query = query.Where("logged_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("logged_at < ?", *to)
+	}
+	var logs []models.ProgressLog
+	if err := query.Order("logged_at, id").Find(&logs).Error; err != nil {
+		return nil, errors.New("failed to load progress entries")
+	}
+
+	measurements := []models.BodyMeasurement{}
+	var profile models.UserProfile
+	if s.db.Where("user_id = ?", memberID).First(&profile).Error == nil {
+		inRange := (from == nil || !profile.CreatedAt.Before(*from)) && (to == nil || profile.CreatedAt.Before(*to))
+		if inRange {
+			measurements = append(measurements, profileMeasurements(&profile)...)
+		}
+	}
+
+	byLog, err := s.measurementsByLog(logs)
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		measurements = append(measurements, byLog[log.ID]...)
+	}
+	return measurements, nil
+}
+
+// measurementsByLog loads the typed measurements of entries, falling back to the
+// untyped measurements of entries recorded before measurements were typed
+func (s *ProgressService) measurementsByLog(logs []models.ProgressLog) (map[uint][]models.BodyMeasurement, error) {
+	byLog := map[uint][]models.BodyMeasurement{}
+	if len(logs) == 0 {
+		return byLog, nil
+	}
+
+	ids := make([]uint, len(logs))
+	for i, log := range logs {
+		ids[i] = log.ID
+	}
+	var rows []models.BodyMeasurement
+	if err := s.db.Where("progress_log_id IN ?", ids).Order("id").Find(&rows).Error; err != nil {
+		return nil, errors.New("failed to load body measurements")
+	}
+	for _, row := range rows {
+		byLog[row.ProgressLogID] = append(byLog[row.ProgressLogID], row)
+	}
+
+	for i := range logs {
+		if _, typed := byLog[logs[i].ID]; !typed {
+			if legacy := legacyMeasurements(&logs[i]); len(legacy) > 0 {
+				byLog[logs[i].ID] = legacy
+			}
+		}
+	}
+	return byLog, nil
+}
+
+// resolveUnit returns the requested unit, or the reader's preference when none is given
+func (s *ProgressService) resolveUnit(userID uint, unit string) (string, error) {
+	switch unit {
+	case UnitCm, UnitInch:
+		return unit, nil
+	case "":
+		return s.unitPreference(userID), nil
+	default:
+		return "", fmt.Errorf("invalid unit %q, expected cm or in", unit)
+	}
+}
+
+// unitPreference returns the measurement unit of a user's profile
+func (s *ProgressService) unitPreference(userID uint) string {
+	var profile models.UserProfile
+	s.db.Select("unit_preference").Where("user_id = ?", userID).First(&profile)
+	if profile.UnitPreference == "imperial" {
+		return UnitInch
+	}
+	return UnitCm
+}
+
+// typedMeasurements validates the measurements of a request. Keys of the untyped map
+// that name a vocabulary site are typed as well (in cm); the other keys are returned as is
+func typedMeasurements(req *ProgressLogRequest, unit string) ([]models.BodyMeasurement, map[string]float64, error) {
+	typed := []models.BodyMeasurement{}
+	other := map[string]float64{}
+	seen := map[string]bool{}
+
+	add := func(input BodyMeasurementInput) error {
+		measurement, err := normalizeMeasurement(input, unit)
+		if err != nil {
+			return err
+		}
+		key := measurement.Site + "/" + measurement.Side
+		if seen[key] {
+			return fmt.Errorf("%s is measured more than once", strings.Trim(measurement.Side+" "+measurement.Site, " "))
+		}
+		seen[key] = true
+		typed = append(typed, measurement)
+		return nil
+	}
+
+	for _, input := range req.BodyMeasurements {
+		if err := add(input); err != nil {
+			return nil, nil, err
+		}
+	}
+	for key, value := range req.Measurements {
+		if strings.TrimSpace(key) == "" || value <= 0 || value > 500 {
+			return nil, nil, fmt.Errorf("invalid measurement %q: values must be between 0 and 500", key)
+		}
+		if site, side, ok := ParseMeasurementKey(key); ok {
+			if err := add(BodyMeasurementInput{Site: site, Side: side, Value: value, Unit: UnitCm}); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		other[key] = value
+	}
+
+	sortMeasurements(typed, func(i int) (string, string) { return typed[i].Site, typed[i].Side })
+	return typed, other, nil
+}
+
+// normalizeMeasurement validates a typed measurement and converts it to cm
+func normalizeMeasurement(input BodyMeasurementInput, defaultUnit string) (models.BodyMeasurement, error) {
+	site := strings.ToLower(strings.TrimSpace(input.Site))
+	side := strings.ToLower(strings.TrimSpace(input.Side))
+	definition, ok := findSite(site)
+	if !ok {
+		return models.BodyMeasurement{}, fmt.Errorf("unknown measurement site %q", input.Site)
+	}
+	if side != "" && (!definition.Bilateral || (side != "left" && side != "right")) {
+		return models.BodyMeasurement{}, fmt.Errorf("invalid side %q for %s", input.Side, site)
+	}
+
+	unit := input.Unit
+	if unit == "" {
+		unit = defaultUnit
+	}
+	value := input.Value
+	switch unit {
+	case UnitCm:
+	case UnitInch:
+		value *= cmPerInch
+	default:
+		return models.BodyMeasurement{}, fmt.Errorf("invalid unit %q, expected cm or in", input.Unit)
+	}
+	if value < minMeasurementCm || value > maxMeasurementCm {
+		return models.BodyMeasurement{}, fmt.Errorf("%s must be between %d and %d cm", site, minMeasurementCm, maxMeasurementCm)
+	}
+
+	return models.BodyMeasurement{Site: site, Side: side, ValueCm: utils.RoundTenth(value)}, nil
+}
+
+// legacyMeasurements types the circumferences of an entry's untyped measurement map
+func legacyMeasurements(log *models.ProgressLog) []models.BodyMeasurement {
+	return parseMeasurementMap(log.Measurements, log.UserID, log.ID, log.LoggedAt)
+}
+
+// profileMeasurements types the baseline measurements of a profile
+func profileMeasurements(profile *models.UserProfile) []models.BodyMeasurement {
+	return parseMeasurementMap(profile.BodyMeasurements, profile.UserID, 0, profile.CreatedAt)
+}
+
+func parseMeasurementMap(encoded string, userID, logID uint, measuredAt time.Time) []models.BodyMeasurement {
+	if encoded == "" {
+		return nil
+	}
+	var values map[string]float64
+	json.Unmarshal([]byte(encoded), &values)
+
+	measurements := []models.BodyMeasurement{}
+	for key, value := range values {
+		site, side, ok := ParseMeasurementKey(key)
+		if !ok || value < minMeasurementCm || value > maxMeasurementCm {
+			continue
+		}
+		measurements = append(measurements, models.BodyMeasurement{
+			UserID:        userID,
+			ProgressLogID: logID,
+			Site:          site,
+			Side:          side,
+			ValueCm:       value,
+			MeasuredAt:    measuredAt,
+		})
+	}
+	sortMeasurements(measurements, func(i int) (string, string) { return measurements[i].Site, measurements[i].Side })
+	return measurements
+}
+
+// measurementValues converts measurements to the reader's unit
+func measurementValues(measurements []models.BodyMeasurement, unit string) []BodyMeasurementValue {
+	values := []BodyMeasurementValue{}
+	for _, measurement := range measurements {
+		values = append(values, BodyMeasurementValue{
+			Site:  measurement.Site,
+			Side:  measurement.Side,
+			Value: ConvertMeasurement(measurement.ValueCm, unit),
+			Unit:  unit,
+		})
+	}
+	return values
+}
+
+// sortMeasurements orders a slice by vocabulary position, then left before right
+func sortMeasurements(slice interface{}, key func(i int) (site, side string)) {
+	position := func(i int) (int, string) {
+		site, side := key(i)
+		for p, definition := range MeasurementSites {
+			if definition.Name == site {
+				return p, side
+			}
+		}
+		return len(MeasurementSites), side
+	}
+	sort.SliceStable(slice, func(i, j int) bool {
+		pi, si := position(i)
+		pj, sj := position(j)
+		if pi != pj {
+			return pi < pj
+		}
+		return si < sj
+	})
+}
+
+func findSite(name string) (MeasurementSite, bool) {
+	for _, definition := range MeasurementSites {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return MeasurementSite{}, false
+}
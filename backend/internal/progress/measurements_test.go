@@ -0,0 +1,80 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestParseMeasurementKey(t *testing.T) {
+	cases := []struct {
+		key, site, side string
+		ok              bool
+	}{
+		{"Waist", "waist", "", true},
+		{"left_arm", "arm", "left", true},
+		{"thigh right", "thigh", "right", true},
+		{"calf-left", "calf", "left", true},
+		{"arm", "arm", "", true},
+		{"left_waist", "", "", false},
+		{"body_fat", "", "", false},
+	}
+	for _, tc := range cases {
+		site, side, ok := ParseMeasurementKey(tc.key)
+		if site != tc.site || side != tc.side || ok != tc.ok {
+			t.Errorf("%q: expected %q/%q/%v, got %q/%q/%v", tc.key, tc.site, tc.side, tc.ok, site, side, ok)
+		}
+	}
+}
+
+func TestTypedMeasurementsRejectsInvalid(t *testing.T) {
+	invalid := []BodyMeasurementInput{
+		{Site: "elbow", Value: 30},
+		{Site: "waist", Side: "left", Value: 80},
+		{Site: "arm", Side: "middle", Value: 30},
+		{Site: "waist", Value: 2},
+		{Site: "waist", Value: 80, Unit: "ft"},
+	}
+	for _, input := range invalid {
+		req := &ProgressLogRequest{BodyMeasurements: []BodyMeasurementInput{input}}
+		if _, _, err := typedMeasurements(req, UnitCm); err == nil {
+			t.Errorf("expected %+v to be rejected", input)
+		}
+	}
+
+	duplicate := &ProgressLogRequest{
+		BodyMeasurements: []BodyMeasurementInput{{Site: "waist", Value: 80}},
+		Measurements:     map[string]float64{"waist": 81},
+	}
+	if _, _, err := typedMeasurements(duplicate, UnitCm); err == nil {
+		t.Error("expected a site measured twice to be rejected")
+	}
+}
+
+func TestBuildMeasurementReport(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 8, 0, 0, 0, time.UTC) }
+	report := buildMeasurementReport([]models.BodyMeasurement{
+		{Site: "thigh", Side: "right", ValueCm: 60, MeasuredAt: day(1)},
+		{Site: "waist", ValueCm: 90, MeasuredAt: day(1)},
+		{Site: "waist", ValueCm: 88, MeasuredAt: day(8)},
+		{Site: "waist", ValueCm: 85.5, MeasuredAt: day(15)},
+		{Site: "thigh", Side: "left", ValueCm: 59, MeasuredAt: day(15)},
+	}, UnitCm)
+
+	if len(report.Sites) != 3 || report.Sites[0].Site != "waist" || report.Sites[1].Side != "left" || report.Sites[2].Side != "right" {
+		t.Fatalf("expected sites in vocabulary order, got %+v", report.Sites)
+	}
+	waist := report.Sites[0]
+	if waist.First != 90 || waist.Latest != 85.5 || waist.Change != -4.5 || waist.ChangePercent != -5 || waist.SinceLast != -2.5 || waist.Count != 3 {
+		t.Errorf("unexpected waist change %+v", waist)
+	}
+	if thigh := report.Sites[1]; thigh.Change != 0 || thigh.SinceLast != 0 || thigh.Count != 1 {
+		t.Errorf("expected a single measurement to show no change, got %+v", thigh)
+	}
+
+	inches := buildMeasurementReport([]models.BodyMeasurement{{Site: "waist", ValueCm: 81.28}}, UnitInch)
+	if inches.Sites[0].Latest != 32 || inches.Unit != UnitInch {
+		t.Errorf("expected the report in inches, got %+v", inches)
+	}
+}
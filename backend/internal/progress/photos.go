@@ -0,0 +1,363 @@
+package progress
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+	"fittrackplus/internal/plan"
+)
+
+// PhotoPoses are the supported progress photo poses, in display order
+var PhotoPoses = []string{"front", "side", "back"}
+
+// photoTypes maps the accepted image types to their file extension
+var photoTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// PhotoUploadRequest holds the form fields sent with a progress photo
+type PhotoUploadRequest struct {
+	Pose    string `form:"pose" binding:"required,oneof=front side back"`
+	TakenOn string `form:"taken_on"` // YYYY-MM-DD, defaults to today
+	Notes   string `form:"notes" binding:"max=500"`
+	Share   bool   `form:"share_with_trainer"` // consent to show the photo to the assigned trainer
+}
+
+// PhotoSharingRequest changes whether a photo is shared with the trainer
+type PhotoSharingRequest struct {
+	SharedWithTrainer bool `json:"shared_with_trainer"`
+}
+
+// PhotoResponse represents a progress photo
+type PhotoResponse struct {
+	ID                uint      `json:"id"`
+	UserID            uint      `json:"user_id"`
+	Pose              string    `json:"pose"`
+	TakenOn           time.Time `json:"taken_on"`
+	ImageURL          string    `json:"image_url"` // authenticated endpoint serving the image
+	ContentType       string    `json:"content_type"`
+	Notes             string    `json:"notes,omitempty"`
+	SharedWithTrainer bool      `json:"shared_with_trainer"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// PhotoDay groups the photos taken on one date
+type PhotoDay struct {
+	Date   time.Time       `json:"date"`
+	Photos []PhotoResponse `json:"photos"`
+}
+
+// PhotoTimeline is a member's photos grouped by date, most recent first
+type PhotoTimeline struct {
+	UserID uint       `json:"user_id"`
+	Days   []PhotoDay `json:"days"`
+}
+
+// PosePair pairs the photos of one pose closest to the two compared dates
+type PosePair struct {
+	Pose      string         `json:"pose"`
+	Before    *PhotoResponse `json:"before"`
+	After     *PhotoResponse `json:"after"`
+	DaysApart int            `json:"days_apart"`
+}
+
+// PhotoComparison lines up photos by pose across two dates
+type PhotoComparison struct {
+	UserID uint       `json:"user_id"`
+	From   time.Time  `json:"from"`
+	To     time.Time  `json:"to"`
+	Pairs  []PosePair `json:"pairs"`
+}
+
+// UploadPhoto stores a progress photo for a member
+func (s *ProgressService) UploadPhoto(userID uint, file *multipart.FileHeader, req *PhotoUploadRequest) (*PhotoResponse, error) {
+	// Photos are dated by UTC calendar day, like the dates members pass in
+	takenOn := utils.Today()
+	if req.TakenOn != "" {
+		date, err := time.Parse("2006-01-02", req.TakenOn)
+		if err != nil {
+			return nil, errors.New("invalid taken_on date, expected YYYY-MM-DD")
+		}
+		if date.After(takenOn) {
+			return nil, errors.New("photos cannot be dated in the future")
+		}
+		takenOn = utils.DateOnly(date)
+	}
+	if file.Size > s.cfg.MaxUploadSize {
+		return nil, fmt.Errorf("photo is too large (max %d MB)", s.cfg.MaxUploadSize/(1024*1024))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	// Trust the bytes rather than the declared content type
+	header := make([]byte, 512)
+	n, err := io.ReadFull(src, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, errors.New("could not read the uploaded photo")
+	}
+	contentType := http.DetectContentType(header[:n])
+	ext, ok := photoTypes[contentType]
+	if !ok {
+		return nil, errors.New("invalid photo type (only JPEG, PNG and WebP allowed)")
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+	relative := filepath.Join("progress_photos", fmt.Sprint(userID), name+ext)
+	if err := writeFile(filepath.Join(s.cfg.UploadPath, relative), src); err != nil {
+		return nil, err
+	}
+
+	photo := models.ProgressPhoto{
+		UserID:            userID,
+		Pose:              req.Pose,
+		TakenOn:           takenOn,
+		FilePath:          relative,
+		ContentType:       contentType,
+		SizeBytes:         file.Size,
+		Notes:             strings.TrimSpace(req.Notes),
+		SharedWithTrainer: req.Share,
+	}
+	if err := s.db.Create(&photo).Error; err != nil {
+		os.Remove(filepath.Join(s.cfg.UploadPath, relative))
+		return nil, err
+	}
+
+	return buildPhotoResponse(&photo), nil
+}
+
+// GetPhotoTimeline returns the member's photos the viewer may see, grouped by date
+// Trainers only see the photos their clients shared with them
+func (s *ProgressService) GetPhotoTimeline(memberID, viewerID uint, viewerRole, pose string, from, to *time.Time) (*PhotoTimeline, error) {
+	photos, err := s.visiblePhotos(memberID, viewerID, viewerRole, pose, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &PhotoTimeline{UserID: memberID, Days: []PhotoDay{}}
+	for i := len(photos) - 1; i >= 0; i-- {
+		date := utils.DateOnly(photos[i].TakenOn)
+		if n := len(timeline.Days); n == 0 || !timeline.Days[n-1].Date.Equal(date) {
+			timeline.Days = append(timeline.Days, PhotoDay{Date: date, Photos: []PhotoResponse{}})
+		}
+		day := &timeline.Days[len(timeline.Days)-1]
+		day.Photos = append(day.Photos, *buildPhotoResponse(&photos[i]))
+	}
+	for i := range timeline.Days {
+		photos := timeline.Days[i].Photos
+		sort.SliceStable(photos, func(a, b int) bool { return posePosition(photos[a].Pose) < posePosition(photos[b].Pose) })
+	}
+	return timeline, nil
+}
+
+// ComparePhotos pairs, for every pose, the photos taken closest to two dates
+func (s *ProgressService) ComparePhotos(memberID, viewerID uint, viewerRole string, from, to time.Time) (*PhotoComparison, error) {
+	if !from.Before(to) {
+		return nil, errors.New("the first date must be before the second")
+	}
+	photos, err := s.visiblePhotos(memberID, viewerID, viewerRole, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PhotoComparison{
+		UserID: memberID,
+		From:   from,
+		To:     to,
+		Pairs:  pairPhotos(photos, from, to),
+	}, nil
+}
+
+// GetPhotoFile returns the location and type of a photo's image for a viewer allowed to see it
+func (s *ProgressService) GetPhotoFile(photoID, viewerID uint, viewerRole string) (path, contentType string, err error) {
+	var photo models.ProgressPhoto
+	if err := s.db.First(&photo, photoID).Error; err != nil {
+		return "", "", errors.New("photo not found")
+	}
+	if !s.canViewPhoto(&photo, viewerID, viewerRole) {
+		// Do not reveal that a private photo exists
+		return "", "", errors.New("photo not found")
+	}
+	return filepath.Join(s.cfg.UploadPath, photo.FilePath), photo.ContentType, nil
+}
+
+// SetPhotoSharing grants or withdraws the trainer's access to one of the member's photos
+func (s *ProgressService) SetPhotoSharing(photoID, userID uint, shared bool) (*PhotoResponse, error) {
+	photo, err := s.findOwnPhoto(photoID, userID)
+	if err != nil {
+		return nil, err
+	}
+	photo.SharedWithTrainer = shared
+	if err := s.db.Model(photo).Update("shared_with_trainer", shared).Error; err != nil {
+		return nil, err
+	}
+	return buildPhotoResponse(photo), nil
+}
+
+// DeletePhoto deletes one of the member's photos and its image
+func (s *ProgressService) DeletePhoto(photoID, userID uint) error {
+	photo, err := s.findOwnPhoto(photoID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(photo).Error; err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.cfg.UploadPath, photo.FilePath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// visiblePhotos loads the member's photos the viewer may see, oldest first
+func (s *ProgressService) visiblePhotos(memberID, viewerID uint, viewerRole, pose string, from, to *time.Time) ([]models.ProgressPhoto, error) {
+	query := s.db.Where("user_id = ?", memberID)
+	switch {
+	case memberID == viewerID:
+	case viewerRole == "trainer" && plan.IsAssignedTrainer(s.db, viewerID, memberID):
+		query = query.Where("shared_with_trainer = ?", true)
+	default:
+		return nil, errors.New("you can only view your own photos or those your clients shared with you")
+	}
+
+	if pose != "" {
+		if posePosition(pose) == len(PhotoPoses) {
+			return nil, fmt.Errorf("invalid pose %q, expected front, side or back", pose)
+		}
+		query = query.Where("pose = ?", pose)
+	}
+	if from != nil {
+		query = query.Where("taken_on >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("taken_on < ?", *to)
+	}
+
+	var photos []models.ProgressPhoto
+	if err := query.Order("taken_on, id").Find(&photos).Error; err != nil {
+		return nil, errors.New("failed to load photos")
+	}
+	return photos, nil
+}
+
+// canViewPhoto checks that the viewer owns the photo or is the trainer it was shared with
+func (s *ProgressService) canViewPhoto(photo *models.ProgressPhoto, viewerID uint, viewerRole string) bool {
+	if photo.UserID == viewerID {
+		return true
+	}
+	return viewerRole == "trainer" && photo.SharedWithTrainer && plan.IsAssignedTrainer(s.db, viewerID, photo.UserID)
+}
+
+func (s *ProgressService) findOwnPhoto(photoID, userID uint) (*models.ProgressPhoto, error) {
+	var photo models.ProgressPhoto
+	if err := s.db.Where("id = ? AND user_id = ?", photoID, userID).First(&photo).Error; err != nil {
+		return nil, errors.New("photo not found")
+	}
+	return &photo, nil
+}
+
+// pairPhotos picks, per pose, the photo closest to each date (photos oldest first)
+// A pose is only paired when two different photos are available
+func pairPhotos(photos []models.ProgressPhoto, from, to time.Time) []PosePair {
+	pairs := []PosePair{}
+	for _, pose := range PhotoPoses {
+		var before, after *models.ProgressPhoto
+		for i := range photos {
+			if photos[i].Pose != pose {
+				continue
+			}
+			if before == nil || daysBetween(photos[i].TakenOn, from) < daysBetween(before.TakenOn, from) {
+				before = &photos[i]
+			}
+			// Ties go to the later photo
+			if after == nil || daysBetween(photos[i].TakenOn, to) <= daysBetween(after.TakenOn, to) {
+				after = &photos[i]
+			}
+		}
+		if before == nil || before.ID == after.ID || !before.TakenOn.Before(after.TakenOn) {
+			continue
+		}
+		pairs = append(pairs, PosePair{
+			Pose:      pose,
+			Before:    buildPhotoResponse(before),
+			After:     buildPhotoResponse(after),
+			DaysApart: int(daysBetween(after.TakenOn, before.TakenOn)),
+		})
+	}
+	return pairs
+}
+
+func buildPhotoResponse(photo *models.ProgressPhoto) *PhotoResponse {
+	return &PhotoResponse{
+		ID:                photo.ID,
+		UserID:            photo.UserID,
+		Pose:              photo.Pose,
+		TakenOn:           photo.TakenOn,
+		ImageURL:          fmt.Sprintf("/api/v1/progress/photos/%d/image", photo.ID),
+		ContentType:       photo.ContentType,
+		Notes:             photo.Notes,
+		SharedWithTrainer: photo.SharedWithTrainer,
+		CreatedAt:         photo.CreatedAt,
+	}
+}
+
+func writeFile(path string, src io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(path)
+		return err
+	}
+	return out.Close()
+}
+
+func randomName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func posePosition(pose string) int {
+	for i, name := range PhotoPoses {
+		if name == pose {
+			return i
+		}
+	}
+	return len(PhotoPoses)
+}
+
+func daysBetween(a, b time.Time) float64 {
+	return math.Abs(utils.DateOnly(a).Sub(utils.DateOnly(b)).Hours() / 24)
+}
@@ -0,0 +1,34 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestPairPhotos(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	photos := []models.ProgressPhoto{
+		{ID: 1, Pose: "front", TakenOn: day(1, 3)},
+		{ID: 2, Pose: "side", TakenOn: day(1, 3)},
+		{ID: 3, Pose: "front", TakenOn: day(2, 1)},
+		{ID: 4, Pose: "back", TakenOn: day(3, 30)},
+		{ID: 5, Pose: "front", TakenOn: day(4, 2)},
+	}
+
+	pairs := pairPhotos(photos, day(1, 1), day(4, 1))
+	if len(pairs) != 1 {
+		t.Fatalf("expected only the front pose to have two photos, got %+v", pairs)
+	}
+	if pairs[0].Before.ID != 1 || pairs[0].After.ID != 5 || pairs[0].DaysApart != 90 {
+		t.Errorf("expected the photos closest to each date, got %+v / %+v (%d days)", pairs[0].Before, pairs[0].After, pairs[0].DaysApart)
+	}
+
+	if pairs := pairPhotos(photos, day(1, 1), day(2, 1)); pairs[0].After.ID != 3 {
+		t.Errorf("expected the February photo, got %+v", pairs[0].After)
+	}
+	if pairs := pairPhotos(photos[:2], day(1, 1), day(4, 1)); len(pairs) != 0 {
+		t.Errorf("expected a single photo per pose not to be paired, got %+v", pairs)
+	}
+}
@@ -0,0 +1,303 @@
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/plan"
+
+	"gorm.io/gorm"
+)
+
+// ProgressService handles member progress logs
+type ProgressService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewProgressService creates a new progress service
+func NewProgressService(cfg *config.Config) *ProgressService {
+	return &ProgressService{
+		db:  database.GetDB(),
+		cfg: cfg,
+	}
+}
+
+// ProgressLogRequest represents a progress entry creation/update request
+type ProgressLogRequest struct {
+	Weight           float64                `json:"weight" binding:"omitempty,min=20,max=500"`  // in kg
+	BodyMeasurements []BodyMeasurementInput `json:"body_measurements" binding:"omitempty,dive"` // typed circumferences, see GET /progress/measurements/sites
+	Measurements     map[string]float64     `json:"measurements"`                               // other values such as body_fat (%) and muscle_mass (kg); keys naming a measurement site, e.g. {"waist": 82}, are typed in cm
+	Notes            string                 `json:"notes" binding:"max=2000"`
+	LoggedAt         *time.Time             `json:"logged_at"` // backfill an earlier date; defaults to now
+}
+
+// ProgressLogResponse represents a progress entry
+type ProgressLogResponse struct {
+	ID               uint                   `json:"id"`
+	UserID           uint                   `json:"user_id"`
+	Weight           float64                `json:"weight,omitempty"`
+	BodyMeasurements []BodyMeasurementValue `json:"body_measurements,omitempty"` // in the reader's unit
+	Measurements     map[string]float64     `json:"measurements,omitempty"`
+	Notes            string                 `json:"notes,omitempty"`
+	LoggedAt         time.Time              `json:"logged_at"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+}
+
+// ProgressFilter holds the history parameters
+type ProgressFilter struct {
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	PageSize int
+}
+
+// ProgressHistoryResponse is one page of a member's progress history
+type ProgressHistoryResponse struct {
+	Logs       []ProgressLogResponse `json:"logs"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+	Total      int64                 `json:"total"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+// maxPageSize caps the history page size
+const maxPageSize = 100
+
+// CreateProgressLog records a progress entry for a member
+func (s *ProgressService) CreateProgressLog(userID uint, req *ProgressLogRequest) (*ProgressLogResponse, error) {
+	unit := s.unitPreference(userID)
+	log := models.ProgressLog{UserID: userID}
+	measurements, err := applyProgressLogRequest(&log, req, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&log).Error; err != nil {
+			return err
+		}
+		return saveMeasurements(tx, &log, measurements)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildProgressLogResponse(&log, measurements, unit), nil
+}
+
+// UpdateProgressLog replaces the contents of one of the member's entries
+func (s *ProgressService) UpdateProgressLog(logID, userID uint, req *ProgressLogRequest) (*ProgressLogResponse, error) {
+	log, err := s.findOwnLog(logID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := s.unitPreference(userID)
+	loggedAt := log.LoggedAt
+	measurements, err := applyProgressLogRequest(log, req, unit)
+	if err != nil {
+		return nil, err
+	}
+	// Editing an entry keeps its date unless a new one is given
+	if req.LoggedAt == nil {
+		log.LoggedAt = loggedAt
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(log).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("progress_log_id = ?", log.ID).Delete(&models.BodyMeasurement{}).Error; err != nil {
+			return err
+		}
+		return saveMeasurements(tx, log, measurements)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildProgressLogResponse(log, measurements, unit), nil
+}
+
+// DeleteProgressLog deletes one of the member's entries
+func (s *ProgressService) DeleteProgressLog(logID, userID uint) error {
+	log, err := s.findOwnLog(logID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("progress_log_id = ?", log.ID).Delete(&models.BodyMeasurement{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(log).Error
+	})
+}
+
+// GetProgressLog retrieves a single entry visible to the viewer
+func (s *ProgressService) GetProgressLog(logID, viewerID uint, viewerRole string) (*ProgressLogResponse, error) {
+	var log models.ProgressLog
+	if err := s.db.First(&log, logID).Error; err != nil {
+		return nil, errors.New("progress entry not found")
+	}
+	if err := s.canViewProgress(log.UserID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.measurementsByLog([]models.ProgressLog{log})
+	if err != nil {
+		return nil, err
+	}
+	return buildProgressLogResponse(&log, measurements[log.ID], s.unitPreference(viewerID)), nil
+}
+
+// GetProgressHistory returns a page of a member's entries, most recent first
+// Members see their own history, trainers that of the members they assigned plans to
+func (s *ProgressService) GetProgressHistory(memberID, viewerID uint, viewerRole string, filter ProgressFilter) (*ProgressHistoryResponse, error) {
+	if err := s.canViewProgress(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > maxPageSize {
+		filter.PageSize = maxPageSize
+	}
+
+	query := s.db.Model(&models.ProgressLog{}).Where("user_id = ?", memberID)
+	if filter.From != nil {
+		query = query.Where("logged_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("logged_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var logs []models.ProgressLog
+	err := query.Order("logged_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.PageSize).Limit(filter.PageSize).
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.measurementsByLog(logs)
+	if err != nil {
+		return nil, err
+	}
+	unit := s.unitPreference(viewerID)
+
+	response := &ProgressHistoryResponse{
+		Logs:       []ProgressLogResponse{},
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		Total:      total,
+		TotalPages: int((total + int64(filter.PageSize) - 1) / int64(filter.PageSize)),
+	}
+	for i := range logs {
+		response.Logs = append(response.Logs, *buildProgressLogResponse(&logs[i], measurements[logs[i].ID], unit))
+	}
+	return response, nil
+}
+
+// canViewProgress checks that the viewer may see a member's progress
+func (s *ProgressService) canViewProgress(memberID, viewerID uint, viewerRole string) error {
+	switch {
+	case memberID == viewerID, viewerRole == "admin":
+		return nil
+	case viewerRole == "trainer" && plan.IsAssignedTrainer(s.db, viewerID, memberID):
+		return nil
+	default:
+		return errors.New("you can only view your own progress or that of your clients")
+	}
+}
+
+func (s *ProgressService) findOwnLog(logID, userID uint) (*models.ProgressLog, error) {
+	var log models.ProgressLog
+	if err := s.db.Where("id = ? AND user_id = ?", logID, userID).First(&log).Error; err != nil {
+		return nil, errors.New("progress entry not found")
+	}
+	return &log, nil
+}
+
+// applyProgressLogRequest validates a request and copies it onto an entry, returning
+// the typed body measurements to store with it. Untyped values default to the given unit
+func applyProgressLogRequest(log *models.ProgressLog, req *ProgressLogRequest, unit string) ([]models.BodyMeasurement, error) {
+	notes := strings.TrimSpace(req.Notes)
+	if req.Weight == 0 && len(req.BodyMeasurements) == 0 && len(req.Measurements) == 0 && notes == "" {
+		return nil, errors.New("a progress entry needs a weight, measurements or notes")
+	}
+
+	typed, other, err := typedMeasurements(req, unit)
+	if err != nil {
+		return nil, err
+	}
+	measurements := ""
+	if len(other) > 0 {
+		encoded, err := json.Marshal(other)
+		if err != nil {
+			return nil, err
+		}
+		measurements = string(encoded)
+	}
+
+	loggedAt := time.Now()
+	if req.LoggedAt != nil {
+		if req.LoggedAt.After(time.Now().Add(time.Hour)) {
+			return nil, errors.New("progress cannot be logged in the future")
+		}
+		loggedAt = *req.LoggedAt
+	}
+
+	log.Weight = req.Weight
+	log.Measurements = measurements
+	log.Notes = notes
+	log.LoggedAt = loggedAt
+	return typed, nil
+}
+
+// saveMeasurements stores the typed body measurements of a saved entry
+func saveMeasurements(tx *gorm.DB, log *models.ProgressLog, measurements []models.BodyMeasurement) error {
+	if len(measurements) == 0 {
+		return nil
+	}
+	for i := range measurements {
+		measurements[i].UserID = log.UserID
+		measurements[i].ProgressLogID = log.ID
+		measurements[i].MeasuredAt = log.LoggedAt
+	}
+	return tx.Create(&measurements).Error
+}
+
+func buildProgressLogResponse(log *models.ProgressLog, measurements []models.BodyMeasurement, unit string) *ProgressLogResponse {
+	response := &ProgressLogResponse{
+		ID:        log.ID,
+		UserID:    log.UserID,
+		Weight:    log.Weight,
+		Notes:     log.Notes,
+		LoggedAt:  log.LoggedAt,
+		CreatedAt: log.CreatedAt,
+		UpdatedAt: log.UpdatedAt,
+	}
+	if len(measurements) > 0 {
+		response.BodyMeasurements = measurementValues(measurements, unit)
+	}
+	if log.Measurements != "" {
+		json.Unmarshal([]byte(log.Measurements), &response.Measurements)
+	}
+	return response
+}
@@ -0,0 +1,48 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestApplyProgressLogRequest(t *testing.T) {
+	var log models.ProgressLog
+
+	if _, err := applyProgressLogRequest(&log, &ProgressLogRequest{}, UnitCm); err == nil {
+		t.Error("expected an empty entry to be rejected")
+	}
+
+	future := time.Now().Add(48 * time.Hour)
+	if _, err := applyProgressLogRequest(&log, &ProgressLogRequest{Weight: 80, LoggedAt: &future}, UnitCm); err == nil {
+		t.Error("expected a future date to be rejected")
+	}
+
+	if _, err := applyProgressLogRequest(&log, &ProgressLogRequest{Measurements: map[string]float64{"waist": -1}}, UnitCm); err == nil {
+		t.Error("expected a negative measurement to be rejected")
+	}
+
+	past := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	req := &ProgressLogRequest{
+		Weight:           80,
+		BodyMeasurements: []BodyMeasurementInput{{Site: "arm", Side: "left", Value: 14}},
+		Measurements:     map[string]float64{"waist": 82, "body_fat": 21.5},
+		LoggedAt:         &past,
+	}
+	measurements, err := applyProgressLogRequest(&log, req, UnitInch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !log.LoggedAt.Equal(past) || log.Measurements != `{"body_fat":21.5}` {
+		t.Errorf("expected the waist to be typed and body fat kept, got %+v", log)
+	}
+	if len(measurements) != 2 || measurements[0].Site != "waist" || measurements[0].ValueCm != 82 || measurements[1].ValueCm != 35.6 {
+		t.Errorf("expected the waist in cm and the arm converted from inches, got %+v", measurements)
+	}
+
+	response := buildProgressLogResponse(&log, measurements, UnitInch)
+	if response.Measurements["body_fat"] != 21.5 || response.BodyMeasurements[0].Value != 32.3 || response.BodyMeasurements[1].Value != 14 {
+		t.Errorf("expected measurements to round-trip in inches, got %+v", response)
+	}
+}
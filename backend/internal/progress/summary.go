@@ -0,0 +1,140 @@
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+)
+
+// Summary compares a member's first and latest progress entries against their goals
+type Summary struct {
+	HasData          bool       `json:"has_data"`
+	GoalDirection    string     `json:"goal_direction,omitempty"` // loss, gain, maintain
+	StartWeight      float64    `json:"start_weight"`
+	CurrentWeight    float64    `json:"current_weight"`
+	TargetWeight     float64    `json:"target_weight"`
+	WeightChange     float64    `json:"weight_change"`      // current minus start, negative when weight was lost
+	BodyFatChange    float64    `json:"body_fat_change"`    // percentage points since the profile baseline
+	MuscleMassChange float64    `json:"muscle_mass_change"` // kg since the profile baseline
+	OverallProgress  float64    `json:"overall_progress"`   // 0-100 towards the target weight
+	FirstLoggedAt    *time.Time `json:"first_logged_at,omitempty"`
+	LastLoggedAt     *time.Time `json:"last_logged_at,omitempty"`
+}
+
+// Measurement keys body composition is read from
+var (
+	BodyFatKeys    = []string{"body_fat", "body_fat_percentage"}
+	MuscleMassKeys = []string{"muscle_mass"}
+)
+
+// maintainTolerance is how close (kg) start and target weight must be to count as maintaining
+const maintainTolerance = 0.5
+
+// GetSummary computes a member's progress summary
+// The summary is empty (HasData false) until the member has logged a weight
+func (s *ProgressService) GetSummary(memberID, viewerID uint, viewerRole string) (*Summary, error) {
+	if err := s.canViewProgress(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	var profile models.UserProfile
+	s.db.Where("user_id = ?", memberID).First(&profile)
+
+	var logs []models.ProgressLog
+	if err := s.db.Where("user_id = ?", memberID).Order("logged_at, id").Find(&logs).Error; err != nil {
+		return nil, errors.New("failed to load progress entries")
+	}
+
+	return computeSummary(logs, &profile), nil
+}
+
+// computeSummary builds the summary from entries in chronological order
+func computeSummary(logs []models.ProgressLog, profile *models.UserProfile) *Summary {
+	summary := &Summary{TargetWeight: profile.TargetWeight}
+
+	var first, latest *models.ProgressLog
+	var bodyFat, muscleMass float64
+	for i := range logs {
+		if logs[i].Weight > 0 {
+			if first == nil {
+				first = &logs[i]
+			}
+			latest = &logs[i]
+		}
+		if logs[i].Measurements != "" {
+			var measurements map[string]float64
+			json.Unmarshal([]byte(logs[i].Measurements), &measurements)
+			if value := MeasurementValue(measurements, BodyFatKeys); value > 0 {
+				bodyFat = value
+			}
+			if value := MeasurementValue(measurements, MuscleMassKeys); value > 0 {
+				muscleMass = value
+			}
+		}
+	}
+	if first == nil {
+		return summary
+	}
+
+	summary.HasData = true
+	summary.StartWeight = first.Weight
+	summary.CurrentWeight = latest.Weight
+	summary.WeightChange = utils.RoundTenth(latest.Weight - first.Weight)
+	summary.FirstLoggedAt = &first.LoggedAt
+	summary.LastLoggedAt = &latest.LoggedAt
+
+	if bodyFat > 0 && profile.BodyFatPercentage > 0 {
+		summary.BodyFatChange = utils.RoundTenth(bodyFat - profile.BodyFatPercentage)
+	}
+	if muscleMass > 0 && profile.MuscleMass > 0 {
+		summary.MuscleMassChange = utils.RoundTenth(muscleMass - profile.MuscleMass)
+	}
+
+	if profile.TargetWeight <= 0 {
+		return summary
+	}
+	summary.GoalDirection = GoalDirection(first.Weight, profile.TargetWeight)
+	summary.OverallProgress = goalProgress(first.Weight, latest.Weight, profile.TargetWeight)
+	return summary
+}
+
+// GoalDirection tells whether reaching target from start means losing, gaining or keeping weight
+func GoalDirection(start, target float64) string {
+	switch {
+	case math.Abs(target-start) < maintainTolerance:
+		return "maintain"
+	case target < start:
+		return "loss"
+	default:
+		return "gain"
+	}
+}
+
+// goalProgress is the share of the way from start to target covered, 0-100
+// Moving away from the target counts as no progress; maintaining is complete while
+// the weight stays within a kilogram of the target
+func goalProgress(start, current, target float64) float64 {
+	if GoalDirection(start, target) == "maintain" {
+		if math.Abs(current-target) <= 1 {
+			return 100
+		}
+		return 0
+	}
+
+	ratio := (current - start) / (target - start)
+	return math.Round(math.Max(0, math.Min(ratio, 1))*1000) / 10
+}
+
+// MeasurementValue returns the value of the first key present, or 0
+func MeasurementValue(measurements map[string]float64, keys []string) float64 {
+	for _, key := range keys {
+		if value, ok := measurements[key]; ok {
+			return value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestComputeSummary(t *testing.T) {
+	profile := &models.UserProfile{TargetWeight: 80, BodyFatPercentage: 25, MuscleMass: 35}
+
+	if summary := computeSummary(nil, profile); summary.HasData || summary.TargetWeight != 80 {
+		t.Errorf("expected an empty summary without entries, got %+v", summary)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 8, 0, 0, 0, time.UTC) }
+	logs := []models.ProgressLog{
+		{Weight: 90, LoggedAt: day(1)},
+		{Measurements: `{"waist":90,"body_fat":22.5}`, LoggedAt: day(5)},
+		{Weight: 86, Measurements: `{"muscle_mass":35.8}`, LoggedAt: day(10)},
+	}
+	summary := computeSummary(logs, profile)
+
+	if !summary.HasData || summary.GoalDirection != "loss" || summary.WeightChange != -4 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if summary.OverallProgress != 40 || summary.BodyFatChange != -2.5 || summary.MuscleMassChange != 0.8 {
+		t.Errorf("unexpected progress %+v", summary)
+	}
+}
+
+func TestGoalProgress(t *testing.T) {
+	cases := []struct {
+		start, current, target, want float64
+	}{
+		{60, 63, 70, 30},  // gaining
+		{90, 92, 80, 0},   // moving away from the target
+		{90, 78, 80, 100}, // overshooting
+		{70, 70.8, 70, 100},
+	}
+	for _, tc := range cases {
+		if got := goalProgress(tc.start, tc.current, tc.target); got != tc.want {
+			t.Errorf("%v -> %v (target %v): expected %v, got %v", tc.start, tc.current, tc.target, tc.want, got)
+		}
+	}
+}
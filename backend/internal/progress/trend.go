@@ -0,0 +1,217 @@
+package progress
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+)
+
+// Trend statuses
+const (
+	TrendOnTrack          = "on_track"
+	TrendAhead            = "ahead"
+	TrendBehind           = "behind"
+	TrendReached          = "reached"
+	TrendNoGoal           = "no_goal"           // no target weight or timeline in the profile
+	TrendInsufficientData = "insufficient_data" // less than a week of weigh-ins
+)
+
+const (
+	// trendSmoothing is the daily EMA factor; 0.1 follows a 10% step towards each new weigh-in
+	trendSmoothing = 0.1
+	// rateWindowDays is how far back the weekly rate of change is fitted
+	rateWindowDays = 28
+	// minRateSpanDays is the shortest history a rate is estimated from
+	minRateSpanDays = 7
+	// forecastTolerance is how far the forecast may miss the deadline and still be on track
+	forecastTolerance = 7 * 24 * time.Hour
+	// reachedTolerance is how close (kg) the trend must be to the target to count as reached
+	reachedTolerance = 0.5
+	// maxForecastDays caps projections of a very slow rate
+	maxForecastDays = 5 * 365
+)
+
+// TrendPoint is one day's weight and its smoothed trend
+type TrendPoint struct {
+	Date   time.Time `json:"date"`
+	Weight float64   `json:"weight"` // average of the day's weigh-ins
+	Trend  float64   `json:"trend"`
+}
+
+// Trend is a member's smoothed weight trend and goal forecast
+type Trend struct {
+	HasData            bool         `json:"has_data"`
+	Status             string       `json:"status"`                   // on_track, ahead, behind, reached, no_goal, insufficient_data
+	GoalDirection      string       `json:"goal_direction,omitempty"` // loss, gain, maintain
+	CurrentWeight      float64      `json:"current_weight"`
+	TrendWeight        float64      `json:"trend_weight"`
+	TargetWeight       float64      `json:"target_weight"`
+	WeeklyRate         float64      `json:"weekly_rate"`          // kg per week, negative when losing
+	RequiredWeeklyRate float64      `json:"required_weekly_rate"` // kg per week needed to meet the deadline
+	ProjectedDate      *time.Time   `json:"projected_date,omitempty"`
+	GoalDate           *time.Time   `json:"goal_date,omitempty"` // profile creation plus the timeline
+	DaysDifference     int          `json:"days_difference"`     // goal date minus projected date, positive when ahead
+	Points             []TrendPoint `json:"points"`
+}
+
+// GetTrend computes a member's weight trend, returning the points of the last days
+func (s *ProgressService) GetTrend(memberID, viewerID uint, viewerRole string, days int) (*Trend, error) {
+	if err := s.canViewProgress(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	if days < 1 || days > 730 {
+		days = 90
+	}
+
+	var profile models.UserProfile
+	s.db.Where("user_id = ?", memberID).First(&profile)
+
+	var logs []models.ProgressLog
+	err := s.db.Where("user_id = ? AND weight > 0", memberID).Order("logged_at, id").Find(&logs).Error
+	if err != nil {
+		return nil, errors.New("failed to load progress entries")
+	}
+
+	trend := computeTrend(logs, &profile, time.Now())
+	cutoff := utils.Today().AddDate(0, 0, -days)
+	for len(trend.Points) > 0 && trend.Points[0].Date.Before(cutoff) {
+		trend.Points = trend.Points[1:]
+	}
+	return trend, nil
+}
+
+// computeTrend smooths the weigh-ins (chronological) and forecasts the goal date
+func computeTrend(logs []models.ProgressLog, profile *models.UserProfile, now time.Time) *Trend {
+	trend := &Trend{
+		Status:       TrendInsufficientData,
+		TargetWeight: profile.TargetWeight,
+		Points:       smoothWeights(logs),
+	}
+	if len(trend.Points) == 0 {
+		return trend
+	}
+
+	latest := trend.Points[len(trend.Points)-1]
+	trend.HasData = true
+	trend.CurrentWeight = latest.Weight
+	trend.TrendWeight = latest.Trend
+
+	rate, ok := weeklyRate(trend.Points)
+	if ok {
+		trend.WeeklyRate = utils.Round(rate, 2)
+	}
+
+	if profile.TargetWeight <= 0 || profile.Timeline <= 0 {
+		trend.Status = TrendNoGoal
+		return trend
+	}
+	goalDate := utils.DateOnly(profile.CreatedAt).AddDate(0, 0, profile.Timeline)
+	trend.GoalDate = &goalDate
+	trend.GoalDirection = GoalDirection(trend.Points[0].Trend, profile.TargetWeight)
+
+	remaining := profile.TargetWeight - latest.Trend
+	if math.Abs(remaining) <= reachedTolerance {
+		trend.Status = TrendReached
+		return trend
+	}
+	if weeksLeft := goalDate.Sub(utils.DateOnly(now)).Hours() / (24 * 7); weeksLeft > 0 {
+		trend.RequiredWeeklyRate = utils.Round(remaining/weeksLeft, 2)
+	}
+	if !ok {
+		return trend
+	}
+
+	trend.Status = TrendBehind
+	// A flat trend or one moving away from the target never gets there
+	if rate == 0 || math.Signbit(rate) != math.Signbit(remaining) {
+		return trend
+	}
+	daysToGoal := remaining / rate * 7
+	if daysToGoal > maxForecastDays {
+		return trend
+	}
+	projected := latest.Date.Add(time.Duration(daysToGoal * float64(24*time.Hour)))
+	projected = utils.DateOnly(projected)
+	trend.ProjectedDate = &projected
+	trend.DaysDifference = int(math.Round(goalDate.Sub(projected).Hours() / 24))
+
+	switch {
+	case projected.Before(goalDate.Add(-forecastTolerance)):
+		trend.Status = TrendAhead
+	case !projected.After(goalDate.Add(forecastTolerance)):
+		trend.Status = TrendOnTrack
+	}
+	return trend
+}
+
+// smoothWeights averages the weigh-ins of each day and runs an exponential moving
+// average over them. Gaps between weigh-ins weigh the next one proportionally more
+func smoothWeights(logs []models.ProgressLog) []TrendPoint {
+	points := []TrendPoint{}
+	counts := []int{}
+	for _, log := range logs {
+		if log.Weight <= 0 {
+			continue
+		}
+		day := utils.DateOnly(log.LoggedAt)
+		if n := len(points); n > 0 && points[n-1].Date.Equal(day) {
+			points[n-1].Weight += log.Weight
+			counts[n-1]++
+			continue
+		}
+		points = append(points, TrendPoint{Date: day, Weight: log.Weight})
+		counts = append(counts, 1)
+	}
+
+	for i := range points {
+		points[i].Weight /= float64(counts[i])
+		if i == 0 {
+			points[i].Trend = points[i].Weight
+		} else {
+			gap := points[i].Date.Sub(points[i-1].Date).Hours() / 24
+			alpha := 1 - math.Pow(1-trendSmoothing, gap)
+			points[i].Trend = points[i-1].Trend + alpha*(points[i].Weight-points[i-1].Trend)
+		}
+	}
+	for i := range points {
+		points[i].Weight = utils.Round(points[i].Weight, 2)
+		points[i].Trend = utils.Round(points[i].Trend, 2)
+	}
+	return points
+}
+
+// weeklyRate fits a line through the trend of the last rateWindowDays and returns
+// its slope in kg per week. It needs at least a week of weigh-ins
+func weeklyRate(points []TrendPoint) (float64, bool) {
+	last := points[len(points)-1].Date
+	windowStart := last.AddDate(0, 0, -rateWindowDays)
+
+	var n, sumX, sumY, sumXY, sumXX float64
+	first := last
+	for _, point := range points {
+		if point.Date.Before(windowStart) {
+			continue
+		}
+		if point.Date.Before(first) {
+			first = point.Date
+		}
+		x := point.Date.Sub(windowStart).Hours() / 24
+		n++
+		sumX += x
+		sumY += point.Trend
+		sumXY += x * point.Trend
+		sumXX += x * x
+	}
+	if last.Sub(first).Hours()/24 < minRateSpanDays || n < 2 {
+		return 0, false
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denominator * 7, true
+}
@@ -0,0 +1,92 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+// weighIns logs a weight every day from start, losing perDay kg each day
+func weighIns(start time.Time, days int, weight, perDay float64) []models.ProgressLog {
+	logs := []models.ProgressLog{}
+	for i := 0; i < days; i++ {
+		logs = append(logs, models.ProgressLog{
+			Weight:   weight - perDay*float64(i),
+			LoggedAt: start.AddDate(0, 0, i).Add(7 * time.Hour),
+		})
+	}
+	return logs
+}
+
+func TestSmoothWeights(t *testing.T) {
+	day := func(d int, hour int) time.Time { return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC) }
+	points := smoothWeights([]models.ProgressLog{
+		{Weight: 80, LoggedAt: day(1, 7)},
+		{Weight: 81, LoggedAt: day(1, 20)},
+		{Weight: 79.5, LoggedAt: day(2, 7)},
+		{Notes: "no weight", LoggedAt: day(2, 9)},
+		{Weight: 79.5, LoggedAt: day(4, 7)},
+	})
+
+	if len(points) != 3 {
+		t.Fatalf("expected one point per day, got %+v", points)
+	}
+	if points[0].Weight != 80.5 || points[0].Trend != 80.5 {
+		t.Errorf("expected the first day averaged and used as the seed, got %+v", points[0])
+	}
+	if points[1].Trend != 80.4 {
+		t.Errorf("expected a 10%% step towards the next weigh-in, got %+v", points[1])
+	}
+	// A two-day gap moves the trend 19% of the way
+	if points[2].Trend != 80.23 {
+		t.Errorf("expected the gap to weigh the weigh-in more, got %+v", points[2])
+	}
+}
+
+func TestComputeTrend(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.AddDate(0, 0, 42)
+	// Steady 0.1 kg/day loss from 90 kg
+	logs := weighIns(start, 43, 90, 0.1)
+
+	if trend := computeTrend(logs, &models.UserProfile{}, now); trend.Status != TrendNoGoal || !trend.HasData {
+		t.Errorf("expected no_goal without a target, got %+v", trend)
+	}
+	if trend := computeTrend(logs[:3], &models.UserProfile{TargetWeight: 80, Timeline: 90}, now); trend.Status != TrendInsufficientData {
+		t.Errorf("expected insufficient_data for three days of weigh-ins, got %q", trend.Status)
+	}
+
+	cases := []struct {
+		name     string
+		target   float64
+		timeline int
+		want     string
+	}{
+		{"deadline well past the forecast", 80, 365, TrendAhead},
+		{"deadline far before the forecast", 80, 60, TrendBehind},
+		{"moving away from the target", 95, 365, TrendBehind},
+		{"already at the target", 86.5, 90, TrendReached},
+	}
+	for _, tc := range cases {
+		profile := &models.UserProfile{TargetWeight: tc.target, Timeline: tc.timeline}
+		profile.CreatedAt = start
+		trend := computeTrend(logs, profile, now)
+		if trend.Status != tc.want {
+			t.Errorf("%s: expected %s, got %s (%+v)", tc.name, tc.want, trend.Status, trend)
+		}
+	}
+
+	profile := &models.UserProfile{TargetWeight: 80, Timeline: 90}
+	profile.CreatedAt = start
+	trend := computeTrend(logs, profile, now)
+	if trend.WeeklyRate > -0.6 || trend.WeeklyRate < -0.8 {
+		t.Errorf("expected roughly -0.7 kg/week, got %v", trend.WeeklyRate)
+	}
+	if trend.ProjectedDate == nil || trend.GoalDate == nil || trend.RequiredWeeklyRate >= 0 {
+		t.Fatalf("expected a forecast, got %+v", trend)
+	}
+	if trend.DaysDifference != int(trend.GoalDate.Sub(*trend.ProjectedDate).Hours()/24) {
+		t.Errorf("days difference %d does not match %v vs %v", trend.DaysDifference, trend.GoalDate, trend.ProjectedDate)
+	}
+}
@@ -0,0 +1,397 @@
+package workout
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+	"fittrackplus/internal/exercise"
+	"fittrackplus/internal/plan"
+)
+
+// TrainingAnalytics summarises a member's logged training over recent weeks
+type TrainingAnalytics struct {
+	UserID       uint              `json:"user_id"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Weeks        []WeeklyVolume    `json:"weeks"`
+	MuscleGroups []MuscleGroupLoad `json:"muscle_groups"`
+	Imbalances   []MuscleImbalance `json:"imbalances"`
+	Workload     WorkloadRatio     `json:"workload"`
+	Intensity    IntensityProfile  `json:"intensity"`
+}
+
+// WeeklyVolume is the training volume of one Monday-to-Sunday week
+type WeeklyVolume struct {
+	WeekStart time.Time `json:"week_start"`
+	Sessions  int       `json:"sessions"`
+	Sets      int       `json:"sets"`
+	Reps      int       `json:"reps"`
+	TonnageKg float64   `json:"tonnage_kg"` // sum of reps x weight
+}
+
+// MuscleGroupLoad counts the working sets that trained a muscle group
+// Sets count fully for primary muscles and half for secondary muscles
+type MuscleGroupLoad struct {
+	MuscleGroup    string  `json:"muscle_group"`
+	Sets           float64 `json:"sets"`
+	SetsPerWeek    float64 `json:"sets_per_week"`
+	ShareOfVolume  float64 `json:"share_of_volume"` // percentage of all muscle group sets
+	LastTrainedAgo int     `json:"last_trained_days_ago"`
+}
+
+// MuscleImbalance flags opposing muscle groups trained very unevenly
+type MuscleImbalance struct {
+	Dominant   string  `json:"dominant"`
+	Neglected  string  `json:"neglected"`
+	Ratio      float64 `json:"ratio"` // dominant sets per neglected set
+	Suggestion string  `json:"suggestion"`
+}
+
+// WorkloadRatio is the acute:chronic workload ratio based on tonnage
+// Acute load is the last 7 days; chronic load is the weekly average of the last 28 days
+type WorkloadRatio struct {
+	AcuteLoadKg   float64 `json:"acute_load_kg"`
+	ChronicLoadKg float64 `json:"chronic_load_kg"`
+	Ratio         float64 `json:"ratio"`
+	Status        string  `json:"status"` // insufficient_data, low, optimal, high, very_high
+}
+
+// IntensityProfile distributes working sets over intensity zones
+type IntensityProfile struct {
+	ByRPE          []IntensityZone `json:"by_rpe"`           // sets with an RPE logged
+	ByRelativeLoad []IntensityZone `json:"by_relative_load"` // weighted sets as a share of the best estimated 1RM
+}
+
+// IntensityZone is one band of an intensity distribution
+type IntensityZone struct {
+	Zone       string  `json:"zone"`
+	Sets       int     `json:"sets"`
+	Percentage float64 `json:"percentage"`
+}
+
+// opposingMuscles pairs muscle groups that should get comparable work
+var opposingMuscles = [][2][]string{
+	{{"chest"}, {"upper_back", "lats"}},
+	{{"quadriceps"}, {"hamstrings"}},
+	{{"biceps"}, {"triceps"}},
+	{{"shoulders"}, {"rear_delts"}},
+}
+
+// muscleTags holds the catalog muscle groups of an exercise
+type muscleTags struct {
+	primary   []string
+	secondary []string
+}
+
+// GetTrainingAnalytics computes volume, muscle group load, workload ratio and intensity
+// for a member over the given number of weeks
+// Members can see their own analytics, trainers those of the members they assigned plans to
+func (s *WorkoutService) GetTrainingAnalytics(memberID, viewerID uint, viewerRole string, weeks int) (*TrainingAnalytics, error) {
+	if err := s.canViewTraining(memberID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+	if weeks < 1 || weeks > 52 {
+		return nil, errors.New("weeks must be between 1 and 52")
+	}
+
+	now := time.Now()
+	from := weekStart(now).AddDate(0, 0, -7*(weeks-1))
+	// The workload ratio always looks back 28 days
+	if chronicStart := utils.DateOnly(now).AddDate(0, 0, -27); chronicStart.Before(from) {
+		from = chronicStart
+	}
+
+	var sessions []models.WorkoutSession
+	err := s.db.Where("user_id = ? AND status = ? AND started_at >= ?", memberID, "completed", from).
+		Preload("Exercises").Preload("Exercises.Sets").
+		Order("started_at").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := computeAnalytics(sessions, s.catalogMuscles(sessions), s.bestOneRepMaxes(memberID), now, weeks)
+	analytics.UserID = memberID
+	return analytics, nil
+}
+
+// canViewTraining checks that the viewer may see a member's training data
+func (s *WorkoutService) canViewTraining(memberID, viewerID uint, viewerRole string) error {
+	switch {
+	case memberID == viewerID, viewerRole == "admin":
+		return nil
+	case viewerRole == "trainer" && plan.IsAssignedTrainer(s.db, viewerID, memberID):
+		return nil
+	default:
+		return errors.New("you can only view your own training or that of your clients")
+	}
+}
+
+// catalogMuscles looks up the muscle groups of the exercises in the sessions
+// Exercises logged by name are matched to approved catalog entries with the same name
+func (s *WorkoutService) catalogMuscles(sessions []models.WorkoutSession) map[string]muscleTags {
+	var ids []uint
+	var names []string
+	for _, session := range sessions {
+		for _, exerciseLog := range session.Exercises {
+			if exerciseLog.ExerciseID != nil {
+				ids = append(ids, *exerciseLog.ExerciseID)
+			} else {
+				names = append(names, strings.ToLower(exerciseLog.ExerciseName))
+			}
+		}
+	}
+
+	var exercises []models.Exercise
+	if len(ids) > 0 || len(names) > 0 {
+		s.db.Where("id IN ? OR (status = ? AND LOWER(name) IN ?)", append(ids, 0), "approved", append(names, "")).Find(&exercises)
+	}
+
+	tags := map[string]muscleTags{}
+	for i := range exercises {
+		primary, secondary := exercise.ParseMuscles(&exercises[i])
+		id := exercises[i].ID
+		tags[ExerciseKey(&id, "")] = muscleTags{primary, secondary}
+		tags[ExerciseKey(nil, exercises[i].Name)] = muscleTags{primary, secondary}
+	}
+	return tags
+}
+
+// bestOneRepMaxes returns the member's best estimated 1RM per exercise
+func (s *WorkoutService) bestOneRepMaxes(userID uint) map[string]float64 {
+	var records []models.PersonalRecord
+	s.db.Where("user_id = ? AND record_type = ?", userID, "best_e1rm").Find(&records)
+
+	best := map[string]float64{}
+	for _, record := range records {
+		if record.Value > best[record.ExerciseKey] {
+			best[record.ExerciseKey] = record.Value
+		}
+	}
+	return best
+}
+
+// computeAnalytics builds the analytics from completed sessions; sessions before the
+// first reported week only count towards the workload ratio
+func computeAnalytics(sessions []models.WorkoutSession, muscles map[string]muscleTags, bestE1RM map[string]float64, now time.Time, weeks int) *TrainingAnalytics {
+	today := utils.DateOnly(now)
+	from := weekStart(now).AddDate(0, 0, -7*(weeks-1))
+	analytics := &TrainingAnalytics{
+		From:         from,
+		To:           today,
+		Weeks:        make([]WeeklyVolume, weeks),
+		MuscleGroups: []MuscleGroupLoad{},
+		Imbalances:   []MuscleImbalance{},
+	}
+	for i := range analytics.Weeks {
+		analytics.Weeks[i].WeekStart = from.AddDate(0, 0, 7*i)
+	}
+
+	muscleSets := map[string]float64{}
+	lastTrained := map[string]time.Time{}
+	rpeZones := []IntensityZone{{Zone: "light (RPE < 6)"}, {Zone: "moderate (RPE 6-7.5)"}, {Zone: "hard (RPE 8-9)"}, {Zone: "maximal (RPE 9.5-10)"}}
+	loadZones := []IntensityZone{{Zone: "< 60% 1RM"}, {Zone: "60-75% 1RM"}, {Zone: "75-85% 1RM"}, {Zone: "> 85% 1RM"}}
+	var acute, chronic float64
+
+	for _, session := range sessions {
+		day := utils.DateOnly(session.StartedAt)
+		age := int(today.Sub(day).Hours() / 24)
+		inRange := !day.Before(from)
+		week := int(day.Sub(from).Hours() / 24 / 7)
+
+		if inRange && week < weeks {
+			analytics.Weeks[week].Sessions++
+		}
+
+		for _, exerciseLog := range session.Exercises {
+			key := ExerciseKey(exerciseLog.ExerciseID, exerciseLog.ExerciseName)
+			tags, tagged := muscles[key]
+			if !tagged && exerciseLog.ExerciseID != nil {
+				tags, tagged = muscles[ExerciseKey(nil, exerciseLog.ExerciseName)]
+			}
+
+			for _, set := range exerciseLog.Sets {
+				if set.IsWarmup {
+					continue
+				}
+				tonnage := float64(set.Reps) * set.WeightKg
+				if age < 7 {
+					acute += tonnage
+				}
+				if age < 28 {
+					chronic += tonnage
+				}
+				if !inRange || week >= weeks {
+					continue
+				}
+
+				analytics.Weeks[week].Sets++
+				analytics.Weeks[week].Reps += set.Reps
+				analytics.Weeks[week].TonnageKg += tonnage
+
+				if tagged {
+					for _, muscle := range tags.primary {
+						muscleSets[muscle]++
+						lastTrained[muscle] = laterDate(lastTrained[muscle], day)
+					}
+					for _, muscle := range tags.secondary {
+						muscleSets[muscle] += 0.5
+						lastTrained[muscle] = laterDate(lastTrained[muscle], day)
+					}
+				}
+
+				if set.RPE > 0 {
+					rpeZones[rpeZone(set.RPE)].Sets++
+				}
+				if e1rm := bestE1RM[key]; e1rm > 0 && set.WeightKg > 0 {
+					loadZones[relativeLoadZone(set.WeightKg/e1rm)].Sets++
+				}
+			}
+		}
+	}
+
+	for i := range analytics.Weeks {
+		analytics.Weeks[i].TonnageKg = utils.RoundTenth(analytics.Weeks[i].TonnageKg)
+	}
+
+	var totalMuscleSets float64
+	for _, sets := range muscleSets {
+		totalMuscleSets += sets
+	}
+	for muscle, sets := range muscleSets {
+		analytics.MuscleGroups = append(analytics.MuscleGroups, MuscleGroupLoad{
+			MuscleGroup:    muscle,
+			Sets:           sets,
+			SetsPerWeek:    utils.RoundTenth(sets / float64(weeks)),
+			ShareOfVolume:  utils.RoundTenth(sets / totalMuscleSets * 100),
+			LastTrainedAgo: int(today.Sub(lastTrained[muscle]).Hours() / 24),
+		})
+	}
+	sort.Slice(analytics.MuscleGroups, func(i, j int) bool {
+		a, b := analytics.MuscleGroups[i], analytics.MuscleGroups[j]
+		return a.Sets > b.Sets || (a.Sets == b.Sets && a.MuscleGroup < b.MuscleGroup)
+	})
+
+	analytics.Imbalances = findImbalances(muscleSets)
+	analytics.Workload = workloadRatio(acute, chronic/4)
+	analytics.Intensity = IntensityProfile{ByRPE: withPercentages(rpeZones), ByRelativeLoad: withPercentages(loadZones)}
+
+	return analytics
+}
+
+// findImbalances reports opposing muscle groups where one side gets at least twice
+// the work of the other
+func findImbalances(muscleSets map[string]float64) []MuscleImbalance {
+	imbalances := []MuscleImbalance{}
+	for _, pair := range opposingMuscles {
+		var a, b float64
+		for _, muscle := range pair[0] {
+			a += muscleSets[muscle]
+		}
+		for _, muscle := range pair[1] {
+			b += muscleSets[muscle]
+		}
+
+		dominant, neglected := pair[0], pair[1]
+		if b > a {
+			a, b = b, a
+			dominant, neglected = neglected, dominant
+		}
+		// Ignore pairs without enough work to judge
+		if a < 6 || a < 2*b {
+			continue
+		}
+
+		ratio := 0.0
+		if b > 0 {
+			ratio = utils.RoundTenth(a / b)
+		}
+		imbalances = append(imbalances, MuscleImbalance{
+			Dominant:   strings.Join(dominant, "+"),
+			Neglected:  strings.Join(neglected, "+"),
+			Ratio:      ratio,
+			Suggestion: fmt.Sprintf("Add work for %s to balance %s", strings.Join(neglected, " and "), strings.Join(dominant, " and ")),
+		})
+	}
+	return imbalances
+}
+
+// workloadRatio classifies the acute:chronic workload ratio
+// 0.8-1.3 is the commonly cited sweet spot; above 1.5 injury risk rises sharply
+func workloadRatio(acute, chronic float64) WorkloadRatio {
+	workload := WorkloadRatio{AcuteLoadKg: utils.RoundTenth(acute), ChronicLoadKg: utils.RoundTenth(chronic), Status: "insufficient_data"}
+	if chronic <= 0 {
+		return workload
+	}
+
+	workload.Ratio = math.Round(acute/chronic*100) / 100
+	switch {
+	case workload.Ratio < 0.8:
+		workload.Status = "low"
+	case workload.Ratio <= 1.3:
+		workload.Status = "optimal"
+	case workload.Ratio <= 1.5:
+		workload.Status = "high"
+	default:
+		workload.Status = "very_high"
+	}
+	return workload
+}
+
+func rpeZone(rpe float64) int {
+	switch {
+	case rpe < 6:
+		return 0
+	case rpe < 8:
+		return 1
+	case rpe < 9.5:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func relativeLoadZone(share float64) int {
+	switch {
+	case share < 0.6:
+		return 0
+	case share < 0.75:
+		return 1
+	case share <= 0.85:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func withPercentages(zones []IntensityZone) []IntensityZone {
+	total := 0
+	for _, zone := range zones {
+		total += zone.Sets
+	}
+	if total > 0 {
+		for i := range zones {
+			zones[i].Percentage = utils.RoundTenth(float64(zones[i].Sets) / float64(total) * 100)
+		}
+	}
+	return zones
+}
+
+func laterDate(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// weekStart returns the Monday of the week containing t
+func weekStart(t time.Time) time.Time {
+	day := utils.DateOnly(t)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
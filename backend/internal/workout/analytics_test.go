@@ -0,0 +1,54 @@
+package workout
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestComputeAnalytics(t *testing.T) {
+	// Wednesday; the two-week window starts on Monday 4 March
+	now := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+	bench := func(day int, sets int) models.WorkoutSession {
+		session := models.WorkoutSession{StartedAt: time.Date(2024, 3, day, 18, 0, 0, 0, time.UTC)}
+		exerciseLog := models.WorkoutExerciseLog{ExerciseName: "Bench Press"}
+		for i := 0; i < sets; i++ {
+			exerciseLog.Sets = append(exerciseLog.Sets, models.WorkoutSetLog{Reps: 5, WeightKg: 80, RPE: 8})
+		}
+		exerciseLog.Sets = append(exerciseLog.Sets, models.WorkoutSetLog{Reps: 10, WeightKg: 40, IsWarmup: true})
+		session.Exercises = []models.WorkoutExerciseLog{exerciseLog}
+		return session
+	}
+	sessions := []models.WorkoutSession{bench(5, 4), bench(12, 4)}
+	muscles := map[string]muscleTags{"name:bench press": {primary: []string{"chest"}, secondary: []string{"triceps"}}}
+	bestE1RM := map[string]float64{"name:bench press": 100}
+
+	analytics := computeAnalytics(sessions, muscles, bestE1RM, now, 2)
+
+	if len(analytics.Weeks) != 2 || analytics.Weeks[0].TonnageKg != 1600 || analytics.Weeks[1].Sets != 4 {
+		t.Fatalf("unexpected weekly volume %+v", analytics.Weeks)
+	}
+	if analytics.MuscleGroups[0].MuscleGroup != "chest" || analytics.MuscleGroups[0].Sets != 8 || analytics.MuscleGroups[1].Sets != 4 {
+		t.Errorf("unexpected muscle groups %+v", analytics.MuscleGroups)
+	}
+	if len(analytics.Imbalances) != 1 || analytics.Imbalances[0].Dominant != "chest" {
+		t.Errorf("expected chest to outweigh back, got %+v", analytics.Imbalances)
+	}
+	// 1600 kg in the last 7 days against 3200 kg over 28 days (800 kg a week)
+	if analytics.Workload.Ratio != 2 || analytics.Workload.Status != "very_high" {
+		t.Errorf("unexpected workload %+v", analytics.Workload)
+	}
+	if analytics.Intensity.ByRPE[2].Sets != 8 || analytics.Intensity.ByRelativeLoad[2].Percentage != 100 {
+		t.Errorf("unexpected intensity %+v", analytics.Intensity)
+	}
+}
+
+func TestWorkloadRatio(t *testing.T) {
+	if status := workloadRatio(0, 0).Status; status != "insufficient_data" {
+		t.Errorf("expected insufficient_data, got %s", status)
+	}
+	if status := workloadRatio(1000, 1000).Status; status != "optimal" {
+		t.Errorf("expected optimal, got %s", status)
+	}
+}
@@ -0,0 +1,534 @@
+package workout
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/auth"
+	"fittrackplus/internal/common/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkoutHandler handles workout logging HTTP requests
+type WorkoutHandler struct {
+	workoutService *WorkoutService
+}
+
+// NewWorkoutHandler creates a new workout handler
+func NewWorkoutHandler(cfg *config.Config) *WorkoutHandler {
+	return &WorkoutHandler{
+		workoutService: NewWorkoutService(cfg),
+	}
+}
+
+// StartSession godoc
+// @Summary Start a workout session
+// @Description Start logging a workout. Set user_plan_id, plan_day and workout_position to follow a scheduled workout of one of your plans; the response then lists the planned exercises. Reusing a client_id returns the existing session
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param session body StartSessionRequest true "Session details"
+// @Success 201 {object} WorkoutSessionResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workouts/sessions [post]
+func (h *WorkoutHandler) StartSession(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	var req StartSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	session, err := h.workoutService.StartSession(userID, &req)
+	if err != nil {
+		c.JSON(workoutErrorStatus(err), gin.H{
+			"error":   "Failed to start session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// SubmitSessions godoc
+// @Summary Submit workout sessions recorded offline
+// @Description Submit complete sessions with their exercises and sets. Every session needs a client_id; sessions already submitted are reported as duplicates, so a batch can be retried safely
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sessions body map[string]interface{} true "Sessions (sessions: list of sessions with client_id, started_at, finished_at, exercises)"
+// @Success 200 {object} map[string]interface{} "Result per session"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workouts/sessions/sync [post]
+func (h *WorkoutHandler) SubmitSessions(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	var req struct {
+		Sessions []SessionSubmission `json:"sessions" binding:"required,min=1,max=100,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	results := h.workoutService.SubmitSessions(userID, req.Sessions)
+
+	summary := gin.H{"created": 0, "duplicate": 0, "failed": 0}
+	for _, result := range results {
+		summary[result.Status] = summary[result.Status].(int) + 1
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"summary": summary,
+	})
+}
+
+// GetSessions godoc
+// @Summary Get workout history
+// @Description Get the current user's workout sessions, most recent first
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Sessions started on or after this date (YYYY-MM-DD)"
+// @Param to query string false "Sessions started on or before this date (YYYY-MM-DD)"
+// @Param status query string false "in_progress or completed"
+// @Success 200 {array} WorkoutSessionResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workouts/sessions [get]
+func (h *WorkoutHandler) GetSessions(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+
+	filter := SessionFilter{Status: c.Query("status")}
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid to date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		to = to.AddDate(0, 0, 1) // include the whole day
+		filter.To = &to
+	}
+
+	sessions, err := h.workoutService.GetSessions(userID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get sessions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// GetSession godoc
+// @Summary Get a workout session
+// @Description Get one of the current user's sessions with its exercises, sets and, for planned workouts, the prescription
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} WorkoutSessionResponse
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Session not found"
+// @Router /workouts/sessions/{id} [get]
+func (h *WorkoutHandler) GetSession(c *gin.Context) {
+	sessionID, userID, ok := sessionParams(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.workoutService.GetSession(sessionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Session not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// LogExercise godoc
+// @Summary Log an exercise
+// @Description Add a performed exercise with its sets (reps, weight, duration, distance, RPE) to a session in progress. Use prescription_position to link it to the planned exercise
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Param exercise body ExerciseLogRequest true "Exercise and sets"
+// @Success 200 {object} WorkoutSessionResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Session not found"
+// @Failure 409 {object} map[string]interface{} "Session already finished"
+// @Router /workouts/sessions/{id}/exercises [post]
+func (h *WorkoutHandler) LogExercise(c *gin.Context) {
+	sessionID, userID, ok := sessionParams(c)
+	if !ok {
+		return
+	}
+
+	var req ExerciseLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	session, err := h.workoutService.LogExercise(sessionID, userID, &req)
+	if err != nil {
+		c.JSON(workoutErrorStatus(err), gin.H{
+			"error":   "Failed to log exercise",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// FinishSession godoc
+// @Summary Finish a workout session
+// @Description Complete a session. Sessions that follow a scheduled workout mark it as done on the plan
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Param finish body FinishSessionRequest false "Finish time and notes"
+// @Success 200 {object} WorkoutSessionResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Session not found"
+// @Failure 409 {object} map[string]interface{} "Session already finished"
+// @Router /workouts/sessions/{id}/finish [post]
+func (h *WorkoutHandler) FinishSession(c *gin.Context) {
+	sessionID, userID, ok := sessionParams(c)
+	if !ok {
+		return
+	}
+
+	var req FinishSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	session, err := h.workoutService.FinishSession(sessionID, userID, &req)
+	if err != nil {
+		c.JSON(workoutErrorStatus(err), gin.H{
+			"error":   "Failed to finish session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// DeleteSession godoc
+// @Summary Delete a workout session
+// @Description Delete one of the current user's sessions and everything logged in it
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} map[string]interface{} "Session deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Session not found"
+// @Router /workouts/sessions/{id} [delete]
+func (h *WorkoutHandler) DeleteSession(c *gin.Context) {
+	sessionID, userID, ok := sessionParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workoutService.DeleteSession(sessionID, userID); err != nil {
+		c.JSON(workoutErrorStatus(err), gin.H{
+			"error":   "Failed to delete session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session deleted successfully",
+	})
+}
+
+// sessionParams reads the caller and the session ID, writing an error response when either is missing
+func sessionParams(c *gin.Context) (sessionID, userID uint, ok bool) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid session ID",
+		})
+		return 0, 0, false
+	}
+
+	return uint(id), userID, true
+}
+
+func workoutErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "session is already"):
+		return http.StatusConflict
+	case strings.HasPrefix(err.Error(), "you can only"):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// GetPersonalRecords godoc
+// @Summary Get personal records
+// @Description Get the current user's best performances per exercise: heaviest weight, most reps at each weight, best estimated 1RM, fastest time per distance and longest distance
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param exercise_id query int false "Catalog exercise ID"
+// @Param exercise query string false "Exercise name (for exercises outside the catalog)"
+// @Success 200 {array} ExerciseRecordsResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workouts/records [get]
+func (h *WorkoutHandler) GetPersonalRecords(c *gin.Context) {
+	userID, exerciseID, ok := recordParams(c)
+	if !ok {
+		return
+	}
+
+	records, err := h.workoutService.GetPersonalRecords(userID, exerciseID, c.Query("exercise"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get personal records",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// GetRecordHistory godoc
+// @Summary Get personal record history
+// @Description Get every personal record the current user has set, most recent first
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param exercise_id query int false "Catalog exercise ID"
+// @Param exercise query string false "Exercise name (for exercises outside the catalog)"
+// @Param type query string false "heaviest_weight, most_reps, best_e1rm, fastest_time or longest_distance"
+// @Success 200 {array} PersonalRecordResponse
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workouts/records/history [get]
+func (h *WorkoutHandler) GetRecordHistory(c *gin.Context) {
+	userID, exerciseID, ok := recordParams(c)
+	if !ok {
+		return
+	}
+
+	recordType := c.Query("type")
+	if recordType != "" && recordTypeIndex(recordType) == len(RecordTypes) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid record type",
+			"allowed": RecordTypes,
+		})
+		return
+	}
+
+	history, err := h.workoutService.GetRecordHistory(userID, exerciseID, c.Query("exercise"), recordType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get record history",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// EstimateOneRepMax godoc
+// @Summary Estimate a one-rep max
+// @Description Estimate the one-rep max for a set with the Epley and Brzycki formulas (up to 12 reps)
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param weight query number true "Weight lifted in kg"
+// @Param reps query int true "Repetitions performed"
+// @Success 200 {object} OneRepMaxEstimate
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /workouts/e1rm [get]
+func (h *WorkoutHandler) EstimateOneRepMax(c *gin.Context) {
+	weight, weightErr := strconv.ParseFloat(c.Query("weight"), 64)
+	reps, repsErr := strconv.Atoi(c.Query("reps"))
+	if weightErr != nil || repsErr != nil || weight <= 0 || reps < 1 || reps > maxE1RMReps {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "weight must be positive and reps between 1 and 12",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.workoutService.EstimateOneRepMaxes(weight, reps))
+}
+
+// recordParams reads the caller and the optional exercise_id filter, writing an error response when invalid
+func recordParams(c *gin.Context) (userID uint, exerciseID *uint, ok bool) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return 0, nil, false
+	}
+
+	if value := c.Query("exercise_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid exercise ID",
+			})
+			return 0, nil, false
+		}
+		exercise := uint(id)
+		exerciseID = &exercise
+	}
+
+	return userID, exerciseID, true
+}
+
+// GetTrainingAnalytics godoc
+// @Summary Get training analytics
+// @Description Get weekly tonnage, sets per muscle group (from exercise catalog tags), opposing muscle imbalances, acute:chronic workload ratio and intensity distribution from logged workouts. Members see their own; trainers can pass user_id for the members they assigned plans to
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Member ID (defaults to the current user)"
+// @Param weeks query int false "Weeks to cover, 1-52 (default 8)"
+// @Success 200 {object} TrainingAnalytics
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - not your client"
+// @Router /workouts/analytics [get]
+func (h *WorkoutHandler) GetTrainingAnalytics(c *gin.Context) {
+	userID, exists := auth.GetCurrentUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User ID not found",
+		})
+		return
+	}
+	userRole, _ := auth.GetCurrentUserRole(c)
+
+	memberID := userID
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user ID",
+			})
+			return
+		}
+		memberID = uint(id)
+	}
+
+	weeks := 8
+	if value := c.Query("weeks"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid number of weeks",
+			})
+			return
+		}
+		weeks = parsed
+	}
+
+	analytics, err := h.workoutService.GetTrainingAnalytics(memberID, userID, userRole, weeks)
+	if err != nil {
+		c.JSON(workoutErrorStatus(err), gin.H{
+			"error":   "Failed to get training analytics",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
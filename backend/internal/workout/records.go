@@ -0,0 +1,412 @@
+package workout
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/common/utils"
+	"fittrackplus/internal/notification"
+
+	"gorm.io/gorm"
+)
+
+// RecordTypes are the kinds of personal records detected from logged sets
+var RecordTypes = []string{"heaviest_weight", "most_reps", "best_e1rm", "fastest_time", "longest_distance"}
+
+// maxE1RMReps is the highest rep count a one-rep max is estimated from; estimates
+// from longer sets are too unreliable to count as records
+const maxE1RMReps = 12
+
+// PersonalRecordResponse represents a personal record
+type PersonalRecordResponse struct {
+	ID             uint      `json:"id"`
+	ExerciseID     *uint     `json:"exercise_id,omitempty"`
+	ExerciseName   string    `json:"exercise_name"`
+	RecordType     string    `json:"record_type"`
+	Value          float64   `json:"value"`
+	Unit           string    `json:"unit"` // kg, reps, seconds, meters
+	PreviousValue  *float64  `json:"previous_value,omitempty"`
+	WeightKg       float64   `json:"weight_kg,omitempty"`
+	Reps           int       `json:"reps,omitempty"`
+	DistanceMeters float64   `json:"distance_meters,omitempty"`
+	SessionID      uint      `json:"session_id"`
+	Description    string    `json:"description"`
+	AchievedAt     time.Time `json:"achieved_at"`
+}
+
+// ExerciseRecordsResponse lists the current records for one exercise
+type ExerciseRecordsResponse struct {
+	ExerciseID   *uint                    `json:"exercise_id,omitempty"`
+	ExerciseName string                   `json:"exercise_name"`
+	Records      []PersonalRecordResponse `json:"records"`
+}
+
+// OneRepMaxEstimate holds the estimated one-rep max for a set by each formula
+type OneRepMaxEstimate struct {
+	WeightKg float64 `json:"weight_kg"`
+	Reps     int     `json:"reps"`
+	Epley    float64 `json:"epley"`
+	Brzycki  float64 `json:"brzycki"`
+	Formula  string  `json:"formula"` // formula used for records
+	E1RM     float64 `json:"e1rm"`
+}
+
+// recordKey identifies what a record is kept for; most_reps records are kept per
+// weight and fastest_time records per distance
+type recordKey struct {
+	ExerciseKey    string
+	RecordType     string
+	WeightKg       float64
+	DistanceMeters float64
+}
+
+// recordCandidate is the best performance of a session for one record key
+type recordCandidate struct {
+	key          recordKey
+	value        float64
+	exerciseID   *uint
+	exerciseName string
+	set          models.WorkoutSetLog
+}
+
+// EstimateOneRepMax estimates the weight that could be lifted once for the given set
+// Returns 0 when the set cannot be used (no load, no reps or more than maxE1RMReps)
+func EstimateOneRepMax(weightKg float64, reps int, formula string) float64 {
+	if weightKg <= 0 || reps < 1 || reps > maxE1RMReps {
+		return 0
+	}
+	if reps == 1 {
+		return utils.RoundTenth(weightKg)
+	}
+	if formula == "brzycki" {
+		return utils.RoundTenth(weightKg * 36 / float64(37-reps))
+	}
+	return utils.RoundTenth(weightKg * (1 + float64(reps)/30))
+}
+
+// EstimateOneRepMaxes returns the estimate for a set by both formulas
+func (s *WorkoutService) EstimateOneRepMaxes(weightKg float64, reps int) *OneRepMaxEstimate {
+	estimate := &OneRepMaxEstimate{
+		WeightKg: weightKg,
+		Reps:     reps,
+		Epley:    EstimateOneRepMax(weightKg, reps, "epley"),
+		Brzycki:  EstimateOneRepMax(weightKg, reps, "brzycki"),
+		Formula:  s.cfg.E1RMFormula,
+	}
+	estimate.E1RM = EstimateOneRepMax(weightKg, reps, s.cfg.E1RMFormula)
+	return estimate
+}
+
+// GetPersonalRecords returns the member's current records, grouped by exercise
+func (s *WorkoutService) GetPersonalRecords(userID uint, exerciseID *uint, exerciseName string) ([]ExerciseRecordsResponse, error) {
+	var records []models.PersonalRecord
+	query := s.recordQuery(userID, exerciseID, exerciseName, "")
+	if err := query.Order("achieved_at, id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	// Keep the best record per key; later rows only exist when they improved on earlier ones,
+	// but a deleted session can leave an older record as the best again
+	best := map[recordKey]models.PersonalRecord{}
+	for _, record := range records {
+		key := recordKey{record.ExerciseKey, record.RecordType, record.WeightKg, record.DistanceMeters}
+		if record.RecordType != "most_reps" {
+			key.WeightKg = 0
+		}
+		if record.RecordType != "fastest_time" {
+			key.DistanceMeters = 0
+		}
+		if current, ok := best[key]; !ok || isBetter(record.RecordType, record.Value, current.Value) {
+			best[key] = record
+		}
+	}
+
+	byExercise := map[string]*ExerciseRecordsResponse{}
+	order := []string{}
+	for _, record := range best {
+		group, ok := byExercise[record.ExerciseKey]
+		if !ok {
+			group = &ExerciseRecordsResponse{ExerciseID: record.ExerciseID, ExerciseName: record.ExerciseName}
+			byExercise[record.ExerciseKey] = group
+			order = append(order, record.ExerciseKey)
+		}
+		group.Records = append(group.Records, *buildRecordResponse(&record))
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		return byExercise[order[i]].ExerciseName < byExercise[order[j]].ExerciseName
+	})
+	responses := []ExerciseRecordsResponse{}
+	for _, key := range order {
+		group := byExercise[key]
+		sort.Slice(group.Records, func(i, j int) bool {
+			a, b := group.Records[i], group.Records[j]
+			if a.RecordType != b.RecordType {
+				return recordTypeIndex(a.RecordType) < recordTypeIndex(b.RecordType)
+			}
+			return a.WeightKg < b.WeightKg || (a.WeightKg == b.WeightKg && a.DistanceMeters < b.DistanceMeters)
+		})
+		responses = append(responses, *group)
+	}
+	return responses, nil
+}
+
+// GetRecordHistory returns every record the member has set, most recent first
+func (s *WorkoutService) GetRecordHistory(userID uint, exerciseID *uint, exerciseName, recordType string) ([]PersonalRecordResponse, error) {
+	var records []models.PersonalRecord
+	query := s.recordQuery(userID, exerciseID, exerciseName, recordType)
+	if err := query.Order("achieved_at DESC, id DESC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []PersonalRecordResponse{}
+	for i := range records {
+		responses = append(responses, *buildRecordResponse(&records[i]))
+	}
+	return responses, nil
+}
+
+// detectRecords compares a finished session against the member's records, stores the
+// records it sets and notifies the member of any it improved
+func (s *WorkoutService) detectRecords(sessionID uint) {
+	var session models.WorkoutSession
+	err := s.db.Preload("Exercises").Preload("Exercises.Sets").First(&session, sessionID).Error
+	if err != nil {
+		return
+	}
+
+	achievedAt := session.StartedAt
+	if session.FinishedAt != nil {
+		achievedAt = *session.FinishedAt
+	}
+
+	var created []models.PersonalRecord
+	for _, candidate := range sessionBests(&session, s.cfg.E1RMFormula) {
+		record := models.PersonalRecord{
+			UserID:         session.UserID,
+			ExerciseKey:    candidate.key.ExerciseKey,
+			ExerciseID:     candidate.exerciseID,
+			ExerciseName:   candidate.exerciseName,
+			RecordType:     candidate.key.RecordType,
+			Value:          candidate.value,
+			WeightKg:       candidate.set.WeightKg,
+			Reps:           candidate.set.Reps,
+			DistanceMeters: candidate.set.DistanceMeters,
+			SessionID:      session.ID,
+			SetLogID:       candidate.set.ID,
+			AchievedAt:     achievedAt,
+		}
+
+		if previous, ok := s.currentBest(session.UserID, candidate.key, session.ID); ok {
+			if !isBetter(candidate.key.RecordType, candidate.value, previous) {
+				continue
+			}
+			record.PreviousValue = &previous
+		}
+
+		if err := s.db.Create(&record).Error; err == nil {
+			created = append(created, record)
+		}
+	}
+
+	// The first performance of an exercise sets a baseline; only improvements are news
+	var improved []string
+	for i := range created {
+		if created[i].PreviousValue != nil {
+			improved = append(improved, DescribeRecord(&created[i]))
+		}
+	}
+	if len(improved) == 0 {
+		return
+	}
+
+	title := "New personal record"
+	if len(improved) > 1 {
+		title = fmt.Sprintf("%d new personal records", len(improved))
+	}
+	s.notificationService.Notify(session.UserID, notification.Message{
+		Type:     "success",
+		Category: "personal_record",
+		Title:    title,
+		Message:  strings.Join(improved, "; "),
+		Link:     "/workouts/records",
+	})
+}
+
+// currentBest returns the best value recorded for a key outside the given session
+func (s *WorkoutService) currentBest(userID uint, key recordKey, excludeSessionID uint) (float64, bool) {
+	aggregate := "MAX(value)"
+	if key.RecordType == "fastest_time" {
+		aggregate = "MIN(value)"
+	}
+
+	query := s.db.Model(&models.PersonalRecord{}).
+		Where("user_id = ? AND exercise_key = ? AND record_type = ? AND session_id <> ?", userID, key.ExerciseKey, key.RecordType, excludeSessionID)
+	switch key.RecordType {
+	case "most_reps":
+		query = query.Where("weight_kg = ?", key.WeightKg)
+	case "fastest_time":
+		query = query.Where("distance_meters = ?", key.DistanceMeters)
+	}
+
+	var best *float64
+	if err := query.Select(aggregate).Scan(&best).Error; err != nil || best == nil {
+		return 0, false
+	}
+	return *best, true
+}
+
+func (s *WorkoutService) recordQuery(userID uint, exerciseID *uint, exerciseName, recordType string) *gorm.DB {
+	query := s.db.Where("user_id = ?", userID)
+	if exerciseID != nil {
+		query = query.Where("exercise_id = ?", *exerciseID)
+	} else if exerciseName != "" {
+		query = query.Where("LOWER(exercise_name) = ?", strings.ToLower(strings.TrimSpace(exerciseName)))
+	}
+	if recordType != "" {
+		query = query.Where("record_type = ?", recordType)
+	}
+	return query
+}
+
+// sessionBests finds the best performance in a session for every record key
+// Warm-up sets are ignored
+func sessionBests(session *models.WorkoutSession, formula string) []recordCandidate {
+	best := map[recordKey]*recordCandidate{}
+	order := []recordKey{}
+
+	consider := func(exerciseLog *models.WorkoutExerciseLog, set models.WorkoutSetLog, recordType string, value float64) {
+		key := recordKey{ExerciseKey: ExerciseKey(exerciseLog.ExerciseID, exerciseLog.ExerciseName), RecordType: recordType}
+		switch recordType {
+		case "most_reps":
+			key.WeightKg = set.WeightKg
+		case "fastest_time":
+			key.DistanceMeters = set.DistanceMeters
+		}
+
+		current, ok := best[key]
+		if !ok {
+			order = append(order, key)
+		} else if !isBetter(recordType, value, current.value) {
+			return
+		}
+		best[key] = &recordCandidate{key: key, value: value, exerciseID: exerciseLog.ExerciseID, exerciseName: exerciseLog.ExerciseName, set: set}
+	}
+
+	for e := range session.Exercises {
+		exerciseLog := &session.Exercises[e]
+		for _, set := range exerciseLog.Sets {
+			if set.IsWarmup {
+				continue
+			}
+			if set.WeightKg > 0 && set.Reps > 0 {
+				consider(exerciseLog, set, "heaviest_weight", set.WeightKg)
+				if e1rm := EstimateOneRepMax(set.WeightKg, set.Reps, formula); e1rm > 0 {
+					consider(exerciseLog, set, "best_e1rm", e1rm)
+				}
+			}
+			if set.Reps > 0 {
+				consider(exerciseLog, set, "most_reps", float64(set.Reps))
+			}
+			if set.DistanceMeters > 0 {
+				consider(exerciseLog, set, "longest_distance", set.DistanceMeters)
+				if set.DurationSeconds > 0 {
+					consider(exerciseLog, set, "fastest_time", float64(set.DurationSeconds))
+				}
+			}
+		}
+	}
+
+	candidates := []recordCandidate{}
+	for _, key := range order {
+		candidates = append(candidates, *best[key])
+	}
+	return candidates
+}
+
+// DescribeRecord summarises a record for notifications and activity feeds
+func DescribeRecord(record *models.PersonalRecord) string {
+	switch record.RecordType {
+	case "heaviest_weight":
+		return fmt.Sprintf("%s: heaviest weight %s kg", record.ExerciseName, formatNumber(record.Value))
+	case "most_reps":
+		if record.WeightKg > 0 {
+			return fmt.Sprintf("%s: %d reps at %s kg", record.ExerciseName, int(record.Value), formatNumber(record.WeightKg))
+		}
+		return fmt.Sprintf("%s: %d reps", record.ExerciseName, int(record.Value))
+	case "best_e1rm":
+		return fmt.Sprintf("%s: estimated 1RM %s kg", record.ExerciseName, formatNumber(record.Value))
+	case "fastest_time":
+		seconds := int(record.Value)
+		return fmt.Sprintf("%s: %s m in %d:%02d", record.ExerciseName, formatNumber(record.DistanceMeters), seconds/60, seconds%60)
+	case "longest_distance":
+		return fmt.Sprintf("%s: longest distance %s m", record.ExerciseName, formatNumber(record.Value))
+	}
+	return record.ExerciseName
+}
+
+func buildRecordResponse(record *models.PersonalRecord) *PersonalRecordResponse {
+	return &PersonalRecordResponse{
+		ID:             record.ID,
+		ExerciseID:     record.ExerciseID,
+		ExerciseName:   record.ExerciseName,
+		RecordType:     record.RecordType,
+		Value:          record.Value,
+		Unit:           RecordUnit(record.RecordType),
+		PreviousValue:  record.PreviousValue,
+		WeightKg:       record.WeightKg,
+		Reps:           record.Reps,
+		DistanceMeters: record.DistanceMeters,
+		SessionID:      record.SessionID,
+		Description:    DescribeRecord(record),
+		AchievedAt:     record.AchievedAt,
+	}
+}
+
+// isBetter reports whether value beats best; lower is better only for times
+func isBetter(recordType string, value, best float64) bool {
+	if recordType == "fastest_time" {
+		return value < best
+	}
+	return value > best
+}
+
+// RecordUnit returns the unit a record type is measured in
+func RecordUnit(recordType string) string {
+	switch recordType {
+	case "most_reps":
+		return "reps"
+	case "fastest_time":
+		return "seconds"
+	case "longest_distance":
+		return "meters"
+	default:
+		return "kg"
+	}
+}
+
+func recordTypeIndex(recordType string) int {
+	for i, t := range RecordTypes {
+		if t == recordType {
+			return i
+		}
+	}
+	return len(RecordTypes)
+}
+
+// ExerciseKey identifies an exercise across sessions: by catalog entry when there is one,
+// otherwise by name
+func ExerciseKey(exerciseID *uint, exerciseName string) string {
+	if exerciseID != nil {
+		return "id:" + strconv.FormatUint(uint64(*exerciseID), 10)
+	}
+	return "name:" + strings.ToLower(strings.TrimSpace(exerciseName))
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,67 @@
+package workout
+
+import (
+	"testing"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestEstimateOneRepMax(t *testing.T) {
+	cases := []struct {
+		weight  float64
+		reps    int
+		formula string
+		want    float64
+	}{
+		{100, 1, "epley", 100},
+		{100, 5, "epley", 116.7},
+		{100, 5, "brzycki", 112.5},
+		{100, 13, "epley", 0},
+		{0, 5, "epley", 0},
+	}
+	for _, tc := range cases {
+		if got := EstimateOneRepMax(tc.weight, tc.reps, tc.formula); got != tc.want {
+			t.Errorf("%s %.0f x %d: expected %.1f, got %.1f", tc.formula, tc.weight, tc.reps, tc.want, got)
+		}
+	}
+}
+
+func TestSessionBests(t *testing.T) {
+	session := &models.WorkoutSession{
+		Exercises: []models.WorkoutExerciseLog{
+			{ExerciseName: "Back Squat", Sets: []models.WorkoutSetLog{
+				{Reps: 5, WeightKg: 140, IsWarmup: true},
+				{Reps: 5, WeightKg: 100},
+				{Reps: 8, WeightKg: 100},
+				{Reps: 3, WeightKg: 110},
+			}},
+			{ExerciseName: "Running", Sets: []models.WorkoutSetLog{
+				{DistanceMeters: 5000, DurationSeconds: 1500},
+				{DistanceMeters: 5000, DurationSeconds: 1440},
+			}},
+		},
+	}
+
+	values := map[recordKey]float64{}
+	for _, candidate := range sessionBests(session, "epley") {
+		values[candidate.key] = candidate.value
+	}
+
+	squat, run := "name:back squat", "name:running"
+	expected := map[recordKey]float64{
+		{ExerciseKey: squat, RecordType: "heaviest_weight"}:                  110,
+		{ExerciseKey: squat, RecordType: "best_e1rm"}:                        126.7,
+		{ExerciseKey: squat, RecordType: "most_reps", WeightKg: 100}:         8,
+		{ExerciseKey: squat, RecordType: "most_reps", WeightKg: 110}:         3,
+		{ExerciseKey: run, RecordType: "longest_distance"}:                   5000,
+		{ExerciseKey: run, RecordType: "fastest_time", DistanceMeters: 5000}: 1440,
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %d records, got %v", len(expected), values)
+	}
+	for key, want := range expected {
+		if values[key] != want {
+			t.Errorf("%+v: expected %v, got %v", key, want, values[key])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package workout
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/testdb"
+	"fittrackplus/internal/plan"
+)
+
+func TestDeleteSessionUndoesPlanCompletion(t *testing.T) {
+	cfg := testdb.Connect(t)
+	service := NewWorkoutService(cfg)
+	planService := plan.NewPlanService(cfg)
+
+	trainer := testdb.CreateUser(t, "trainer")
+	member := testdb.CreateUser(t, "member")
+
+	workoutDay := func(number int) plan.PlanDayRequest {
+		return plan.PlanDayRequest{DayNumber: number, Workouts: []plan.PlanWorkoutRequest{{
+			Name:      "Full body",
+			Exercises: []plan.ExercisePrescriptionRequest{{ExerciseName: "Back Squat", Sets: 3, RepsMin: 5, LoadType: "kg", LoadValue: 80}},
+		}}}
+	}
+	created, err := planService.CreatePlan(&plan.PlanRequest{
+		Name:     "Two day test plan",
+		GoalType: "gain_muscle",
+		PlanType: "fitness",
+		Duration: 2,
+		Days:     []plan.PlanDayRequest{workoutDay(1), workoutDay(2)},
+	}, trainer.ID, "trainer")
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	userPlan, err := planService.AssignPlan(member.ID, created.ID, trainer.ID, false)
+	if err != nil {
+		t.Fatalf("failed to assign plan: %v", err)
+	}
+
+	// Two sessions follow the same scheduled workout
+	finished := time.Now()
+	submission := func(n int) SessionSubmission {
+		return SessionSubmission{
+			StartSessionRequest: StartSessionRequest{
+				ClientID:        fmt.Sprintf("delete-test-%d-%d", member.ID, n),
+				UserPlanID:      &userPlan.ID,
+				PlanDay:         1,
+				WorkoutPosition: 1,
+				StartedAt:       finished.Add(-time.Hour),
+			},
+			FinishedAt: &finished,
+			Exercises:  []ExerciseLogRequest{{ExerciseName: "Back Squat", Sets: []SetLogRequest{{Reps: 5, WeightKg: 80}}}},
+		}
+	}
+	results := service.SubmitSessions(member.ID, []SessionSubmission{submission(1), submission(2)})
+	for _, result := range results {
+		if result.Status != "created" {
+			t.Fatalf("submission %s was %s: %s", result.ClientID, result.Status, result.Error)
+		}
+	}
+
+	completed := func() int {
+		t.Helper()
+		response, err := planService.GetUserPlan(userPlan.ID, member.ID)
+		if err != nil {
+			t.Fatalf("failed to load user plan: %v", err)
+		}
+		return response.ProgressDetails.CompletedWorkouts
+	}
+	if got := completed(); got != 1 {
+		t.Fatalf("expected 1 completed workout, got %d", got)
+	}
+
+	// The other session still covers the workout
+	if err := service.DeleteSession(results[0].SessionID, member.ID); err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+	if got := completed(); got != 1 {
+		t.Fatalf("expected the workout to stay completed, got %d completed", got)
+	}
+
+	if err := service.DeleteSession(results[1].SessionID, member.ID); err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+	if got := completed(); got != 0 {
+		t.Fatalf("expected no completed workouts after deleting both sessions, got %d", got)
+	}
+
+	// The client ID can be submitted again
+	if again := service.SubmitSessions(member.ID, []SessionSubmission{submission(1)}); again[0].Status != "created" {
+		t.Fatalf("expected resubmission to create the session, got %s: %s", again[0].Status, again[0].Error)
+	}
+}
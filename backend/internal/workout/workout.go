@@ -0,0 +1,643 @@
+package workout
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fittrackplus/internal/common/config"
+	"fittrackplus/internal/common/database"
+	"fittrackplus/internal/common/models"
+	"fittrackplus/internal/notification"
+	"fittrackplus/internal/plan"
+
+	"gorm.io/gorm"
+)
+
+// WorkoutService handles logging of performed workouts
+type WorkoutService struct {
+	db                  *gorm.DB
+	cfg                 *config.Config
+	planService         *plan.PlanService
+	notificationService *notification.NotificationService
+}
+
+// NewWorkoutService creates a new workout service
+func NewWorkoutService(cfg *config.Config) *WorkoutService {
+	return &WorkoutService{
+		db:                  database.GetDB(),
+		cfg:                 cfg,
+		planService:         plan.NewPlanService(cfg),
+		notificationService: notification.NewNotificationService(cfg),
+	}
+}
+
+// StartSessionRequest starts a workout session
+// A session follows a scheduled workout when user_plan_id, plan_day and workout_position are set
+type StartSessionRequest struct {
+	ClientID        string    `json:"client_id" binding:"max=64"` // generated by the app; repeated submissions return the same session
+	UserPlanID      *uint     `json:"user_plan_id"`
+	PlanDay         int       `json:"plan_day" binding:"min=0"`
+	WorkoutPosition int       `json:"workout_position" binding:"min=0"`
+	Name            string    `json:"name"`
+	StartedAt       time.Time `json:"started_at"`
+	Notes           string    `json:"notes"`
+}
+
+// ExerciseLogRequest records an exercise performed during a session
+// Set exercise_id for library exercises, or prescription_position to follow the planned exercise
+type ExerciseLogRequest struct {
+	ClientID             string          `json:"client_id" binding:"max=64"`
+	ExerciseID           *uint           `json:"exercise_id"`
+	ExerciseName         string          `json:"exercise_name"`
+	PrescriptionPosition int             `json:"prescription_position" binding:"min=0"`
+	Notes                string          `json:"notes"`
+	Sets                 []SetLogRequest `json:"sets" binding:"required,min=1,dive"`
+}
+
+// SetLogRequest is a single set as performed
+type SetLogRequest struct {
+	Reps            int     `json:"reps" binding:"min=0"`
+	WeightKg        float64 `json:"weight_kg" binding:"min=0"`
+	DurationSeconds int     `json:"duration_seconds" binding:"min=0"`
+	DistanceMeters  float64 `json:"distance_meters" binding:"min=0"`
+	RPE             float64 `json:"rpe" binding:"min=0,max=10"`
+	IsWarmup        bool    `json:"is_warmup"`
+}
+
+// FinishSessionRequest completes a session
+type FinishSessionRequest struct {
+	FinishedAt time.Time `json:"finished_at"`
+	Notes      string    `json:"notes"`
+}
+
+// SessionSubmission is a whole session recorded offline
+type SessionSubmission struct {
+	StartSessionRequest
+	FinishedAt *time.Time           `json:"finished_at"`
+	Exercises  []ExerciseLogRequest `json:"exercises" binding:"dive"`
+}
+
+// SubmissionResult reports what happened to one submitted session
+type SubmissionResult struct {
+	ClientID  string `json:"client_id"`
+	Status    string `json:"status"` // created, duplicate, failed
+	SessionID uint   `json:"session_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// WorkoutSessionResponse represents a logged session
+type WorkoutSessionResponse struct {
+	ID              uint                                `json:"id"`
+	ClientID        string                              `json:"client_id"`
+	UserPlanID      *uint                               `json:"user_plan_id,omitempty"`
+	PlanDay         int                                 `json:"plan_day,omitempty"`
+	WorkoutPosition int                                 `json:"workout_position,omitempty"`
+	Name            string                              `json:"name"`
+	Status          string                              `json:"status"`
+	StartedAt       time.Time                           `json:"started_at"`
+	FinishedAt      *time.Time                          `json:"finished_at,omitempty"`
+	DurationMinutes int                                 `json:"duration_minutes,omitempty"`
+	Notes           string                              `json:"notes,omitempty"`
+	TotalSets       int                                 `json:"total_sets"`
+	TotalReps       int                                 `json:"total_reps"`
+	TotalVolumeKg   float64                             `json:"total_volume_kg"` // sum of reps x weight over working sets
+	Exercises       []WorkoutExerciseResponse           `json:"exercises"`
+	Planned         []plan.ExercisePrescriptionResponse `json:"planned,omitempty"` // what the plan prescribes for this workout
+	CreatedAt       time.Time                           `json:"created_at"`
+}
+
+// WorkoutExerciseResponse represents an exercise performed during a session
+type WorkoutExerciseResponse struct {
+	ID                   uint                 `json:"id"`
+	ClientID             string               `json:"client_id"`
+	Position             int                  `json:"position"`
+	ExerciseID           *uint                `json:"exercise_id,omitempty"`
+	ExerciseName         string               `json:"exercise_name"`
+	PrescriptionPosition int                  `json:"prescription_position,omitempty"`
+	Notes                string               `json:"notes,omitempty"`
+	Sets                 []WorkoutSetResponse `json:"sets"`
+}
+
+// WorkoutSetResponse represents a performed set
+type WorkoutSetResponse struct {
+	SetNumber       int     `json:"set_number"`
+	Reps            int     `json:"reps,omitempty"`
+	WeightKg        float64 `json:"weight_kg,omitempty"`
+	DurationSeconds int     `json:"duration_seconds,omitempty"`
+	DistanceMeters  float64 `json:"distance_meters,omitempty"`
+	RPE             float64 `json:"rpe,omitempty"`
+	IsWarmup        bool    `json:"is_warmup"`
+}
+
+// SessionFilter holds the session history parameters
+type SessionFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Status string
+}
+
+// StartSession opens a new workout session
+// Starting a session with a client ID that was already used returns the existing session
+func (s *WorkoutService) StartSession(userID uint, req *StartSessionRequest) (*WorkoutSessionResponse, error) {
+	if existing := s.findByClientID(userID, req.ClientID); existing != nil {
+		return s.GetSession(existing.ID, userID)
+	}
+
+	session, err := s.newSession(userID, req, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetSession(session.ID, userID)
+}
+
+// LogExercise adds a performed exercise and its sets to a session in progress
+// Logging an exercise with a client ID already used in the session is a no-op
+func (s *WorkoutService) LogExercise(sessionID, userID uint, req *ExerciseLogRequest) (*WorkoutSessionResponse, error) {
+	session, err := s.findSession(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != "in_progress" {
+		return nil, errors.New("session is already finished")
+	}
+
+	var count int64
+	s.db.Model(&models.WorkoutExerciseLog{}).Where("session_id = ?", session.ID).Count(&count)
+	if req.ClientID != "" {
+		var duplicate int64
+		s.db.Model(&models.WorkoutExerciseLog{}).Where("session_id = ? AND client_id = ?", session.ID, req.ClientID).Count(&duplicate)
+		if duplicate > 0 {
+			return s.GetSession(session.ID, userID)
+		}
+	}
+
+	planned, err := s.plannedExercises(session)
+	if err != nil {
+		return nil, err
+	}
+	exerciseLog, err := s.newExerciseLog(req, int(count)+1, planned)
+	if err != nil {
+		return nil, err
+	}
+	exerciseLog.SessionID = session.ID
+
+	if err := s.db.Create(exerciseLog).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetSession(session.ID, userID)
+}
+
+// FinishSession completes a session and checks it for personal records
+// Sessions that follow a scheduled workout mark that workout as done on the member's plan
+func (s *WorkoutService) FinishSession(sessionID, userID uint, req *FinishSessionRequest) (*WorkoutSessionResponse, error) {
+	session, err := s.findSession(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != "in_progress" {
+		return nil, errors.New("session is already finished")
+	}
+
+	finishedAt := req.FinishedAt
+	if finishedAt.IsZero() {
+		finishedAt = time.Now()
+	}
+	if finishedAt.Before(session.StartedAt) {
+		return nil, errors.New("a session cannot finish before it started")
+	}
+
+	updates := map[string]interface{}{"status": "completed", "finished_at": finishedAt}
+	if notes := strings.TrimSpace(req.Notes); notes != "" {
+		updates["notes"] = notes
+	}
+	if err := s.db.Model(session).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	session.FinishedAt = &finishedAt
+	s.recordPlanCompletion(session)
+	s.detectRecords(session.ID)
+
+	return s.GetSession(session.ID, userID)
+}
+
+// SubmitSessions stores sessions recorded offline
+// Each session needs a client ID; sessions already submitted are reported as duplicates
+// so the app can safely retry a whole batch
+func (s *WorkoutService) SubmitSessions(userID uint, submissions []SessionSubmission) []SubmissionResult {
+	results := []SubmissionResult{}
+
+	for i := range submissions {
+		submission := &submissions[i]
+		result := SubmissionResult{ClientID: submission.ClientID}
+
+		if submission.ClientID == "" {
+			result.Status = "failed"
+			result.Error = "client_id is required for bulk submission"
+			results = append(results, result)
+			continue
+		}
+		if existing := s.findByClientID(userID, submission.ClientID); existing != nil {
+			result.Status = "duplicate"
+			result.SessionID = existing.ID
+			results = append(results, result)
+			continue
+		}
+
+		session, err := s.createSubmission(userID, submission)
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+		} else {
+			result.Status = "created"
+			result.SessionID = session.ID
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// GetSessions lists a member's sessions, most recent first
+func (s *WorkoutService) GetSessions(userID uint, filter SessionFilter) ([]WorkoutSessionResponse, error) {
+	query := s.db.Where("user_id = ?", userID).Preload("Exercises", orderByPosition).Preload("Exercises.Sets", orderBySetNumber)
+	if filter.From != nil {
+		query = query.Where("started_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("started_at < ?", *filter.To)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var sessions []models.WorkoutSession
+	if err := query.Order("started_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []WorkoutSessionResponse{}
+	for i := range sessions {
+		responses = append(responses, *buildSessionResponse(&sessions[i]))
+	}
+	return responses, nil
+}
+
+// GetSession retrieves a member's session with its exercises and, for planned
+// workouts, what the plan prescribes
+func (s *WorkoutService) GetSession(sessionID, userID uint) (*WorkoutSessionResponse, error) {
+	var session models.WorkoutSession
+	err := s.db.Where("id = ? AND user_id = ?", sessionID, userID).
+		Preload("Exercises", orderByPosition).Preload("Exercises.Sets", orderBySetNumber).
+		First(&session).Error
+	if err != nil {
+		return nil, errors.New("workout session not found")
+	}
+
+	response := buildSessionResponse(&session)
+	if planned, err := s.plannedExercises(&session); err == nil {
+		response.Planned = planned
+	}
+	return response, nil
+}
+
+// DeleteSession removes a session, everything logged in it, the records it set and
+// the plan completion it recorded
+func (s *WorkoutService) DeleteSession(sessionID, userID uint) error {
+	session, err := s.findSession(sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	// Hard delete: the client ID is unique per member, so a soft-deleted session
+	// would make resubmitting it fail instead of creating it again
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var exerciseLogIDs []uint
+		if err := tx.Model(&models.WorkoutExerciseLog{}).Where("session_id = ?", session.ID).Pluck("id", &exerciseLogIDs).Error; err != nil {
+			return err
+		}
+		if len(exerciseLogIDs) > 0 {
+			if err := tx.Unscoped().Where("exercise_log_id IN ?", exerciseLogIDs).Delete(&models.WorkoutSetLog{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("id IN ?", exerciseLogIDs).Delete(&models.WorkoutExerciseLog{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("session_id = ?", session.ID).Delete(&models.PersonalRecord{}).Error; err != nil {
+			return err
+		}
+		if err := removePlanCompletion(tx, session); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(session).Error
+	})
+}
+
+// removePlanCompletion undoes the plan completion a finished session recorded,
+// unless another completed session followed the same scheduled workout
+func removePlanCompletion(tx *gorm.DB, session *models.WorkoutSession) error {
+	if session.UserPlanID == nil || session.Status != "completed" {
+		return nil
+	}
+
+	var others int64
+	err := tx.Model(&models.WorkoutSession{}).
+		Where("user_plan_id = ? AND plan_day = ? AND workout_position = ? AND status = ? AND id <> ?",
+			*session.UserPlanID, session.PlanDay, session.WorkoutPosition, "completed", session.ID).
+		Count(&others).Error
+	if err != nil || others > 0 {
+		return err
+	}
+
+	return tx.Where("user_plan_id = ? AND plan_day = ? AND workout_position = ?", *session.UserPlanID, session.PlanDay, session.WorkoutPosition).
+		Delete(&models.WorkoutCompletion{}).Error
+}
+
+// createSubmission stores a complete offline session in one transaction
+func (s *WorkoutService) createSubmission(userID uint, submission *SessionSubmission) (*models.WorkoutSession, error) {
+	// The plan may have been paused or completed while the member was offline
+	session, err := s.newSession(userID, &submission.StartSessionRequest, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if submission.FinishedAt != nil {
+		if submission.FinishedAt.Before(session.StartedAt) {
+			return nil, errors.New("a session cannot finish before it started")
+		}
+		session.Status = "completed"
+		session.FinishedAt = submission.FinishedAt
+	}
+
+	planned, err := s.plannedExercises(session)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for i := range submission.Exercises {
+		exercise := &submission.Exercises[i]
+		if exercise.ClientID != "" && seen[exercise.ClientID] {
+			continue
+		}
+		seen[exercise.ClientID] = true
+
+		exerciseLog, err := s.newExerciseLog(exercise, len(session.Exercises)+1, planned)
+		if err != nil {
+			return nil, fmt.Errorf("exercise %d: %v", i+1, err)
+		}
+		session.Exercises = append(session.Exercises, *exerciseLog)
+	}
+
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, err
+	}
+
+	if session.Status == "completed" {
+		s.recordPlanCompletion(session)
+		s.detectRecords(session.ID)
+	}
+	return session, nil
+}
+
+// newSession validates a start request and builds the session
+// Live sessions can only follow an active plan; recorded ones may follow any of the member's plans
+func (s *WorkoutService) newSession(userID uint, req *StartSessionRequest, live bool) (*models.WorkoutSession, error) {
+	session := &models.WorkoutSession{
+		UserID:    userID,
+		ClientID:  strings.TrimSpace(req.ClientID),
+		Name:      strings.TrimSpace(req.Name),
+		Status:    "in_progress",
+		StartedAt: req.StartedAt,
+		Notes:     strings.TrimSpace(req.Notes),
+	}
+	if session.ClientID == "" {
+		clientID, err := newClientID()
+		if err != nil {
+			return nil, err
+		}
+		session.ClientID = clientID
+	}
+	if session.StartedAt.IsZero() {
+		session.StartedAt = time.Now()
+	}
+	if session.StartedAt.After(time.Now().Add(time.Hour)) {
+		return nil, errors.New("a session cannot start in the future")
+	}
+
+	if req.UserPlanID != nil {
+		if req.PlanDay < 1 || req.WorkoutPosition < 1 {
+			return nil, errors.New("plan_day and workout_position are required when following a plan")
+		}
+		lookup := s.planService.GetScheduledWorkout
+		if live {
+			lookup = s.planService.GetPlannedWorkout
+		}
+		planned, err := lookup(*req.UserPlanID, userID, req.PlanDay, req.WorkoutPosition)
+		if err != nil {
+			return nil, err
+		}
+		session.UserPlanID = req.UserPlanID
+		session.PlanDay = req.PlanDay
+		session.WorkoutPosition = req.WorkoutPosition
+		if session.Name == "" {
+			session.Name = planned.Name
+		}
+	}
+
+	if session.Name == "" {
+		session.Name = "Workout"
+	}
+	return session, nil
+}
+
+// newExerciseLog validates an exercise and its sets against the catalog and the planned workout
+func (s *WorkoutService) newExerciseLog(req *ExerciseLogRequest, position int, planned []plan.ExercisePrescriptionResponse) (*models.WorkoutExerciseLog, error) {
+	exerciseLog := &models.WorkoutExerciseLog{
+		ClientID:             strings.TrimSpace(req.ClientID),
+		Position:             position,
+		ExerciseID:           req.ExerciseID,
+		ExerciseName:         strings.TrimSpace(req.ExerciseName),
+		PrescriptionPosition: req.PrescriptionPosition,
+		Notes:                strings.TrimSpace(req.Notes),
+	}
+	if exerciseLog.ClientID == "" {
+		clientID, err := newClientID()
+		if err != nil {
+			return nil, err
+		}
+		exerciseLog.ClientID = clientID
+	}
+
+	if req.PrescriptionPosition > 0 {
+		prescription := findPrescription(planned, req.PrescriptionPosition)
+		if prescription == nil {
+			return nil, fmt.Errorf("the planned workout has no exercise %d", req.PrescriptionPosition)
+		}
+		if exerciseLog.ExerciseID == nil && exerciseLog.ExerciseName == "" {
+			exerciseLog.ExerciseID = prescription.ExerciseID
+			exerciseLog.ExerciseName = prescription.ExerciseName
+		}
+	}
+
+	if exerciseLog.ExerciseID != nil {
+		var exercise models.Exercise
+		if err := s.db.First(&exercise, *exerciseLog.ExerciseID).Error; err != nil {
+			return nil, fmt.Errorf("exercise %d not found in the exercise library", *exerciseLog.ExerciseID)
+		}
+		if exercise.Status != "approved" {
+			return nil, fmt.Errorf("exercise %q is not approved yet", exercise.Name)
+		}
+		exerciseLog.ExerciseName = exercise.Name
+	}
+	if exerciseLog.ExerciseName == "" {
+		return nil, errors.New("exercise_id or exercise_name is required")
+	}
+
+	for i, set := range req.Sets {
+		if set.Reps == 0 && set.DurationSeconds == 0 && set.DistanceMeters == 0 {
+			return nil, fmt.Errorf("set %d: record reps, duration or distance", i+1)
+		}
+		exerciseLog.Sets = append(exerciseLog.Sets, models.WorkoutSetLog{
+			SetNumber:       i + 1,
+			Reps:            set.Reps,
+			WeightKg:        set.WeightKg,
+			DurationSeconds: set.DurationSeconds,
+			DistanceMeters:  set.DistanceMeters,
+			RPE:             set.RPE,
+			IsWarmup:        set.IsWarmup,
+		})
+	}
+
+	return exerciseLog, nil
+}
+
+// plannedExercises returns the prescriptions of the workout a session follows
+// The plan may have changed status since the session started, so it is not checked again
+func (s *WorkoutService) plannedExercises(session *models.WorkoutSession) ([]plan.ExercisePrescriptionResponse, error) {
+	if session.UserPlanID == nil {
+		return nil, nil
+	}
+	planned, err := s.planService.GetScheduledWorkout(*session.UserPlanID, session.UserID, session.PlanDay, session.WorkoutPosition)
+	if err != nil {
+		return nil, err
+	}
+	return planned.Exercises, nil
+}
+
+// recordPlanCompletion marks the scheduled workout a finished session followed as done
+// The session stays logged even if the plan can no longer record completions
+// (e.g. it was paused or completed while the member was offline)
+func (s *WorkoutService) recordPlanCompletion(session *models.WorkoutSession) {
+	if session.UserPlanID == nil || session.FinishedAt == nil {
+		return
+	}
+	s.planService.LogWorkoutCompletion(*session.UserPlanID, session.UserID, session.PlanDay, session.WorkoutPosition, *session.FinishedAt)
+}
+
+func (s *WorkoutService) findSession(sessionID, userID uint) (*models.WorkoutSession, error) {
+	var session models.WorkoutSession
+	if err := s.db.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		return nil, errors.New("workout session not found")
+	}
+	return &session, nil
+}
+
+func (s *WorkoutService) findByClientID(userID uint, clientID string) *models.WorkoutSession {
+	clientID = strings.TrimSpace(clientID)
+	if clientID == "" {
+		return nil
+	}
+	var session models.WorkoutSession
+	if err := s.db.Where("user_id = ? AND client_id = ?", userID, clientID).First(&session).Error; err != nil {
+		return nil
+	}
+	return &session
+}
+
+func buildSessionResponse(session *models.WorkoutSession) *WorkoutSessionResponse {
+	response := &WorkoutSessionResponse{
+		ID:              session.ID,
+		ClientID:        session.ClientID,
+		UserPlanID:      session.UserPlanID,
+		PlanDay:         session.PlanDay,
+		WorkoutPosition: session.WorkoutPosition,
+		Name:            session.Name,
+		Status:          session.Status,
+		StartedAt:       session.StartedAt,
+		FinishedAt:      session.FinishedAt,
+		Notes:           session.Notes,
+		Exercises:       []WorkoutExerciseResponse{},
+		CreatedAt:       session.CreatedAt,
+	}
+	if session.FinishedAt != nil {
+		response.DurationMinutes = int(session.FinishedAt.Sub(session.StartedAt).Minutes())
+	}
+
+	for _, exerciseLog := range session.Exercises {
+		exercise := WorkoutExerciseResponse{
+			ID:                   exerciseLog.ID,
+			ClientID:             exerciseLog.ClientID,
+			Position:             exerciseLog.Position,
+			ExerciseID:           exerciseLog.ExerciseID,
+			ExerciseName:         exerciseLog.ExerciseName,
+			PrescriptionPosition: exerciseLog.PrescriptionPosition,
+			Notes:                exerciseLog.Notes,
+			Sets:                 []WorkoutSetResponse{},
+		}
+		for _, set := range exerciseLog.Sets {
+			exercise.Sets = append(exercise.Sets, WorkoutSetResponse{
+				SetNumber:       set.SetNumber,
+				Reps:            set.Reps,
+				WeightKg:        set.WeightKg,
+				DurationSeconds: set.DurationSeconds,
+				DistanceMeters:  set.DistanceMeters,
+				RPE:             set.RPE,
+				IsWarmup:        set.IsWarmup,
+			})
+			if !set.IsWarmup {
+				response.TotalSets++
+				response.TotalReps += set.Reps
+				response.TotalVolumeKg += float64(set.Reps) * set.WeightKg
+			}
+		}
+		response.Exercises = append(response.Exercises, exercise)
+	}
+
+	return response
+}
+
+func findPrescription(planned []plan.ExercisePrescriptionResponse, position int) *plan.ExercisePrescriptionResponse {
+	for i := range planned {
+		if planned[i].Position == position {
+			return &planned[i]
+		}
+	}
+	return nil
+}
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+func orderBySetNumber(db *gorm.DB) *gorm.DB {
+	return db.Order("set_number")
+}
+
+// newClientID generates a client ID for sessions and exercises started without one
+func newClientID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
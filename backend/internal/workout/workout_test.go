@@ -0,0 +1,34 @@
+package workout
+
+import (
+	"testing"
+	"time"
+
+	"fittrackplus/internal/common/models"
+)
+
+func TestBuildSessionResponseTotals(t *testing.T) {
+	started := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	finished := started.Add(50 * time.Minute)
+	session := &models.WorkoutSession{
+		Status:     "completed",
+		StartedAt:  started,
+		FinishedAt: &finished,
+		Exercises: []models.WorkoutExerciseLog{{
+			ExerciseName: "Back Squat",
+			Sets: []models.WorkoutSetLog{
+				{SetNumber: 1, Reps: 10, WeightKg: 40, IsWarmup: true},
+				{SetNumber: 2, Reps: 5, WeightKg: 100},
+				{SetNumber: 3, Reps: 5, WeightKg: 100},
+			},
+		}},
+	}
+
+	response := buildSessionResponse(session)
+	if response.TotalSets != 2 || response.TotalReps != 10 || response.TotalVolumeKg != 1000 {
+		t.Errorf("expected warm-up sets to be left out of the totals, got %+v", response)
+	}
+	if response.DurationMinutes != 50 {
+		t.Errorf("expected 50 minutes, got %d", response.DurationMinutes)
+	}
+}